@@ -0,0 +1,188 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import (
+	"bytes"
+	"io"
+)
+
+const (
+	ElementListStart = 0
+	ElementListEnd   = 1
+	ElementLeaf      = 2
+	ElementSkipped   = 3
+)
+
+// Element is a single token returned by Decoder.Next(). Offset and
+// HeaderSize always refer to the start of the element so a ListEnd token
+// carries the same values as its ListStart. Size is -1 for lists with an
+// unknown size until the ListEnd token, which carries the parsed size.
+// Skipped tokens describe data dropped while resynchronizing.
+type Element struct {
+	Kind       int
+	ID         int
+	Type       int
+	Offset     int64
+	HeaderSize int
+	Size       int64
+	Value      interface{}
+}
+
+type Decoder struct {
+	reader   io.Reader
+	parser   *Parser
+	typeMap  map[int]int
+	buf      []byte
+	body     *bytes.Buffer
+	current  Element
+	inLeaf   bool
+	lists    []Element
+	elements []Element
+	err      error
+}
+
+func NewDecoder(reader io.Reader, schema *Schema) *Decoder {
+	d := &Decoder{
+		reader:   reader,
+		typeMap:  schema.typeMap,
+		buf:      make([]byte, 4096),
+		body:     bytes.NewBuffer([]byte{}),
+		lists:    []Element{},
+		elements: []Element{},
+	}
+	d.parser = NewParser(schema, d)
+	return d
+}
+
+func (d *Decoder) SetVerifyCRC32(verify bool) {
+	d.parser.SetVerifyCRC32(verify)
+}
+
+func (d *Decoder) SetOptions(options ParserOptions) {
+	d.parser.SetOptions(options)
+}
+
+func (d *Decoder) SetResyncIDs(resyncIDs map[int]int) {
+	d.parser.SetResyncIDs(resyncIDs)
+}
+
+// ResumeAt prepares the decoder to read a stream that starts at offset in
+// the original stream. ancestors holds the lists enclosing offset, outermost
+// first, and their ListEnd tokens are returned when they end.
+func (d *Decoder) ResumeAt(offset int64, ancestors []ListContext) error {
+	d.elements = []Element{}
+	d.inLeaf = false
+	d.err = nil
+	return d.parser.ResumeAt(offset, ancestors)
+}
+
+func (d *Decoder) OnResume(offset int64, ancestors []ListContext) error {
+	d.lists = []Element{}
+	for _, a := range ancestors {
+		d.lists = append(d.lists, Element{Kind: ElementListStart, ID: a.ID, Type: TypeList, Offset: a.Offset, HeaderSize: a.HeaderSize, Size: a.Size})
+	}
+	return nil
+}
+
+// Next returns the next element in the stream. io.EOF is returned once all
+// elements have been returned and io.ErrUnexpectedEOF is returned if the
+// stream ends in the middle of an element.
+func (d *Decoder) Next() (Element, error) {
+	for len(d.elements) == 0 {
+		if d.err != nil {
+			return Element{}, d.err
+		}
+
+		n, err := d.reader.Read(d.buf)
+		if n > 0 {
+			if d.err = d.parser.Append(d.buf[:n]); d.err != nil {
+				continue
+			}
+		}
+
+		if err == io.EOF {
+			if d.err = d.parser.EndOfData(); d.err != nil {
+				continue
+			}
+			// Without resync, EndOfData() leaves a partial header in
+			// the parser's buffer.
+			d.err = io.EOF
+			if len(d.lists) > 0 || d.inLeaf || d.parser.buf.Len() > 0 {
+				d.err = io.ErrUnexpectedEOF
+			}
+		} else if err != nil {
+			d.err = err
+		}
+	}
+
+	e := d.elements[0]
+	d.elements = d.elements[1:]
+	return e, nil
+}
+
+func (d *Decoder) OnHeader(offset int64, hdr []byte, id int, size int64) error {
+	e := Element{Kind: ElementLeaf, ID: id, Type: TypeBinary, Offset: offset, HeaderSize: len(hdr), Size: size}
+	if elementType, present := d.typeMap[id]; present {
+		e.Type = elementType
+	}
+
+	if e.Type == TypeList {
+		e.Kind = ElementListStart
+		d.lists = append(d.lists, e)
+		d.elements = append(d.elements, e)
+		return nil
+	}
+
+	d.current = e
+	d.inLeaf = true
+	d.body.Truncate(0)
+	return nil
+}
+
+func (d *Decoder) OnBody(offset int64, body []byte) error {
+	_, err := d.body.Write(body)
+	return err
+}
+
+func (d *Decoder) OnResync(offset int64, size int64) error {
+	d.inLeaf = false
+	d.elements = append(d.elements, Element{Kind: ElementSkipped, ID: -1, Offset: offset, Size: size})
+	return nil
+}
+
+func (d *Decoder) OnElementEnd(offset int64, id int) error {
+	if !d.inLeaf {
+		e := d.lists[len(d.lists)-1]
+		d.lists = d.lists[:len(d.lists)-1]
+
+		e.Kind = ElementListEnd
+		e.Size = offset - e.Offset - int64(e.HeaderSize)
+		d.elements = append(d.elements, e)
+		return nil
+	}
+
+	e := d.current
+	d.inLeaf = false
+
+	value, ok := decodeValue(e.Type, d.body.Bytes())
+	if !ok {
+		return ErrInvalidValue
+	}
+	e.Value = value
+
+	d.elements = append(d.elements, e)
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestDecoderEndOfStream(t *testing.T) {
+	data := fromHex(t, resumeData)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		elements int
+		want     error
+	}{
+		{"complete", data, 6, io.EOF},
+		{"empty", []byte{}, 0, io.EOF},
+		{"truncated header", data[:2], 0, io.ErrUnexpectedEOF},
+		{"truncated trailing header", append(append([]byte{}, data...), 0x18, 0x53, 0x80), 6, io.ErrUnexpectedEOF},
+		{"truncated header in a list", data[:14], 3, io.ErrUnexpectedEOF},
+		{"truncated body", data[:15], 3, io.ErrUnexpectedEOF},
+		{"truncated list", data[:13], 3, io.ErrUnexpectedEOF},
+	}
+
+	for _, test := range tests {
+		decoder := NewDecoder(bytes.NewReader(test.data), testSchema(t))
+		elements := 0
+		var err error
+		for {
+			if _, err = decoder.Next(); err != nil {
+				break
+			}
+			elements++
+		}
+		if err != test.want || elements != test.elements {
+			t.Errorf("%s: got %d elements and %v, want %d and %v", test.name, elements, err, test.elements, test.want)
+		}
+	}
+}
@@ -17,86 +17,303 @@ package ebml
 import (
 	"bytes"
 	"encoding/binary"
+	"time"
 )
 
+// DateEpoch is the point in time that Date elements are relative to.
+var DateEpoch = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+
 type ElementParserClient interface {
-	OnListStart(offset int64, id int) bool
-	OnListEnd(offset int64, id int) bool
-	OnBinary(id int, value []byte) bool
-	OnInt(id int, value int64) bool
-	OnUint(id int, value uint64) bool
-	OnFloat(id int, value float64) bool
-	OnString(id int, value string) bool
+	OnListStart(offset int64, id int) error
+	OnListEnd(offset int64, id int) error
+	OnBinary(id int, value []byte) error
+	OnInt(id int, value int64) error
+	OnUint(id int, value uint64) error
+	OnFloat(id int, value float64) error
+	OnString(id int, value string) error
+	OnDate(id int, value time.Time) error
+}
+
+// ElementInfo describes the element a callback is made for. DataSize is -1
+// in OnListStart() for lists with an unknown size and holds the number of
+// bytes parsed in OnListEnd(). Depth is 0 for top level elements and Parent
+// is the ID of the enclosing list or -1 at the top level.
+type ElementInfo struct {
+	ID          int
+	Offset      int64
+	HeaderSize  int
+	DataSize    int64
+	UnknownSize bool
+	Depth       int
+	Parent      int
+}
+
+// ElementInfoClient is like ElementParserClient but every callback also
+// receives the ElementInfo of the element.
+type ElementInfoClient interface {
+	OnListStart(info ElementInfo) error
+	OnListEnd(info ElementInfo) error
+	OnBinary(info ElementInfo, value []byte) error
+	OnInt(info ElementInfo, value int64) error
+	OnUint(info ElementInfo, value uint64) error
+	OnFloat(info ElementInfo, value float64) error
+	OnString(info ElementInfo, value string) error
+	OnDate(info ElementInfo, value time.Time) error
+}
+
+// elementParserClientAdapter lets an ElementParserClient be used where an
+// ElementInfoClient is expected.
+type elementParserClientAdapter struct {
+	client ElementParserClient
+}
+
+func (a *elementParserClientAdapter) OnListStart(info ElementInfo) error {
+	return a.client.OnListStart(info.Offset, info.ID)
+}
+
+func (a *elementParserClientAdapter) OnListEnd(info ElementInfo) error {
+	return a.client.OnListEnd(info.Offset+int64(info.HeaderSize)+info.DataSize, info.ID)
+}
+
+func (a *elementParserClientAdapter) OnBinary(info ElementInfo, value []byte) error {
+	return a.client.OnBinary(info.ID, value)
+}
+
+func (a *elementParserClientAdapter) OnInt(info ElementInfo, value int64) error {
+	return a.client.OnInt(info.ID, value)
+}
+
+func (a *elementParserClientAdapter) OnUint(info ElementInfo, value uint64) error {
+	return a.client.OnUint(info.ID, value)
+}
+
+func (a *elementParserClientAdapter) OnFloat(info ElementInfo, value float64) error {
+	return a.client.OnFloat(info.ID, value)
+}
+
+func (a *elementParserClientAdapter) OnString(info ElementInfo, value string) error {
+	return a.client.OnString(info.ID, value)
+}
+
+func (a *elementParserClientAdapter) OnDate(info ElementInfo, value time.Time) error {
+	return a.client.OnDate(info.ID, value)
+}
+
+// StreamingElementParserClient can be implemented by an
+// ElementParserClient or ElementInfoClient to receive binary elements in
+// chunks as they are parsed instead of through OnBinary(). The data passed
+// to OnBinaryChunk() is only valid during the call. OnBinaryEnd() isn't
+// called for elements that are cut short by resynchronization.
+type StreamingElementParserClient interface {
+	OnBinaryStart(id int, size int64) error
+	OnBinaryChunk(data []byte) error
+	OnBinaryEnd() error
 }
 
 type ElementParser struct {
-	id      int
-	buf     *bytes.Buffer
-	client  ElementParserClient
-	typeMap map[int]int
+	info      ElementInfo
+	lists     []ElementInfo
+	buf       *bytes.Buffer
+	client    ElementInfoClient
+	callbacks interface{}
+	typeMap   map[int]int
+	streaming bool
 }
 
-func (p *ElementParser) OnHeader(offset int64, hdr []byte, id int, size int64) bool {
-	p.id = id
+func (p *ElementParser) OnHeader(offset int64, hdr []byte, id int, size int64) error {
+	p.info = ElementInfo{ID: id, Offset: offset, HeaderSize: len(hdr), DataSize: size,
+		UnknownSize: size == -1, Depth: len(p.lists), Parent: -1}
+	if len(p.lists) > 0 {
+		p.info.Parent = p.lists[len(p.lists)-1].ID
+	}
 	p.buf.Truncate(0)
+	p.streaming = false
 
-	if elementType, present := p.typeMap[p.id]; present && elementType == TypeList {
-		return p.client.OnListStart(offset, id)
+	elementType, present := p.typeMap[id]
+	if present && elementType == TypeList {
+		p.lists = append(p.lists, p.info)
+		return p.client.OnListStart(p.info)
 	}
-	return true
+
+	if p.StreamsBody(id) {
+		p.streaming = true
+		return p.callbacks.(StreamingElementParserClient).OnBinaryStart(id, size)
+	}
+	return nil
 }
 
-func (p *ElementParser) OnBody(offset int64, body []byte) bool {
-	bytesWritten, err := p.buf.Write(body)
-	return (bytesWritten == len(body)) && (err == nil)
+// StreamsBody returns true if the body of the element with the specified ID
+// is passed to a StreamingElementParserClient instead of being buffered.
+func (p *ElementParser) StreamsBody(id int) bool {
+	if _, ok := p.callbacks.(StreamingElementParserClient); !ok {
+		return false
+	}
+	elementType, present := p.typeMap[id]
+	return !present || elementType == TypeBinary
+}
+
+func (p *ElementParser) OnBody(offset int64, body []byte) error {
+	if p.streaming {
+		return p.callbacks.(StreamingElementParserClient).OnBinaryChunk(body)
+	}
+
+	_, err := p.buf.Write(body)
+	return err
 }
 
-func (p *ElementParser) OnElementEnd(offset int64, id int) bool {
+// OnResync forwards resync notifications to clients that implement
+// ResyncClient.
+func (p *ElementParser) OnResync(offset int64, size int64) error {
+	p.streaming = false
+	if rc, ok := p.callbacks.(ResyncClient); ok {
+		return rc.OnResync(offset, size)
+	}
+	return nil
+}
+
+// OnResume seeds the open lists with ancestors so elements after a
+// ResumeAt() get the same Depth and Parent as in a parse of the whole
+// stream.
+func (p *ElementParser) OnResume(offset int64, ancestors []ListContext) error {
+	p.lists = []ElementInfo{}
+	for i, a := range ancestors {
+		info := ElementInfo{ID: a.ID, Offset: a.Offset, HeaderSize: a.HeaderSize, DataSize: a.Size,
+			UnknownSize: a.Size == -1, Depth: i, Parent: -1}
+		if i > 0 {
+			info.Parent = ancestors[i-1].ID
+		}
+		p.lists = append(p.lists, info)
+	}
+	p.streaming = false
+	return nil
+}
+
+func (p *ElementParser) OnElementEnd(offset int64, id int) error {
+	if p.streaming {
+		p.streaming = false
+		return p.callbacks.(StreamingElementParserClient).OnBinaryEnd()
+	}
+
 	if elementType, present := p.typeMap[id]; present {
 		switch elementType {
 		case TypeList:
-			return p.client.OnListEnd(offset, id)
+			return p.client.OnListEnd(p.endList(offset, id))
 		case TypeBinary:
-			return p.ParseBinary(p.id, p.buf.Bytes())
-			break
+			return p.ParseBinary(p.info.ID, p.buf.Bytes())
 		case TypeUint:
-			return p.ParseUint(p.id, p.buf.Bytes())
-			break
+			return p.ParseUint(p.info.ID, p.buf.Bytes())
 		case TypeInt:
-			return p.ParseInt(p.id, p.buf.Bytes())
-			break
+			return p.ParseInt(p.info.ID, p.buf.Bytes())
 		case TypeFloat:
-			return p.ParseFloat(p.id, p.buf.Bytes())
-			break
+			return p.ParseFloat(p.info.ID, p.buf.Bytes())
 		case TypeString:
-			return p.ParseString(p.id, p.buf.Bytes())
-			break
+			return p.ParseString(p.info.ID, p.buf.Bytes())
 		case TypeUTF8:
-			return p.ParseUTF8(p.id, p.buf.Bytes())
-			break
+			return p.ParseUTF8(p.info.ID, p.buf.Bytes())
+		case TypeDate:
+			return p.ParseDate(p.info.ID, p.buf.Bytes())
 		}
 	}
-	return p.ParseBinary(p.id, p.buf.Bytes())
+	return p.ParseBinary(p.info.ID, p.buf.Bytes())
 }
 
-func (p *ElementParser) ParseBinary(id int, body []byte) bool {
-	return p.client.OnBinary(id, body)
+// endList pops the list that ends at offset. Lists that were open when
+// parsing was resumed but weren't passed to ResumeAt() are reported with an
+// empty body ending at offset.
+func (p *ElementParser) endList(offset int64, id int) ElementInfo {
+	if len(p.lists) == 0 || p.lists[len(p.lists)-1].ID != id {
+		return ElementInfo{ID: id, Offset: offset, Parent: -1}
+	}
+
+	info := p.lists[len(p.lists)-1]
+	p.lists = p.lists[:len(p.lists)-1]
+	info.DataSize = offset - info.Offset - int64(info.HeaderSize)
+	return info
 }
 
-func (p *ElementParser) ParseUint(id int, body []byte) bool {
+// leafInfo returns the ElementInfo for a leaf callback about id.
+func (p *ElementParser) leafInfo(id int) ElementInfo {
+	info := p.info
+	info.ID = id
+	return info
+}
+
+func (p *ElementParser) ParseBinary(id int, body []byte) error {
+	return p.client.OnBinary(p.leafInfo(id), body)
+}
+
+func (p *ElementParser) ParseUint(id int, body []byte) error {
+	value, ok := decodeUint(body)
+	if !ok {
+		return ErrInvalidValue
+	}
+	return p.client.OnUint(p.leafInfo(id), value)
+}
+
+func (p *ElementParser) ParseInt(id int, body []byte) error {
+	value, ok := decodeInt(body)
+	if !ok {
+		return ErrInvalidValue
+	}
+	return p.client.OnInt(p.leafInfo(id), value)
+}
+
+func (p *ElementParser) ParseFloat(id int, body []byte) error {
+	value, ok := decodeFloat(body)
+	if !ok {
+		return ErrInvalidValue
+	}
+	return p.client.OnFloat(p.leafInfo(id), value)
+}
+
+func (p *ElementParser) ParseString(id int, body []byte) error {
+	return p.client.OnString(p.leafInfo(id), string(body))
+}
+
+func (p *ElementParser) ParseUTF8(id int, body []byte) error {
+	return p.client.OnString(p.leafInfo(id), string(body))
+}
+
+func (p *ElementParser) ParseDate(id int, body []byte) error {
+	value, ok := decodeDate(body)
+	if !ok {
+		return ErrInvalidValue
+	}
+	return p.client.OnDate(p.leafInfo(id), value)
+}
+
+// decodeValue decodes body as an element of type elementType. Binary
+// bodies are copied so the value doesn't share memory with the input.
+func decodeValue(elementType int, body []byte) (interface{}, bool) {
+	switch elementType {
+	case TypeUint:
+		return decodeUint(body)
+	case TypeInt:
+		return decodeInt(body)
+	case TypeFloat:
+		return decodeFloat(body)
+	case TypeString, TypeUTF8:
+		return string(body), true
+	case TypeDate:
+		return decodeDate(body)
+	}
+	return append([]byte{}, body...), true
+}
+
+func decodeUint(body []byte) (uint64, bool) {
 	if len(body) == 0 || len(body) > 8 {
-		return false
+		return 0, false
 	}
 	var value uint64 = 0
 	for i := 0; i < len(body); i += 1 {
 		value = (value << 8) | uint64(body[i])
 	}
-	return p.client.OnUint(id, value)
+	return value, true
 }
 
-func (p *ElementParser) ParseInt(id int, body []byte) bool {
+func decodeInt(body []byte) (int64, bool) {
 	if len(body) == 0 || len(body) > 8 {
-		return false
+		return 0, false
 	}
 
 	var value int64 = 0
@@ -107,38 +324,51 @@ func (p *ElementParser) ParseInt(id int, body []byte) bool {
 	for i := 0; i < len(body); i += 1 {
 		value = (value << 8) | int64(body[i])
 	}
-	return p.client.OnInt(id, value)
+	return value, true
 }
 
-func (p *ElementParser) ParseFloat(id int, body []byte) bool {
+func decodeFloat(body []byte) (float64, bool) {
 	var buf = bytes.NewBuffer(body)
 	if len(body) == 4 {
 		var value float32
 		err := binary.Read(buf, binary.BigEndian, &value)
 		if err != nil {
-			return false
+			return 0, false
 		}
-		return p.client.OnFloat(id, float64(value))
+		return float64(value), true
 	} else if len(body) == 8 {
 		var value float64
 		err := binary.Read(buf, binary.BigEndian, &value)
 		if err != nil {
-			return false
+			return 0, false
 		}
-		return p.client.OnFloat(id, value)
+		return value, true
 	}
-	return false
+	return 0, false
 }
 
-func (p *ElementParser) ParseString(id int, body []byte) bool {
-	return p.client.OnString(id, string(body))
+func NewElementParser(client ElementParserClient, schema *Schema) *ElementParser {
+	p := NewElementInfoParser(&elementParserClientAdapter{client}, schema)
+	p.callbacks = client
+	return p
 }
 
-func (p *ElementParser) ParseUTF8(id int, body []byte) bool {
-	return p.client.OnString(id, string(body))
+func NewElementInfoParser(client ElementInfoClient, schema *Schema) *ElementParser {
+	return &ElementParser{
+		info: ElementInfo{ID: -1, Parent: -1}, lists: []ElementInfo{},
+		buf: bytes.NewBuffer([]byte{}), client: client, callbacks: client, typeMap: schema.typeMap}
 }
 
-func NewElementParser(client ElementParserClient, typeMap map[int]int) *ElementParser {
-	return &ElementParser{
-		id: -1, buf: bytes.NewBuffer([]byte{}), client: client, typeMap: typeMap}
+// decodeDate converts a Date element body, a signed nanosecond offset from
+// DateEpoch, into a time. Empty bodies are allowed and represent DateEpoch.
+func decodeDate(body []byte) (time.Time, bool) {
+	if len(body) == 0 {
+		return DateEpoch, true
+	}
+	if len(body) != 8 {
+		return time.Time{}, false
+	}
+
+	value, _ := decodeInt(body)
+	return DateEpoch.Add(time.Duration(value)), true
 }
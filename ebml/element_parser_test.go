@@ -0,0 +1,295 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// infoRecorder records every callback as a string.
+type infoRecorder struct {
+	events []string
+}
+
+func (r *infoRecorder) record(kind string, info ElementInfo, value interface{}) error {
+	r.events = append(r.events, fmt.Sprintf("%s %+v %v", kind, info, value))
+	return nil
+}
+
+func (r *infoRecorder) OnListStart(info ElementInfo) error {
+	return r.record("start", info, nil)
+}
+func (r *infoRecorder) OnListEnd(info ElementInfo) error {
+	return r.record("end", info, nil)
+}
+func (r *infoRecorder) OnBinary(info ElementInfo, value []byte) error {
+	return r.record("binary", info, value)
+}
+func (r *infoRecorder) OnInt(info ElementInfo, value int64) error {
+	return r.record("int", info, value)
+}
+func (r *infoRecorder) OnUint(info ElementInfo, value uint64) error {
+	return r.record("uint", info, value)
+}
+func (r *infoRecorder) OnFloat(info ElementInfo, value float64) error {
+	return r.record("float", info, value)
+}
+func (r *infoRecorder) OnString(info ElementInfo, value string) error {
+	return r.record("string", info, value)
+}
+func (r *infoRecorder) OnDate(info ElementInfo, value time.Time) error {
+	return r.record("date", info, value)
+}
+
+// A Segment at 0 holding a Cluster at 5 with Uints at 10 and 13.
+const resumeData = "18538067 8B 1F43B675 86 E7 81 05 E7 81 06"
+
+var resumeAncestors = []ListContext{
+	{ID: testIdSegment, Offset: 0, HeaderSize: 5, Size: 11},
+	{ID: testIdCluster, Offset: 5, HeaderSize: 5, Size: 6},
+}
+
+func TestElementParserResumeAt(t *testing.T) {
+	data := fromHex(t, resumeData)
+	schema := testSchema(t)
+
+	full := &infoRecorder{}
+	p := NewParser(schema, NewElementInfoParser(full, schema))
+	if err := p.Append(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.EndOfData(); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed := &infoRecorder{}
+	p = NewParser(schema, NewElementInfoParser(resumed, schema))
+	if err := p.ResumeAt(13, resumeAncestors); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Append(data[13:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.EndOfData(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The resumed parse should match the second Uint and the list ends.
+	if want := full.events[len(full.events)-3:]; !reflect.DeepEqual(resumed.events, want) {
+		t.Errorf("got %q, want %q", resumed.events, want)
+	}
+}
+
+func TestDecoderResumeAt(t *testing.T) {
+	data := fromHex(t, resumeData)
+	decoder := NewDecoder(bytes.NewReader(data[13:]), testSchema(t))
+	if err := decoder.ResumeAt(13, resumeAncestors); err != nil {
+		t.Fatal(err)
+	}
+
+	elements := []Element{}
+	for {
+		e, err := decoder.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		elements = append(elements, e)
+	}
+
+	want := []Element{
+		{Kind: ElementLeaf, ID: testIdUint, Type: TypeUint, Offset: 13, HeaderSize: 2, Size: 1, Value: uint64(6)},
+		{Kind: ElementListEnd, ID: testIdCluster, Type: TypeList, Offset: 5, HeaderSize: 5, Size: 6},
+		{Kind: ElementListEnd, ID: testIdSegment, Type: TypeList, Offset: 0, HeaderSize: 5, Size: 11},
+	}
+	if !reflect.DeepEqual(elements, want) {
+		t.Errorf("got %+v, want %+v", elements, want)
+	}
+}
+
+// binaryRecorder records every callback like infoRecorder but reports
+// binary elements by ID and size so that streamed and buffered elements
+// can be compared.
+type binaryRecorder struct {
+	infoRecorder
+}
+
+func (r *binaryRecorder) OnBinary(info ElementInfo, value []byte) error {
+	r.events = append(r.events, fmt.Sprintf("binary 0x%X %d %X", info.ID, info.DataSize, value))
+	return nil
+}
+
+// streamRecorder receives binary elements in chunks and records them in
+// the same form as binaryRecorder.
+type streamRecorder struct {
+	binaryRecorder
+	t      *testing.T
+	id     int
+	size   int64
+	data   []byte
+	active bool
+}
+
+func (r *streamRecorder) OnBinaryStart(id int, size int64) error {
+	if r.active {
+		r.t.Errorf("OnBinaryStart(0x%X) before the end of 0x%X", id, r.id)
+	}
+	r.id, r.size, r.data, r.active = id, size, []byte{}, true
+	return nil
+}
+
+func (r *streamRecorder) OnBinaryChunk(data []byte) error {
+	if !r.active {
+		r.t.Errorf("OnBinaryChunk() outside of a binary element")
+	}
+	r.data = append(r.data, data...)
+	return nil
+}
+
+func (r *streamRecorder) OnBinaryEnd() error {
+	if !r.active {
+		r.t.Errorf("OnBinaryEnd() outside of a binary element")
+	}
+	if int64(len(r.data)) != r.size {
+		r.t.Errorf("got %d bytes for 0x%X, OnBinaryStart() reported %d", len(r.data), r.id, r.size)
+	}
+	r.active = false
+	r.events = append(r.events, fmt.Sprintf("binary 0x%X %d %X", r.id, r.size, r.data))
+	return nil
+}
+
+// streamTestData returns a Segment holding a Cluster with a Uint, a Group
+// with a five byte and an empty Block, an element that isn't in the test
+// schema and a String.
+func streamTestData(t *testing.T) []byte {
+	data, err := EncodeNodes([]*Node{
+		NewListNode(testIdSegment,
+			NewListNode(testIdCluster,
+				NewNode(testIdUint, TypeUint, uint64(5)),
+				NewListNode(testIdGroup,
+					NewNode(testIdBlock, TypeBinary, []byte{1, 2, 3, 4, 5}),
+					NewNode(testIdBlock, TypeBinary, []byte{}),
+				),
+				NewNode(0xC5, TypeBinary, []byte{6, 7, 8}),
+				NewNode(testIdString, TypeString, "abc"),
+			),
+		),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// parseChunks appends data to a new parser for client split at every
+// offset in splits.
+func parseChunks(t *testing.T, client ElementInfoClient, data []byte, splits []int, options ParserOptions) error {
+	schema := testSchema(t)
+	p := NewParser(schema, NewElementInfoParser(client, schema))
+	p.SetOptions(options)
+	start := 0
+	for _, end := range append(splits, len(data)) {
+		if err := p.Append(data[start:end]); err != nil {
+			return err
+		}
+		start = end
+	}
+	return p.EndOfData()
+}
+
+func TestElementParserStreaming(t *testing.T) {
+	data := streamTestData(t)
+
+	buffered := &binaryRecorder{}
+	if err := parseChunks(t, buffered, data, nil, ParserOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The empty Block ends before the Group that it completes.
+	if len(buffered.events) != 11 || buffered.events[5] != fmt.Sprintf("binary 0x%X 0 ", testIdBlock) ||
+		!strings.HasPrefix(buffered.events[6], fmt.Sprintf("end {ID:%d ", testIdGroup)) {
+		t.Fatalf("got %q", buffered.events)
+	}
+
+	splits := [][]int{nil}
+	for i := 1; i < len(data); i++ {
+		splits = append(splits, []int{i})
+	}
+	byteByByte := []int{}
+	for i := 1; i < len(data); i++ {
+		byteByByte = append(byteByByte, i)
+	}
+	splits = append(splits, byteByByte)
+
+	for _, split := range splits {
+		streamed := &streamRecorder{t: t}
+		if err := parseChunks(t, streamed, data, split, ParserOptions{}); err != nil {
+			t.Errorf("split at %v: %v", split, err)
+			continue
+		}
+		if streamed.active {
+			t.Errorf("split at %v: OnBinaryEnd() wasn't called for 0x%X", split, streamed.id)
+		}
+		if !reflect.DeepEqual(streamed.events, buffered.events) {
+			t.Errorf("split at %v: got %q, want %q", split, streamed.events, buffered.events)
+		}
+	}
+}
+
+func TestElementParserStreamingBufferLimit(t *testing.T) {
+	block := make([]byte, 100)
+	for i := range block {
+		block[i] = byte(i)
+	}
+	data, err := EncodeNodes([]*Node{
+		NewListNode(testIdSegment,
+			NewListNode(testIdCluster,
+				NewListNode(testIdGroup, NewNode(testIdBlock, TypeBinary, block)),
+				NewNode(testIdUint, TypeUint, uint64(5)),
+			),
+		),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	splits := []int{}
+	for i := 8; i < len(data); i += 8 {
+		splits = append(splits, i)
+	}
+	options := ParserOptions{MaxBufferedBytes: 16}
+
+	streamed := &streamRecorder{t: t}
+	if err := parseChunks(t, streamed, data, splits, options); err != nil {
+		t.Fatalf("streaming a Block larger than MaxBufferedBytes failed: %v", err)
+	}
+	if want := fmt.Sprintf("binary 0x%X 100 %X", testIdBlock, block); len(streamed.events) != 8 || streamed.events[3] != want {
+		t.Errorf("got %q", streamed.events)
+	}
+
+	// Clients that buffer the Block are still limited.
+	if err := parseChunks(t, &binaryRecorder{}, data, splits, options); !errors.Is(err, ErrBufferLimit) {
+		t.Errorf("got %v without streaming, want ErrBufferLimit", err)
+	}
+}
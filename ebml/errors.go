@@ -0,0 +1,58 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidVarint = errors.New("invalid variable length integer")
+	ErrSizeOverflow  = errors.New("element extends past the end of its parent")
+	ErrUnknownSize   = errors.New("unexpected unknown size")
+	ErrInvalidValue  = errors.New("invalid element value")
+	ErrCRC32Mismatch = errors.New("CRC-32 mismatch")
+
+	ErrElementTooLarge = errors.New("element size exceeds the limit")
+	ErrTooDeep         = errors.New("lists nested too deeply")
+	ErrBufferLimit     = errors.New("buffered data exceeds the limit")
+)
+
+// SyntaxError describes where parsing failed. Path holds the IDs of the
+// enclosing lists followed by the ID of the element being parsed, if known.
+// Err is one of the Err* values above or the error returned by a client.
+type SyntaxError struct {
+	Offset int64
+	Path   []int
+	Err    error
+}
+
+func (e *SyntaxError) Error() string {
+	if len(e.Path) == 0 {
+		return fmt.Sprintf("ebml: %s at offset %d", e.Err.Error(), e.Offset)
+	}
+
+	path := make([]string, len(e.Path))
+	for i, id := range e.Path {
+		path[i] = fmt.Sprintf("0x%X", id)
+	}
+	return fmt.Sprintf("ebml: %s at offset %d (path /%s)", e.Err.Error(), e.Offset, strings.Join(path, "/"))
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
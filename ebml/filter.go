@@ -14,89 +14,17 @@
 
 package ebml
 
-import (
-	"log"
-)
-
-type filterClient struct {
-	ids    map[int]bool
-	writer *Writer
-}
-
-func (c *filterClient) allowId(id int) bool {
-	_, hasId := c.ids[id]
-	return !hasId
-}
-
-func (c *filterClient) OnListStart(offset int64, id int) bool {
-	if c.allowId(id) {
-		c.writer.WriteListStart(id)
-	}
-
-	return true
-}
-
-func (c *filterClient) OnListEnd(offset int64, id int) bool {
-	if c.allowId(id) {
-		c.writer.WriteListEnd(id)
-	}
-
-	return true
-}
-
-func (c *filterClient) OnBinary(id int, value []byte) bool {
-	if c.allowId(id) {
-		c.writer.Write(id, value)
-	}
-
-	return true
-}
-
-func (c *filterClient) OnInt(id int, value int64) bool {
-	if c.allowId(id) {
-		c.writer.Write(id, value)
-	}
-
-	return true
-}
-
-func (c *filterClient) OnUint(id int, value uint64) bool {
-	if c.allowId(id) {
-		c.writer.Write(id, value)
-	}
-
-	return true
-}
-
-func (c *filterClient) OnFloat(id int, value float64) bool {
-	if c.allowId(id) {
-		c.writer.Write(id, value)
-	}
-
-	return true
-}
-
-func (c *filterClient) OnString(id int, value string) bool {
-	if c.allowId(id) {
-		c.writer.Write(id, value)
-	}
-
-	return true
-}
-
-func Filter(input []byte, ids []int, typeMap map[int]int, unknownSizeInfo map[int][]int) []byte {
-	idMap := map[int]bool{}
-	for i := range ids {
-		idMap[ids[i]] = true
+// Filter removes all elements with the specified IDs from input. Lists are
+// removed together with their children. Like Transform(), the whole input
+// is parsed into memory.
+func Filter(input []byte, ids []int, schema *Schema) ([]byte, error) {
+	drop := func(n *Node) ([]*Node, error) {
+		return nil, nil
 	}
 
-	writer := NewBufferWriter(len(input))
-	parser := NewParser(GetListIDs(typeMap), unknownSizeInfo,
-		NewElementParser(&filterClient{ids: idMap, writer: NewWriter(writer)}, typeMap))
-	if !parser.Append(input) {
-		log.Printf("Filter failed to parse input.\n")
-		return nil
+	funcs := map[int]TransformFunc{}
+	for _, id := range ids {
+		funcs[id] = drop
 	}
-	parser.EndOfData()
-	return writer.Bytes()
+	return Transform(input, funcs, schema)
 }
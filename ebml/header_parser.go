@@ -15,7 +15,9 @@
 package ebml
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"time"
 )
 
 type Header interface {
@@ -66,74 +68,72 @@ func (p *parserClient) DocTypeReadVersion() uint64 {
 	return p.docTypeReadVersion
 }
 
-func (p *parserClient) OnListStart(offset int64, id int) bool {
-	return false
+func (p *parserClient) OnListStart(offset int64, id int) error {
+	return fmt.Errorf("unexpected list %s in EBML header", IdToName(id))
 }
 
-func (p *parserClient) OnListEnd(offset int64, id int) bool {
-	return false
+func (p *parserClient) OnListEnd(offset int64, id int) error {
+	return fmt.Errorf("unexpected list %s in EBML header", IdToName(id))
 }
 
-func (p *parserClient) OnBinary(id int, value []byte) bool {
-	return id == IdCRC32 || id == IdVoid
+func (p *parserClient) OnBinary(id int, value []byte) error {
+	if id == IdCRC32 || id == IdVoid {
+		return nil
+	}
+	return fmt.Errorf("unexpected element %s in EBML header", IdToName(id))
 }
 
-func (p *parserClient) OnInt(id int, value int64) bool {
-	return false
+func (p *parserClient) OnInt(id int, value int64) error {
+	return fmt.Errorf("unexpected element %s in EBML header", IdToName(id))
 }
 
-func (p *parserClient) OnUint(id int, value uint64) bool {
+func (p *parserClient) OnUint(id int, value uint64) error {
 	if id == IdVersion {
 		p.version = value
-		return true
+		return nil
 	}
 	if id == IdReadVersion {
 		p.readVersion = value
-		return true
+		return nil
 	}
 	if id == IdMaxIDLength {
 		p.maxIDLength = value
-		return true
+		return nil
 	}
 	if id == IdMaxSizeLength {
 		p.maxSizeLength = value
-		return true
+		return nil
 	}
 	if id == IdDocTypeVersion {
 		p.docTypeVersion = value
-		return true
+		return nil
 	}
 	if id == IdDocTypeReadVersion {
 		p.docTypeReadVersion = value
-		return true
+		return nil
 	}
 
-	return false
+	return fmt.Errorf("unexpected element %s in EBML header", IdToName(id))
 }
 
-func (p *parserClient) OnFloat(id int, value float64) bool {
-	return false
+func (p *parserClient) OnFloat(id int, value float64) error {
+	return fmt.Errorf("unexpected element %s in EBML header", IdToName(id))
 }
 
-func (p *parserClient) OnString(id int, value string) bool {
+func (p *parserClient) OnString(id int, value string) error {
 	if id != IdDocType {
-		return false
+		return fmt.Errorf("unexpected element %s in EBML header", IdToName(id))
 	}
 
 	p.docType = value
-	return true
+	return nil
 }
 
-func ParseHeader(buf []byte) Header {
-	typeInfo := map[int]int{
-		IdVersion:            TypeUint,
-		IdReadVersion:        TypeUint,
-		IdMaxIDLength:        TypeUint,
-		IdMaxSizeLength:      TypeUint,
-		IdDocType:            TypeString,
-		IdDocTypeVersion:     TypeUint,
-		IdDocTypeReadVersion: TypeUint}
+func (p *parserClient) OnDate(id int, value time.Time) error {
+	return fmt.Errorf("unexpected element %s in EBML header", IdToName(id))
+}
 
+func ParseHeader(buf []byte) (Header, error) {
 	client := &parserClient{
 		version:            1,
 		readVersion:        1,
@@ -142,49 +142,39 @@ func ParseHeader(buf []byte) Header {
 		docType:            "",
 		docTypeVersion:     1,
 		docTypeReadVersion: 1}
-	parser := NewParser(GetListIDs(typeInfo), map[int][]int{},
-		NewElementParser(client, typeInfo))
+	parser := NewParser(headerSchema, NewElementParser(client, headerSchema))
 
-	if !parser.Append(buf) {
-		log.Printf("Failed to parse header.")
-		return nil
+	if err := parser.Append(buf); err != nil {
+		return nil, err
 	}
 
 	if client.Version() != 1 {
-		log.Printf("Unsupported EBML Version %d", client.Version())
-		return nil
+		return nil, fmt.Errorf("unsupported EBML Version %d", client.Version())
 	}
 
 	if client.ReadVersion() != 1 {
-		log.Printf("Unsupported EBML ReadVersion %d", client.ReadVersion())
-		return nil
+		return nil, fmt.Errorf("unsupported EBML ReadVersion %d", client.ReadVersion())
 	}
 
 	if client.MaxIDLength() > 4 {
-		log.Printf("Unsupported EBML MaxIDLength %d", client.MaxIDLength())
-		return nil
-
+		return nil, fmt.Errorf("unsupported EBML MaxIDLength %d", client.MaxIDLength())
 	}
 
 	if client.MaxSizeLength() > 8 {
-		log.Printf("Unsupported EBML MaxSizeLength %d", client.MaxSizeLength())
-		return nil
+		return nil, fmt.Errorf("unsupported EBML MaxSizeLength %d", client.MaxSizeLength())
 	}
 
 	if client.DocType() == "" {
-		log.Printf("Empty EBML DocType not supported")
-		return nil
+		return nil, errors.New("empty EBML DocType not supported")
 	}
 
 	if client.DocTypeVersion() < 1 {
-		log.Printf("Unsupported EBML DocTypeVersion %d", client.DocTypeVersion())
-		return nil
+		return nil, fmt.Errorf("unsupported EBML DocTypeVersion %d", client.DocTypeVersion())
 	}
 
 	if client.DocTypeReadVersion() < 1 {
-		log.Printf("Unsupported EBML DocTypeReadVersion %d", client.DocTypeReadVersion())
-		return nil
+		return nil, fmt.Errorf("unsupported EBML DocTypeReadVersion %d", client.DocTypeReadVersion())
 	}
 
-	return client
+	return client, nil
 }
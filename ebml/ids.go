@@ -14,8 +14,6 @@
 
 package ebml
 
-import "fmt"
-
 const (
 	IdReserved           = 0x1FFFFFFF
 	IdVoid               = 0xEC
@@ -30,40 +28,42 @@ const (
 	IdDocTypeReadVersion = 0x4285
 )
 
-var idTypes = map[int]int{
-	IdVoid:               TypeBinary,
-	IdCRC32:              TypeUint,
-	IdHeader:             TypeList,
-	IdVersion:            TypeUint,
-	IdReadVersion:        TypeUint,
-	IdMaxIDLength:        TypeUint,
-	IdMaxSizeLength:      TypeUint,
-	IdDocType:            TypeString,
-	IdDocTypeVersion:     TypeUint,
-	IdDocTypeReadVersion: TypeUint,
+var headerDefs = []ElementDef{
+	{ID: IdVoid, Name: "Void", Type: TypeBinary, Parent: -1, Global: true},
+	{ID: IdCRC32, Name: "CRC32", Type: TypeBinary, Parent: -1, Global: true},
+	{ID: IdHeader, Name: "EBMLHeader", Type: TypeList, Parent: -1},
+	{ID: IdVersion, Name: "EBMLVersion", Type: TypeUint, Parent: IdHeader},
+	{ID: IdReadVersion, Name: "EBMLReadVersion", Type: TypeUint, Parent: IdHeader},
+	{ID: IdMaxIDLength, Name: "EBMLMaxIDLength", Type: TypeUint, Parent: IdHeader},
+	{ID: IdMaxSizeLength, Name: "EBMLMaxSizeLength", Type: TypeUint, Parent: IdHeader},
+	{ID: IdDocType, Name: "DocType", Type: TypeString, Parent: IdHeader},
+	{ID: IdDocTypeVersion, Name: "DocTypeVersion", Type: TypeUint, Parent: IdHeader},
+	{ID: IdDocTypeReadVersion, Name: "DocTypeReadVersion", Type: TypeUint, Parent: IdHeader},
 }
 
-func IdTypes() map[int]int {
-	return idTypes
+var headerSchema = func() *Schema {
+	s, err := NewSchema(headerDefs...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}()
+
+// HeaderSchema returns the Schema for the EBML header and the global
+// elements. Document formats extend it with their own elements.
+func HeaderSchema() *Schema {
+	return headerSchema
 }
 
-var idToName = map[int]string{
-	IdReserved:           "Reserved",
-	IdVoid:               "Void",
-	IdCRC32:              "CRC32",
-	IdHeader:             "EBMLHeader",
-	IdVersion:            "EBMLVersion",
-	IdReadVersion:        "EBMLReadVersion",
-	IdMaxIDLength:        "EBMLMaxIDLength",
-	IdMaxSizeLength:      "EBMLMaxSizeLength",
-	IdDocType:            "DocType",
-	IdDocTypeVersion:     "DocTypeVersion",
-	IdDocTypeReadVersion: "DocTypeReadVersion",
+// IdTypes returns a new map from element ID to type that callers are free
+// to modify.
+func IdTypes() map[int]int {
+	return headerSchema.TypeMap()
 }
 
 func IdToName(id int) string {
-	if name, ok := idToName[id]; ok {
-		return name
+	if id == IdReserved {
+		return "Reserved"
 	}
-	return fmt.Sprintf("UnknownID(0x%x)", id)
+	return headerSchema.Name(id)
 }
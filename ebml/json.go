@@ -0,0 +1,259 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// JSONElement is a text representation of an element that can be turned
+// back into the exact bytes it was created from. Values are stored in the
+// field matching the element's type unless re-encoding them would not
+// reproduce the original bytes, in which case Binary holds the raw body.
+// Binary values are base64 encoded by encoding/json.
+//
+// When writing elements by hand, ID may be omitted if Name is set,
+// SizeLength may be 0 to use the shortest size field and Length may be 0
+// to use the shortest encoding for integers and 8 bytes for floats.
+type JSONElement struct {
+	ID          string         `json:"id,omitempty"`
+	Name        string         `json:"name,omitempty"`
+	SizeLength  int            `json:"sizeLength,omitempty"`
+	UnknownSize bool           `json:"unknownSize,omitempty"`
+	Length      int            `json:"length,omitempty"`
+	Uint        *uint64        `json:"uint,omitempty"`
+	Int         *int64         `json:"int,omitempty"`
+	Float       *float64       `json:"float,omitempty"`
+	String      *string        `json:"string,omitempty"`
+	Date        *time.Time     `json:"date,omitempty"`
+	Binary      []byte         `json:"binary,omitempty"`
+	Children    []*JSONElement `json:"children,omitempty"`
+}
+
+// ToJSONElements converts the elements in data to JSONElements.
+func ToJSONElements(data []byte, schema *Schema) ([]*JSONElement, error) {
+	// Leaf values are decoded by toJSONElements() so bodies that can't be
+	// decoded, like zero length integers, fall back to Binary instead of
+	// failing.
+	types := map[int]int{}
+	for _, id := range schema.ListIDs() {
+		types[id] = TypeList
+	}
+	nodes, err := ParseNodes(data, schema.WithTypes(types))
+	if err != nil {
+		return nil, err
+	}
+	return toJSONElements(data, nodes, schema)
+}
+
+func toJSONElements(data []byte, nodes []*Node, schema *Schema) ([]*JSONElement, error) {
+	elements := []*JSONElement{}
+	for _, n := range nodes {
+		idLen := idLength(n.ID)
+		sizeLength := n.HeaderSize - idLen
+		e := &JSONElement{ID: fmt.Sprintf("0x%X", n.ID), Name: schema.Name(n.ID), SizeLength: sizeLength}
+
+		// All value bits set in the size field means the size is unknown.
+		sizeField := data[n.Offset+int64(idLen) : n.Offset+int64(n.HeaderSize)]
+		e.UnknownSize = sizeField[0] == 0xff>>uint(sizeLength-1)
+		for _, b := range sizeField[1:] {
+			e.UnknownSize = e.UnknownSize && b == 0xff
+		}
+
+		if n.Type == TypeList {
+			children, err := toJSONElements(data, n.Children, schema)
+			if err != nil {
+				return nil, err
+			}
+			e.Children = children
+			elements = append(elements, e)
+			continue
+		}
+
+		body := data[n.Offset+int64(n.HeaderSize) : n.Offset+int64(n.HeaderSize)+n.Size]
+		elementType, _ := schema.Type(n.ID)
+		if value, ok := decodeValue(elementType, body); ok {
+			setJSONValue(e, value, len(body))
+		}
+
+		encoded, err := e.encodeBody()
+		if err != nil || !bytes.Equal(encoded, body) {
+			e.Length = 0
+			e.Uint, e.Int, e.Float, e.String, e.Date = nil, nil, nil, nil, nil
+			e.Binary = append([]byte{}, body...)
+		}
+		elements = append(elements, e)
+	}
+	return elements, nil
+}
+
+func setJSONValue(e *JSONElement, value interface{}, length int) {
+	switch v := value.(type) {
+	case uint64:
+		e.Uint = &v
+		if length != uintLength(v) {
+			e.Length = length
+		}
+	case int64:
+		e.Int = &v
+		if length != intLength(v) {
+			e.Length = length
+		}
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			break
+		}
+		e.Float = &v
+		if length != 8 {
+			e.Length = length
+		}
+	case string:
+		// encoding/json would replace invalid UTF-8 sequences.
+		if !utf8.ValidString(v) {
+			break
+		}
+		e.String = &v
+	case time.Time:
+		e.Date = &v
+	case []byte:
+		e.Binary = v
+	}
+}
+
+func uintLength(value uint64) int {
+	length := 1
+	for ; length < 8 && value >= uint64(1)<<uint(8*length); length++ {
+	}
+	return length
+}
+
+func intLength(value int64) int {
+	length := 1
+	for ; length < 8; length++ {
+		limit := int64(1) << uint(8*length-1)
+		if value >= -limit && value < limit {
+			break
+		}
+	}
+	return length
+}
+
+func (e *JSONElement) encodeBody() ([]byte, error) {
+	switch {
+	case e.Uint != nil:
+		length := e.Length
+		if length == 0 {
+			length = uintLength(*e.Uint)
+		}
+		if length > 8 {
+			return nil, fmt.Errorf("ebml: invalid length %d for %s", length, e.Name)
+		}
+		buf := [8]byte{}
+		binary.BigEndian.PutUint64(buf[:], *e.Uint)
+		return buf[8-length:], nil
+	case e.Int != nil:
+		length := e.Length
+		if length == 0 {
+			length = intLength(*e.Int)
+		}
+		if length > 8 {
+			return nil, fmt.Errorf("ebml: invalid length %d for %s", length, e.Name)
+		}
+		buf := [8]byte{}
+		binary.BigEndian.PutUint64(buf[:], uint64(*e.Int))
+		return buf[8-length:], nil
+	case e.Float != nil:
+		switch e.Length {
+		case 4:
+			buf := [4]byte{}
+			binary.BigEndian.PutUint32(buf[:], math.Float32bits(float32(*e.Float)))
+			return buf[:], nil
+		case 0, 8:
+			buf := [8]byte{}
+			binary.BigEndian.PutUint64(buf[:], math.Float64bits(*e.Float))
+			return buf[:], nil
+		}
+		return nil, fmt.Errorf("ebml: invalid length %d for %s", e.Length, e.Name)
+	case e.String != nil:
+		return []byte(*e.String), nil
+	case e.Date != nil:
+		buf := [8]byte{}
+		binary.BigEndian.PutUint64(buf[:], uint64(e.Date.Sub(DateEpoch).Nanoseconds()))
+		return buf[:], nil
+	}
+	return e.Binary, nil
+}
+
+// FromJSONElements encodes elements, producing the bytes they were created
+// from by ToJSONElements(). Elements without an ID are looked up by name.
+func FromJSONElements(elements []*JSONElement, schema *Schema) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := writeJSONElements(NewNonSeekableWriter(buf), elements, schema); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeJSONElements(w *Writer, elements []*JSONElement, schema *Schema) error {
+	for _, e := range elements {
+		id, ok := schema.ID(e.Name)
+		if e.ID != "" {
+			value, err := strconv.ParseInt(e.ID, 0, 64)
+			if err != nil {
+				return fmt.Errorf("ebml: invalid id %s", e.ID)
+			}
+			id, ok = int(value), true
+		}
+		if !ok {
+			return fmt.Errorf("ebml: unknown element %s", e.Name)
+		}
+
+		var body []byte
+		var err error
+		if elementType, present := schema.Type(id); e.Children != nil || (present && elementType == TypeList) {
+			buf := &bytes.Buffer{}
+			if err := writeJSONElements(NewNonSeekableWriter(buf), e.Children, schema); err != nil {
+				return err
+			}
+			body = buf.Bytes()
+		} else if body, err = e.encodeBody(); err != nil {
+			return err
+		}
+
+		size := int64(len(body))
+		sizeLength := e.SizeLength
+		if sizeLength == 0 {
+			for sizeLength = 1; sizeLength < 8 && size >= int64(1)<<uint(7*sizeLength)-1; sizeLength++ {
+			}
+		}
+		if e.UnknownSize {
+			size = -1
+		}
+
+		if _, err := w.WriteElementHeader(id, size, sizeLength); err != nil {
+			return err
+		}
+		if _, err := w.WriteToOutput(body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func fromHex(t *testing.T, s string) []byte {
+	data, err := hex.DecodeString(strings.Replace(s, " ", "", -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"zero length uint", "1A45DFA3 83 4286 80"},
+		{"header", "1A45DFA3 8E 4286 81 01 4282 84 7765626D EC 81 00"},
+		{"padded uint", "18538067 8B 1F43B675 86 E7 84 00000001"},
+		{"8 byte size", "18538067 0100000000000009 1F43B675 84 E7 82 0001"},
+		{"zero length int and float", "18538067 8B 1F43B675 86 FB 80 B5 80 E7 80"},
+		{"short float", "18538067 8B 1F43B675 86 B5 84 3F800000"},
+		{"invalid float length", "18538067 8A 1F43B675 85 B5 83 3F8000"},
+		{"negative int", "18538067 8B 1F43B675 86 FB 84 FFFFFFFE"},
+		{"zero length date", "18538067 8A 1F43B675 85 4461 80 86 80"},
+		{"date", "18538067 90 1F43B675 8B 4461 88 0000000000000001"},
+		{"invalid utf-8", "18538067 89 1F43B675 84 86 82 FF FE"},
+		{"unknown id", "18538067 89 1F43B675 84 5555 81 01"},
+	}
+
+	schema := testSchema(t)
+	for _, test := range tests {
+		data := fromHex(t, test.data)
+		elements, err := ToJSONElements(data, schema)
+		if err != nil {
+			t.Errorf("%s: ToJSONElements() failed: %v", test.name, err)
+			continue
+		}
+
+		text, err := json.Marshal(elements)
+		if err != nil {
+			t.Errorf("%s: json.Marshal() failed: %v", test.name, err)
+			continue
+		}
+		elements = nil
+		if err := json.Unmarshal(text, &elements); err != nil {
+			t.Errorf("%s: json.Unmarshal() failed: %v", test.name, err)
+			continue
+		}
+
+		output, err := FromJSONElements(elements, schema)
+		if err != nil {
+			t.Errorf("%s: FromJSONElements() failed: %v", test.name, err)
+			continue
+		}
+		if !bytes.Equal(output, data) {
+			t.Errorf("%s: got %X, want %X (JSON %s)", test.name, output, data, text)
+		}
+	}
+}
+
+func TestJSONValues(t *testing.T) {
+	data := fromHex(t, "18538067 91 1F43B675 8C E7 80 E7 82 0102 86 82 6869 B5 80")
+	elements, err := ToJSONElements(data, testSchema(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	children := elements[0].Children[0].Children
+	if len(children) != 4 {
+		t.Fatalf("got %d elements, want 4", len(children))
+	}
+	if children[0].Uint != nil || len(children[0].Binary) != 0 {
+		t.Errorf("zero length uint got a value")
+	}
+	if children[1].Uint == nil || *children[1].Uint != 0x102 || children[1].Length != 0 {
+		t.Errorf("got uint %v length %d, want 258", children[1].Uint, children[1].Length)
+	}
+	if children[2].String == nil || *children[2].String != "hi" {
+		t.Errorf("got string %v, want hi", children[2].String)
+	}
+	if children[3].Name != "Float" || children[3].Float != nil {
+		t.Errorf("zero length float got a value")
+	}
+}
+
+func TestFromJSONElementsByName(t *testing.T) {
+	one := uint64(1)
+	webm := "webm"
+	elements := []*JSONElement{{Name: "EBMLHeader", Children: []*JSONElement{
+		{Name: "EBMLVersion", Uint: &one},
+		{Name: "DocType", String: &webm},
+	}}}
+
+	output, err := FromJSONElements(elements, testSchema(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fromHex(t, "1A45DFA3 8B 4286 81 01 4282 84 7765626D"); !bytes.Equal(output, want) {
+		t.Errorf("got %X, want %X", output, want)
+	}
+
+	if _, err := FromJSONElements([]*JSONElement{{Name: "NoSuchElement"}}, testSchema(t)); err == nil {
+		t.Errorf("unknown name didn't fail")
+	}
+}
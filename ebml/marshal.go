@@ -0,0 +1,378 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type fieldInfo struct {
+	index        int
+	id           int
+	omitEmpty    bool
+	hasDefault   bool
+	defaultValue string
+}
+
+func parseTag(tag string) (fieldInfo, error) {
+	parts := strings.Split(tag, ",")
+	id, err := strconv.ParseInt(parts[0], 0, 64)
+	if err != nil {
+		return fieldInfo{}, fmt.Errorf("ebml: invalid element ID in tag %q", tag)
+	}
+
+	f := fieldInfo{id: int(id)}
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			f.omitEmpty = true
+		} else if strings.HasPrefix(option, "default=") {
+			f.hasDefault = true
+			f.defaultValue = strings.TrimPrefix(option, "default=")
+		}
+	}
+	return f, nil
+}
+
+func structFields(t reflect.Type) ([]fieldInfo, error) {
+	fields := []fieldInfo{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("ebml")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		if field.PkgPath != "" {
+			return nil, fmt.Errorf("ebml: field %s of %s has an ebml tag but isn't exported", field.Name, t)
+		}
+
+		f, err := parseTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		f.index = i
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+func elementTypeOf(t reflect.Type) (int, error) {
+	if isBytes(t) {
+		return TypeBinary, nil
+	}
+	if t == timeType {
+		return TypeDate, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return TypeList, nil
+	case reflect.Bool, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return TypeUint, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return TypeInt, nil
+	case reflect.Float32, reflect.Float64:
+		return TypeFloat, nil
+	case reflect.String:
+		return TypeString, nil
+	}
+	return 0, fmt.Errorf("ebml: unsupported field type %s", t)
+}
+
+// fieldElementType strips slices and pointers that only describe how
+// often an element occurs.
+func fieldElementType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Slice && !isBytes(t) {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func collectTypes(t reflect.Type, typeMap map[int]int, visited map[reflect.Type]bool) error {
+	if visited[t] {
+		return nil
+	}
+	visited[t] = true
+
+	fields, err := structFields(t)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		ft := fieldElementType(t.Field(f.index).Type)
+		elementType, err := elementTypeOf(ft)
+		if err != nil {
+			return err
+		}
+
+		if existingType, present := typeMap[f.id]; present && existingType != elementType {
+			return fmt.Errorf("ebml: conflicting types for element 0x%X", f.id)
+		}
+		typeMap[f.id] = elementType
+
+		if elementType == TypeList {
+			if err := collectTypes(ft, typeMap, visited); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Unmarshal parses the list body in data and stores the elements in the
+// struct pointed to by v. Elements without a matching field are ignored.
+// Fields tagged with `ebml:"0xB9,default=1"` are set to the default before
+// the list is unmarshaled. See Marshal for how fields map to elements.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("ebml: Unmarshal requires a non-nil struct pointer")
+	}
+
+	typeMap := map[int]int{}
+	if err := collectTypes(rv.Elem().Type(), typeMap, map[reflect.Type]bool{}); err != nil {
+		return err
+	}
+
+	nodes, err := ParseNodes(data, (&Schema{}).WithTypes(typeMap))
+	if err != nil {
+		return err
+	}
+	return unmarshalStruct(rv.Elem(), nodes)
+}
+
+func unmarshalStruct(v reflect.Value, nodes []*Node) error {
+	fields, err := structFields(v.Type())
+	if err != nil {
+		return err
+	}
+
+	fieldMap := map[int]int{}
+	for _, f := range fields {
+		fieldMap[f.id] = f.index
+		if f.hasDefault {
+			if err := setDefault(v.Field(f.index), f.defaultValue); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, n := range nodes {
+		index, present := fieldMap[n.ID]
+		if !present {
+			continue
+		}
+
+		field := v.Field(index)
+		if field.Kind() == reflect.Slice && !isBytes(field.Type()) {
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if err := unmarshalValue(elem, n); err != nil {
+				return err
+			}
+			field.Set(reflect.Append(field, elem))
+			continue
+		}
+
+		if err := unmarshalValue(field, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setDefault(v reflect.Value, value string) error {
+	var err error
+	switch v.Kind() {
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(value)
+		v.SetBool(b)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		u, err = strconv.ParseUint(value, 0, v.Type().Bits())
+		v.SetUint(u)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(value, 0, v.Type().Bits())
+		v.SetInt(i)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(value, v.Type().Bits())
+		v.SetFloat(f)
+	case reflect.String:
+		v.SetString(value)
+	default:
+		return fmt.Errorf("ebml: default values aren't supported for %s", v.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("ebml: invalid default %q for %s", value, v.Type())
+	}
+	return nil
+}
+
+func unmarshalValue(v reflect.Value, n *Node) error {
+	if v.Kind() == reflect.Ptr {
+		elem := reflect.New(v.Type().Elem())
+		if err := unmarshalValue(elem.Elem(), n); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	if isBytes(v.Type()) {
+		v.SetBytes(n.Value.([]byte))
+		return nil
+	}
+	if v.Type() == timeType {
+		v.Set(reflect.ValueOf(n.Value.(time.Time)))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return unmarshalStruct(v, n.Children)
+	case reflect.Bool:
+		v.SetBool(n.Value.(uint64) != 0)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value := n.Value.(uint64)
+		if v.OverflowUint(value) {
+			return fmt.Errorf("ebml: value %d overflows element 0x%X", value, n.ID)
+		}
+		v.SetUint(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value := n.Value.(int64)
+		if v.OverflowInt(value) {
+			return fmt.Errorf("ebml: value %d overflows element 0x%X", value, n.ID)
+		}
+		v.SetInt(value)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(n.Value.(float64))
+	case reflect.String:
+		v.SetString(n.Value.(string))
+	default:
+		return fmt.Errorf("ebml: unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+// Marshal returns the list body encoding of the struct v. Exported struct
+// fields are mapped to elements with tags of the form `ebml:"0xD7"` or
+// `ebml:"0xD7,omitempty"`. Unsigned integer and bool fields map to uint
+// elements, signed integers to int elements, nested structs to lists,
+// time.Time to dates and slices to repeated elements.
+func Marshal(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("ebml: Marshal requires a struct")
+	}
+
+	bw := NewBufferWriter(1024)
+	if err := marshalStruct(NewWriter(bw), rv); err != nil {
+		return nil, err
+	}
+	return bw.Bytes(), nil
+}
+
+func marshalStruct(w *Writer, v reflect.Value) error {
+	fields, err := structFields(v.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		field := v.Field(f.index)
+		if f.omitEmpty && field.IsZero() {
+			continue
+		}
+
+		if field.Kind() == reflect.Slice && !isBytes(field.Type()) {
+			for i := 0; i < field.Len(); i++ {
+				if err := marshalValue(w, f.id, field.Index(i)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if err := marshalValue(w, f.id, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func marshalValue(w *Writer, id int, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	var data interface{}
+	if isBytes(v.Type()) {
+		data = v.Bytes()
+	} else if v.Type() == timeType {
+		data = v.Interface()
+	} else {
+		switch v.Kind() {
+		case reflect.Struct:
+			body, err := Marshal(v.Interface())
+			if err != nil {
+				return err
+			}
+			data = body
+		case reflect.Bool:
+			value := uint64(0)
+			if v.Bool() {
+				value = 1
+			}
+			data = value
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			data = v.Uint()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			data = v.Int()
+		case reflect.Float32:
+			data = float32(v.Float())
+		case reflect.Float64:
+			data = v.Float()
+		case reflect.String:
+			data = v.String()
+		default:
+			return fmt.Errorf("ebml: unsupported field type %s", v.Type())
+		}
+	}
+
+	_, err := w.Write(id, data)
+	return err
+}
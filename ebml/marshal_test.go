@@ -0,0 +1,179 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type marshalChild struct {
+	Name  string `ebml:"0x86"`
+	Count uint8  `ebml:"0xE7,omitempty"`
+}
+
+type marshalStructs struct {
+	Flag     bool           `ebml:"0x88"`
+	Uint     uint64         `ebml:"0xE7"`
+	Int      int32          `ebml:"0xFB"`
+	Float    float64        `ebml:"0xB5"`
+	String   string         `ebml:"0x86"`
+	Date     time.Time      `ebml:"0x4461"`
+	Binary   []byte         `ebml:"0xA1"`
+	Child    marshalChild   `ebml:"0xA0"`
+	Children []marshalChild `ebml:"0xA6"`
+	Optional *uint64        `ebml:"0xEE"`
+	Ignored  int
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	optional := uint64(7)
+	v := &marshalStructs{
+		Flag:     true,
+		Uint:     300,
+		Int:      -2,
+		Float:    1.5,
+		String:   "hi",
+		Date:     time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Binary:   []byte{1, 2, 3},
+		Child:    marshalChild{Name: "a", Count: 1},
+		Children: []marshalChild{{Name: "b"}, {Name: "c", Count: 3}},
+		Optional: &optional,
+	}
+
+	data, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &marshalStructs{}
+	if err := Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Date.Equal(v.Date) {
+		t.Errorf("got date %s, want %s", got.Date, v.Date)
+	}
+	got.Date = v.Date
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("got %+v, want %+v", got, v)
+	}
+}
+
+func TestMarshalEncoding(t *testing.T) {
+	data, err := Marshal(marshalChild{Name: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Count is omitted because it is zero.
+	if want := fromHex(t, "86 82 6869"); !bytes.Equal(data, want) {
+		t.Errorf("got %X, want %X", data, want)
+	}
+
+	type nilPointer struct {
+		Value *uint64 `ebml:"0xE7"`
+	}
+	if data, err := Marshal(&nilPointer{}); err != nil || len(data) != 0 {
+		t.Errorf("got %X %v for a nil pointer, want no elements", data, err)
+	}
+}
+
+func TestMarshalPointers(t *testing.T) {
+	type pointers struct {
+		Child    *marshalChild   `ebml:"0xA0"`
+		Missing  *marshalChild   `ebml:"0xA5"`
+		Children []*marshalChild `ebml:"0xA6"`
+	}
+
+	v := &pointers{
+		Child:    &marshalChild{Name: "a", Count: 1},
+		Children: []*marshalChild{{Name: "b"}, {Name: "c", Count: 3}},
+	}
+	data, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fromHex(t, "A0 86 86 81 61 E7 81 01 A6 83 86 81 62 A6 86 86 81 63 E7 81 03")
+	if !bytes.Equal(data, want) {
+		t.Errorf("got %X, want %X", data, want)
+	}
+
+	got := &pointers{}
+	if err := Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("got %+v, want %+v", got, v)
+	}
+}
+
+func TestUnmarshalDefaults(t *testing.T) {
+	type defaults struct {
+		Enabled  bool    `ebml:"0x88,default=1"`
+		Language string  `ebml:"0x86,default=eng"`
+		Scale    uint64  `ebml:"0xE7,default=1000000"`
+		Rate     float64 `ebml:"0xB5,default=8000.0"`
+	}
+
+	v := &defaults{}
+	if err := Unmarshal(fromHex(t, "86 83 667261 EC 81 00"), v); err != nil {
+		t.Fatal(err)
+	}
+	want := &defaults{Enabled: true, Language: "fra", Scale: 1000000, Rate: 8000}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("got %+v, want %+v", v, want)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	type unexported struct {
+		value uint64 `ebml:"0xE7"`
+	}
+	if _, err := Marshal(&unexported{value: 1}); err == nil {
+		t.Errorf("Marshal() accepted an unexported field")
+	}
+	if err := Unmarshal(fromHex(t, "E7 81 01"), &unexported{}); err == nil {
+		t.Errorf("Unmarshal() accepted an unexported field")
+	}
+
+	type badTag struct {
+		Value uint64 `ebml:"E7"`
+	}
+	if _, err := Marshal(&badTag{}); err == nil {
+		t.Errorf("Marshal() accepted an invalid tag")
+	}
+
+	type conflict struct {
+		Uint   uint64 `ebml:"0xE7"`
+		String string `ebml:"0xE7"`
+	}
+	if err := Unmarshal(nil, &conflict{}); err == nil {
+		t.Errorf("Unmarshal() accepted conflicting element types")
+	}
+
+	type small struct {
+		Value uint8 `ebml:"0xE7"`
+	}
+	if err := Unmarshal(fromHex(t, "E7 82 0100"), &small{}); err == nil {
+		t.Errorf("Unmarshal() accepted a value that overflows the field")
+	}
+
+	if err := Unmarshal(nil, small{}); err == nil {
+		t.Errorf("Unmarshal() accepted a non-pointer")
+	}
+	if _, err := Marshal(1); err == nil {
+		t.Errorf("Marshal() accepted a non-struct")
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+)
+
+// Node is an element in an in-memory EBML document. List nodes hold their
+// children in Children and leaf nodes hold a uint64, int64, float64,
+// string, time.Time or []byte in Value. Offset, HeaderSize and Size
+// describe where the element was found when parsed and are ignored when
+// writing.
+type Node struct {
+	ID         int
+	Type       int
+	Offset     int64
+	HeaderSize int
+	Size       int64
+	Value      interface{}
+	Children   []*Node
+}
+
+func NewNode(id int, elementType int, value interface{}) *Node {
+	return &Node{ID: id, Type: elementType, Offset: -1, Size: -1, Value: value}
+}
+
+func NewListNode(id int, children ...*Node) *Node {
+	return &Node{ID: id, Type: TypeList, Offset: -1, Size: -1, Children: children}
+}
+
+func ParseNodes(buf []byte, schema *Schema) ([]*Node, error) {
+	return ReadNodes(bytes.NewReader(buf), schema, NewNodeBuilder())
+}
+
+// ReadNodes decodes r to the end and adds every element to builder. It
+// returns the builder's top level nodes.
+func ReadNodes(r io.Reader, schema *Schema, builder *NodeBuilder) ([]*Node, error) {
+	decoder := NewDecoder(r, schema)
+	for {
+		e, err := decoder.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		builder.Add(e)
+	}
+
+	return builder.Nodes(), nil
+}
+
+// ReadNode decodes the element that starts at offset in r. Only the bytes
+// needed to find the end of the element are read.
+func ReadNode(r io.ReaderAt, offset int64, schema *Schema) (*Node, error) {
+	builder := NewNodeBuilder()
+
+	decoder := NewDecoder(io.NewSectionReader(r, offset, math.MaxInt64-offset), schema)
+	for {
+		e, err := decoder.Next()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		e.Offset += offset
+		builder.Add(e)
+		if nodes := builder.Nodes(); len(nodes) > 0 && builder.Depth() == 0 {
+			return nodes[0], nil
+		}
+	}
+}
+
+// NodeBuilder builds a Node tree from the elements returned by
+// Decoder.Next().
+type NodeBuilder struct {
+	// Skip, if set, is called for every ListStart and leaf element with the
+	// number of lists enclosing it. Elements it returns true for are left
+	// out of the tree, along with the children of skipped lists.
+	Skip func(e Element, depth int) bool
+
+	root    *Node
+	stack   []*Node
+	skipped int
+}
+
+func NewNodeBuilder() *NodeBuilder {
+	root := NewListNode(-1)
+	return &NodeBuilder{root: root, stack: []*Node{root}}
+}
+
+// Add adds e to the tree. Skipped tokens are ignored.
+func (b *NodeBuilder) Add(e Element) {
+	if b.skipped > 0 {
+		switch e.Kind {
+		case ElementListStart:
+			b.skipped++
+		case ElementListEnd:
+			b.skipped--
+		}
+		return
+	}
+
+	parent := b.stack[len(b.stack)-1]
+	switch e.Kind {
+	case ElementListStart:
+		if b.Skip != nil && b.Skip(e, b.Depth()) {
+			b.skipped = 1
+			return
+		}
+		n := &Node{ID: e.ID, Type: e.Type, Offset: e.Offset, HeaderSize: e.HeaderSize, Size: e.Size, Children: []*Node{}}
+		parent.Children = append(parent.Children, n)
+		b.stack = append(b.stack, n)
+	case ElementListEnd:
+		parent.Size = e.Size
+		b.stack = b.stack[:len(b.stack)-1]
+	case ElementLeaf:
+		if b.Skip != nil && b.Skip(e, b.Depth()) {
+			return
+		}
+		n := &Node{ID: e.ID, Type: e.Type, Offset: e.Offset, HeaderSize: e.HeaderSize, Size: e.Size, Value: e.Value}
+		parent.Children = append(parent.Children, n)
+	}
+}
+
+// Nodes returns the top level nodes added so far.
+func (b *NodeBuilder) Nodes() []*Node {
+	return b.root.Children
+}
+
+// Depth returns the number of lists that have started but not ended.
+func (b *NodeBuilder) Depth() int {
+	return len(b.stack) - 1
+}
+
+// Child returns the first child with the specified ID or nil if there
+// isn't one.
+func (n *Node) Child(id int) *Node {
+	for _, child := range n.Children {
+		if child.ID == id {
+			return child
+		}
+	}
+	return nil
+}
+
+func (n *Node) ChildrenWithID(id int) []*Node {
+	children := []*Node{}
+	for _, child := range n.Children {
+		if child.ID == id {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// Lookup follows the first child with each ID in path and returns the
+// final node or nil if any step is missing.
+func (n *Node) Lookup(path ...int) *Node {
+	current := n
+	for _, id := range path {
+		if current = current.Child(id); current == nil {
+			return nil
+		}
+	}
+	return current
+}
+
+func (n *Node) AppendChild(child *Node) {
+	n.Children = append(n.Children, child)
+}
+
+func (n *Node) InsertChild(index int, child *Node) {
+	if index < 0 || index > len(n.Children) {
+		index = len(n.Children)
+	}
+	n.Children = append(n.Children, nil)
+	copy(n.Children[index+1:], n.Children[index:])
+	n.Children[index] = child
+}
+
+func (n *Node) RemoveChild(child *Node) bool {
+	for i := range n.Children {
+		if n.Children[i] == child {
+			n.Children = append(n.Children[:i], n.Children[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveChildren removes all children with the specified ID and returns
+// the number of children removed.
+func (n *Node) RemoveChildren(id int) int {
+	children := []*Node{}
+	for _, child := range n.Children {
+		if child.ID != id {
+			children = append(children, child)
+		}
+	}
+	removed := len(n.Children) - len(children)
+	n.Children = children
+	return removed
+}
+
+func (n *Node) ReplaceChild(oldChild *Node, newChild *Node) bool {
+	for i := range n.Children {
+		if n.Children[i] == oldChild {
+			n.Children[i] = newChild
+			return true
+		}
+	}
+	return false
+}
+
+// Write serializes the node. List bodies are assembled in memory so the
+// written lists always have known sizes, even on non-seekable writers.
+func (n *Node) Write(w *Writer) (int, error) {
+	if n.Type != TypeList {
+		if n.Value == nil {
+			return 0, errors.New("ebml: node has no value")
+		}
+		return w.Write(n.ID, n.Value)
+	}
+
+	body, err := EncodeNodes(n.Children)
+	if err != nil {
+		return 0, err
+	}
+	return w.Write(n.ID, body)
+}
+
+func WriteNodes(w *Writer, nodes []*Node) (int, error) {
+	total := 0
+	for _, n := range nodes {
+		count, err := n.Write(w)
+		total += count
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func EncodeNodes(nodes []*Node) ([]byte, error) {
+	bw := NewBufferWriter(1024)
+	if _, err := WriteNodes(NewWriter(bw), nodes); err != nil {
+		return nil, err
+	}
+	return bw.Bytes(), nil
+}
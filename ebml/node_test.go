@@ -0,0 +1,148 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import (
+	"bytes"
+	"testing"
+)
+
+// A Segment holding a Cluster with a Uint, a String and a Group holding a
+// Block.
+const nodeData = "18538067 92 1F43B675 8D E7 81 05 86 82 6869 A0 84 A1 82 0102"
+
+func TestParseNodes(t *testing.T) {
+	data := fromHex(t, nodeData)
+	nodes, err := ParseNodes(data, testSchema(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 {
+		t.Fatalf("got %d top level nodes, want 1", len(nodes))
+	}
+
+	segment := nodes[0]
+	if segment.ID != testIdSegment || segment.Offset != 0 || segment.HeaderSize != 5 || segment.Size != 18 {
+		t.Errorf("got segment %+v", segment)
+	}
+
+	cluster := segment.Child(testIdCluster)
+	if cluster == nil || len(cluster.Children) != 3 {
+		t.Fatalf("got cluster %+v", cluster)
+	}
+	if n := cluster.Child(testIdUint); n == nil || n.Value != uint64(5) || n.Offset != 10 || n.Size != 1 {
+		t.Errorf("got uint %+v", n)
+	}
+	if n := cluster.Child(testIdString); n == nil || n.Value != "hi" {
+		t.Errorf("got string %+v", n)
+	}
+	block := segment.Lookup(testIdCluster, testIdGroup, testIdBlock)
+	if block == nil || !bytes.Equal(block.Value.([]byte), []byte{1, 2}) || block.Offset != 19 {
+		t.Errorf("got block %+v", block)
+	}
+	if segment.Lookup(testIdCluster, testIdBlock) != nil {
+		t.Errorf("Lookup() found a Block outside of a Group")
+	}
+
+	output, err := EncodeNodes(nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(output, data) {
+		t.Errorf("got %X, want %X", output, data)
+	}
+}
+
+func TestNodeBuilderSkip(t *testing.T) {
+	builder := NewNodeBuilder()
+	depths := map[int]int{}
+	builder.Skip = func(e Element, depth int) bool {
+		depths[e.ID] = depth
+		return e.ID == testIdGroup || e.ID == testIdString
+	}
+	nodes, err := ReadNodes(bytes.NewReader(fromHex(t, nodeData)), testSchema(t), builder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nodes) != 1 || nodes[0].Size != 18 {
+		t.Fatalf("got %+v", nodes)
+	}
+	cluster := nodes[0].Child(testIdCluster)
+	if cluster == nil || cluster.Size != 13 || len(cluster.Children) != 1 || cluster.Child(testIdUint) == nil {
+		t.Errorf("got cluster %+v, want only the Uint", cluster)
+	}
+	if _, present := depths[testIdBlock]; present {
+		t.Errorf("Skip() was called for a Block inside a skipped Group")
+	}
+	if depths[testIdSegment] != 0 || depths[testIdCluster] != 1 || depths[testIdGroup] != 2 {
+		t.Errorf("got depths %v", depths)
+	}
+}
+
+func TestReadNode(t *testing.T) {
+	data := fromHex(t, nodeData)
+	n, err := ReadNode(bytes.NewReader(data), 17, testSchema(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.ID != testIdGroup || n.Offset != 17 || n.Size != 4 || len(n.Children) != 1 {
+		t.Errorf("got %+v", n)
+	}
+	if n.Children[0].Offset != 19 {
+		t.Errorf("got child offset %d, want 19", n.Children[0].Offset)
+	}
+
+	if _, err := ReadNode(bytes.NewReader(data[:20]), 17, testSchema(t)); err == nil {
+		t.Errorf("ReadNode() accepted a truncated element")
+	}
+}
+
+func TestNodeEditing(t *testing.T) {
+	cluster := NewListNode(testIdCluster,
+		NewNode(testIdUint, TypeUint, uint64(1)),
+		NewNode(testIdUint, TypeUint, uint64(2)))
+
+	str := NewNode(testIdString, TypeString, "a")
+	cluster.InsertChild(1, str)
+	cluster.InsertChild(-1, NewNode(testIdUint, TypeUint, uint64(3)))
+	if len(cluster.ChildrenWithID(testIdUint)) != 3 || cluster.Children[1] != str {
+		t.Fatalf("InsertChild() got %+v", cluster.Children)
+	}
+
+	group := NewListNode(testIdGroup, NewNode(testIdBlock, TypeBinary, []byte{0xFF}))
+	if !cluster.ReplaceChild(str, group) || cluster.ReplaceChild(str, group) {
+		t.Errorf("ReplaceChild() didn't replace the child exactly once")
+	}
+	if cluster.RemoveChildren(testIdUint) != 3 {
+		t.Errorf("RemoveChildren() didn't remove 3 children")
+	}
+
+	output, err := EncodeNodes([]*Node{cluster})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fromHex(t, "1F43B675 85 A0 83 A1 81 FF"); !bytes.Equal(output, want) {
+		t.Errorf("got %X, want %X", output, want)
+	}
+
+	if !cluster.RemoveChild(group) || cluster.RemoveChild(group) || len(cluster.Children) != 0 {
+		t.Errorf("RemoveChild() didn't remove the child exactly once")
+	}
+
+	if _, err := EncodeNodes([]*Node{{ID: testIdUint, Type: TypeUint}}); err == nil {
+		t.Errorf("EncodeNodes() accepted a leaf without a value")
+	}
+}
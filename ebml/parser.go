@@ -16,19 +16,72 @@ package ebml
 
 import (
 	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
 	//"log"
 )
 
 type ParserClient interface {
-	OnHeader(offset int64, hdr []byte, id int, size int64) bool
-	OnBody(offset int64, body []byte) bool
-	OnElementEnd(offset int64, id int) bool
+	OnHeader(offset int64, hdr []byte, id int, size int64) error
+	OnBody(offset int64, body []byte) error
+	OnElementEnd(offset int64, id int) error
+}
+
+// ResyncClient can be implemented by a ParserClient to find out about data
+// that was skipped while resynchronizing after corrupt or truncated input.
+type ResyncClient interface {
+	OnResync(offset int64, size int64) error
+}
+
+// ResumeClient can be implemented by a ParserClient that tracks open lists.
+// OnResume() is called by ResumeAt() with the lists that enclose offset,
+// before the OnElementEnd() calls for any of them that are already complete.
+type ResumeClient interface {
+	OnResume(offset int64, ancestors []ListContext) error
+}
+
+// StreamingClient can be implemented by a ParserClient that passes some
+// element bodies on as they arrive instead of holding them in memory.
+type StreamingClient interface {
+	StreamsBody(id int) bool
+}
+
+// ParserOptions limits the resources used while parsing untrusted input.
+// A zero field means there is no limit.
+type ParserOptions struct {
+	// MaxElementSize is the largest size any element may declare.
+	MaxElementSize int64
+
+	// MaxDepth is the maximum number of nested lists.
+	MaxDepth int
+
+	// MaxBufferedBytes is the largest body a non-list element may have,
+	// since clients like ElementParser and Decoder accumulate the body in
+	// memory. Bodies that a StreamingClient streams aren't limited. The
+	// parser itself only buffers the partial header at the end of the input.
+	MaxBufferedBytes int64
+}
+
+// ListContext describes a list that encloses the offset passed to
+// ResumeAt(). Offset is where the list's header starts and Size is -1 for
+// lists with an unknown size.
+type ListContext struct {
+	ID         int
+	Offset     int64
+	HeaderSize int
+	Size       int64
 }
 
 type listInfo struct {
 	id          int
 	size        int64
+	bodyOffset  int64
 	bytesParsed int64
+	crc         hash.Hash32
+	expectedCRC uint32
 }
 
 type Parser struct {
@@ -40,7 +93,16 @@ type Parser struct {
 	client           ParserClient
 	listMap          map[int]bool
 	unknownSizeIdMap map[int]map[int]bool
-	parserError      bool
+	err              error
+	verifyCRC32      bool
+	crcList          *listInfo
+	crcBytes         []byte
+	resyncIDs        map[int]int
+	resyncing        bool
+	resyncStart      int64
+	elementStart     int64
+	headerBytes      int
+	options          ParserOptions
 }
 
 func (li *listInfo) AddBytes(byteCount int64) bool {
@@ -48,11 +110,12 @@ func (li *listInfo) AddBytes(byteCount int64) bool {
 	return li.bytesParsed == li.size
 }
 
-func NewParser(listElements []int, unknownSizeInfo map[int][]int, client ParserClient) *Parser {
+func NewParser(schema *Schema, client ParserClient) *Parser {
 	listMap := map[int]bool{}
-	for i := 0; i < len(listElements); i++ {
-		listMap[listElements[i]] = true
+	for _, id := range schema.listIDs {
+		listMap[id] = true
 	}
+	unknownSizeInfo := schema.unknownSizeInfo
 	unknownSizeIdMap := map[int]map[int]bool{}
 	for id, parentAndPeerIds := range unknownSizeInfo {
 		idMap := map[int]bool{}
@@ -71,28 +134,127 @@ func NewParser(listElements []int, unknownSizeInfo map[int][]int, client ParserC
 		unknownSizeIdMap[id] = idMap
 	}
 
-	return &Parser{buf: bytes.NewBuffer([]byte{}), offset: 0, bytesLeft: 0, client: client, listMap: listMap, unknownSizeIdMap: unknownSizeIdMap, parserError: false}
+	return &Parser{buf: bytes.NewBuffer([]byte{}), offset: 0, bytesLeft: 0, client: client, listMap: listMap, unknownSizeIdMap: unknownSizeIdMap, err: nil}
 }
 
-func (b *Parser) Append(buf []byte) bool {
-	if b.parserError {
-		return false
+// SetVerifyCRC32 controls whether CRC-32 elements that are the first child
+// of a list are checked against the rest of the list's payload. A mismatch
+// is reported as a *SyntaxError wrapping ErrCRC32Mismatch when the list ends.
+func (b *Parser) SetVerifyCRC32(verify bool) {
+	b.verifyCRC32 = verify
+}
+
+// SetResyncIDs enables recovery from invalid IDs, sizes and unknown sizes.
+// Instead of failing, the parser scans forward for the next element with one
+// of the IDs in resyncIDs, which maps each ID to the ID of its parent list
+// or -1 for top level elements. Open lists that can't contain that element
+// are ended and the skipped byte range is reported to clients implementing
+// ResyncClient. Passing nil disables recovery.
+func (b *Parser) SetResyncIDs(resyncIDs map[int]int) {
+	b.resyncIDs = resyncIDs
+}
+
+func (b *Parser) SetOptions(options ParserOptions) {
+	b.options = options
+}
+
+// ResumeAt discards any buffered data and prepares the parser to receive
+// data that starts at offset in the original stream. ancestors holds the
+// lists enclosing offset, outermost first, so element offsets and the
+// OnElementEnd() calls for those lists match a parse of the whole stream.
+func (b *Parser) ResumeAt(offset int64, ancestors []ListContext) error {
+	lists := []*listInfo{}
+	for i, a := range ancestors {
+		bodyOffset := a.Offset + int64(a.HeaderSize)
+		if !b.isList(a.ID) {
+			return fmt.Errorf("ebml: 0x%X is not a list", a.ID)
+		}
+		if offset < bodyOffset || (a.Size != -1 && offset > bodyOffset+a.Size) {
+			return fmt.Errorf("ebml: offset %d is outside of list 0x%X", offset, a.ID)
+		}
+
+		// Lists only count the body of a child list once it ends.
+		end := offset
+		if i+1 < len(ancestors) {
+			end = ancestors[i+1].Offset + int64(ancestors[i+1].HeaderSize)
+			if end < bodyOffset {
+				return fmt.Errorf("ebml: list 0x%X is outside of list 0x%X", ancestors[i+1].ID, a.ID)
+			}
+		}
+		lists = append(lists, &listInfo{id: a.ID, size: a.Size, bodyOffset: bodyOffset, bytesParsed: end - bodyOffset})
+	}
+
+	b.buf.Reset()
+	b.offset = offset
+	b.bytesLeft = 0
+	b.lists = lists
+	b.err = nil
+	b.crcList = nil
+	b.resyncing = false
+	b.elementStart = offset
+
+	if rc, ok := b.client.(ResumeClient); ok {
+		if err := rc.OnResume(offset, ancestors); err != nil {
+			return err
+		}
+	}
+
+	// End any lists that are already complete.
+	return b.consumeBytes(0)
+}
+
+func (b *Parser) Append(buf []byte) error {
+	if b.err != nil {
+		return b.err
 	}
 
 	b.buf.Write(buf)
 
 	for b.buf.Len() > 0 {
+		if b.resyncing {
+			found, err := b.resync()
+			if err != nil {
+				return err
+			}
+			if !found {
+				break
+			}
+		}
+
 		if b.bytesLeft == 0 {
 			totalParsed, id, size := b.readHeader(b.buf.Bytes())
-			if totalParsed <= 0 {
+			if totalParsed == 0 {
 				break
 			}
+			if totalParsed < 0 {
+				if err := b.recover(ErrInvalidVarint, -1); err != nil {
+					return err
+				}
+				continue
+			}
 
 			// Check to see if this ID indicates the end of
 			// a list with an unknown size.
-			if !b.checkForAncestorId(id) {
-				b.parserError = true
-				return false
+			if err := b.checkForAncestorId(id); err != nil {
+				return err
+			}
+
+			if b.options.MaxElementSize > 0 && size > b.options.MaxElementSize {
+				err := fmt.Errorf("%w: %d > %d", ErrElementTooLarge, size, b.options.MaxElementSize)
+				if err := b.recover(err, id); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if len(b.lists) > 0 {
+				li := b.lists[len(b.lists)-1]
+				if li.size != -1 && size != -1 && li.bytesParsed+int64(totalParsed)+size > li.size {
+					if err := b.recover(ErrSizeOverflow, id); err != nil {
+						return err
+					}
+					continue
+				}
 			}
 
 			//log.Printf("%d id %s size %d depth %d\n",
@@ -101,35 +263,48 @@ func (b *Parser) Append(buf []byte) bool {
 
 			if b.isList(id) {
 				if _, ok := b.unknownSizeIdMap[id]; (size == -1) && !ok {
-					b.parserError = true
-					return false
+					if err := b.recover(ErrUnknownSize, id); err != nil {
+						return err
+					}
+					continue
+				}
+
+				if b.options.MaxDepth > 0 && len(b.lists) >= b.options.MaxDepth {
+					return b.fail(fmt.Errorf("%w: limit is %d", ErrTooDeep, b.options.MaxDepth), id)
 				}
 
 				// Consume the header.
-				if !b.consumeHeader(totalParsed, id, size) {
-					b.parserError = true
-					return false
+				if err := b.consumeHeader(totalParsed, id, size); err != nil {
+					return err
 				}
 
-				b.lists = append(b.lists, &listInfo{id: id, size: size, bytesParsed: 0})
+				b.lists = append(b.lists, &listInfo{id: id, size: size, bodyOffset: b.offset, bytesParsed: 0})
 				if size == 0 {
-					if !b.consumeBytes(0) {
-						b.parserError = true
-						return false
+					if err := b.consumeBytes(0); err != nil {
+						return err
 					}
 				}
 				continue
 			}
 
 			if size == -1 {
-				b.parserError = true
-				return false
+				if err := b.recover(ErrUnknownSize, id); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if b.options.MaxBufferedBytes > 0 && size > b.options.MaxBufferedBytes && !b.streamsBody(id) {
+				err := fmt.Errorf("%w: %d byte element, limit is %d", ErrBufferLimit, size, b.options.MaxBufferedBytes)
+				if err := b.recover(err, id); err != nil {
+					return err
+				}
+				continue
 			}
 
 			// Consume the header.
-			if !b.consumeHeader(totalParsed, id, size) {
-				b.parserError = true
-				return false
+			if err := b.consumeHeader(totalParsed, id, size); err != nil {
+				return err
 			}
 			b.bytesLeft = size
 		}
@@ -141,15 +316,22 @@ func (b *Parser) Append(buf []byte) bool {
 
 		// Consume element body.
 		b.bytesLeft -= int64(bytesToConsume)
-		if !b.consumeBody(bytesToConsume) {
-			b.parserError = true
-			return false
+		if err := b.consumeBody(bytesToConsume); err != nil {
+			return err
 		}
 	}
-	return true
+	return nil
 }
 
-func (b *Parser) EndOfData() {
+func (b *Parser) EndOfData() error {
+	if b.err != nil {
+		return b.err
+	}
+
+	if b.resyncIDs != nil {
+		return b.truncate()
+	}
+
 	for len(b.lists) > 0 {
 		li := b.lists[len(b.lists)-1]
 		if li.size != -1 {
@@ -157,13 +339,162 @@ func (b *Parser) EndOfData() {
 		}
 
 		li.size = li.bytesParsed
-		if !b.consumeBytes(0) {
-			return
+		if err := b.consumeBytes(0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recover starts scanning for a resync ID if resynchronization is enabled
+// and fails with err otherwise.
+func (b *Parser) recover(err error, id int) error {
+	if b.resyncIDs == nil {
+		return b.fail(err, id)
+	}
+
+	b.resyncing = true
+	b.resyncStart = b.offset
+	b.skip(1)
+	return nil
+}
+
+func (b *Parser) skip(byteCount int) {
+	b.buf.Next(byteCount)
+	b.offset += int64(byteCount)
+}
+
+// resync looks for the next resync ID in the buffered data. It returns false
+// if more data is needed.
+func (b *Parser) resync() (bool, error) {
+	for b.buf.Len() > 0 {
+		buf := b.buf.Bytes()
+		index, id := b.findResyncID(buf)
+		if index == -1 {
+			// Keep enough bytes to match an ID that spans appends.
+			if len(buf) > 3 {
+				b.skip(len(buf) - 3)
+			}
+			return false, nil
+		}
+		b.skip(index)
+
+		totalParsed, _, size := b.readHeader(b.buf.Bytes())
+		if totalParsed == 0 {
+			return false, nil
+		}
+		if totalParsed < 0 || (size == -1 && b.unknownSizeIdMap[id] == nil) {
+			b.skip(1)
+			continue
+		}
+
+		return true, b.finishResync(id)
+	}
+	return false, nil
+}
+
+func (b *Parser) findResyncID(buf []byte) (int, int) {
+	bestIndex, bestId := -1, 0
+	for id := range b.resyncIDs {
+		idBytes := make([]byte, idLength(id))
+		for i, value := len(idBytes)-1, id; i >= 0; i, value = i-1, value>>8 {
+			idBytes[i] = byte(value)
+		}
+
+		if index := bytes.Index(buf, idBytes); index != -1 && (bestIndex == -1 || index < bestIndex) {
+			bestIndex, bestId = index, id
 		}
 	}
+	return bestIndex, bestId
 }
 
-func (b *Parser) checkForAncestorId(id int) bool {
+func (b *Parser) finishResync(id int) error {
+	b.resyncing = false
+	if err := b.reportResync(b.resyncStart, b.offset-b.resyncStart); err != nil {
+		return err
+	}
+
+	// End lists that can't contain the element found.
+	parentId := b.resyncIDs[id]
+	for len(b.lists) > 0 {
+		li := b.lists[len(b.lists)-1]
+		if li.id == parentId && (li.size == -1 || b.offset <= li.bodyOffset+li.size) {
+			li.bytesParsed = b.offset - li.bodyOffset
+			break
+		}
+
+		if err := b.client.OnElementEnd(b.resyncStart, li.id); err != nil {
+			return b.fail(err, -1)
+		}
+		b.lists = b.lists[:len(b.lists)-1]
+	}
+
+	// The skipped data makes CRC-32 checks of the remaining lists useless.
+	for _, li := range b.lists {
+		li.crc = nil
+	}
+	b.crcList = nil
+	return nil
+}
+
+// truncate ends all open lists, reporting any partial element at the end of
+// the data as skipped.
+func (b *Parser) truncate() error {
+	start := b.offset
+	if b.resyncing {
+		start = b.resyncStart
+	} else if b.bytesLeft > 0 {
+		start = b.elementStart
+	}
+
+	if b.resyncing || b.bytesLeft > 0 || b.buf.Len() > 0 {
+		end := b.offset + int64(b.buf.Len())
+		if err := b.reportResync(start, end-start); err != nil {
+			return err
+		}
+		b.skip(b.buf.Len())
+		b.resyncing = false
+		b.bytesLeft = 0
+	}
+
+	for len(b.lists) > 0 {
+		li := b.lists[len(b.lists)-1]
+		if err := b.client.OnElementEnd(start, li.id); err != nil {
+			return b.fail(err, -1)
+		}
+		b.lists = b.lists[:len(b.lists)-1]
+	}
+	return nil
+}
+
+func (b *Parser) reportResync(offset int64, size int64) error {
+	if rc, ok := b.client.(ResyncClient); ok {
+		if err := rc.OnResync(offset, size); err != nil {
+			return b.fail(err, -1)
+		}
+	}
+	return nil
+}
+
+// fail records err as the parser's final error. Errors that are not
+// already a *SyntaxError are wrapped with the current offset and the path
+// of open lists, followed by id unless it is -1.
+func (b *Parser) fail(err error, id int) error {
+	if _, ok := err.(*SyntaxError); !ok {
+		path := []int{}
+		for _, li := range b.lists {
+			path = append(path, li.id)
+		}
+		if id != -1 {
+			path = append(path, id)
+		}
+		err = &SyntaxError{Offset: b.offset, Path: path, Err: err}
+	}
+	b.err = err
+	return err
+}
+
+func (b *Parser) checkForAncestorId(id int) error {
 	for len(b.lists) > 0 {
 		li := b.lists[len(b.lists)-1]
 		if li.size != -1 {
@@ -176,45 +507,92 @@ func (b *Parser) checkForAncestorId(id int) bool {
 		}
 
 		li.size = li.bytesParsed
-		if !b.consumeBytes(0) {
-			return false
+		if err := b.consumeBytes(0); err != nil {
+			return err
 		}
 	}
-	return true
+	return nil
+}
+
+func (b *Parser) streamsBody(id int) bool {
+	sc, ok := b.client.(StreamingClient)
+	return ok && sc.StreamsBody(id)
 }
 
-func (b *Parser) consumeHeader(headerSize int, id int, size int64) bool {
+func (b *Parser) consumeHeader(headerSize int, id int, size int64) error {
 	b.currentId = id
-	if !b.client.OnHeader(b.offset, b.buf.Next(headerSize), id, size) {
-		return false
+	b.elementStart = b.offset
+	hdr := b.buf.Next(headerSize)
+	if err := b.client.OnHeader(b.offset, hdr, id, size); err != nil {
+		return b.fail(err, id)
+	}
+	b.updateCRCs(hdr)
+
+	if b.verifyCRC32 && id == IdCRC32 && size == 4 && len(b.lists) > 0 {
+		if li := b.lists[len(b.lists)-1]; li.bytesParsed == 0 {
+			b.crcList = li
+			b.crcBytes = []byte{}
+		}
 	}
 
-	return b.consumeBytes(headerSize)
+	if b.isList(id) {
+		return b.consumeBytes(headerSize)
+	}
+
+	// A leaf's header is added to the open lists with its first body bytes so
+	// that an empty leaf ends before a list that it completes.
+	b.offset += int64(headerSize)
+	b.headerBytes = headerSize
+	return nil
 }
 
-func (b *Parser) consumeBody(byteCount int) bool {
-	if byteCount > 0 && !b.client.OnBody(b.offset, b.buf.Next(byteCount)) {
-		return false
+func (b *Parser) consumeBody(byteCount int) error {
+	headerBytes := b.headerBytes
+	b.headerBytes = 0
+
+	if byteCount > 0 {
+		body := b.buf.Next(byteCount)
+		if err := b.client.OnBody(b.offset, body); err != nil {
+			return b.fail(err, b.currentId)
+		}
+		b.updateCRCs(body)
+
+		if b.crcList != nil {
+			b.crcBytes = append(b.crcBytes, body...)
+		}
+	}
+
+	// The CRC-32 covers everything in the list after the CRC-32 element so
+	// only start hashing once the element has been consumed.
+	if b.bytesLeft == 0 && b.crcList != nil {
+		b.crcList.crc = crc32.NewIEEE()
+		b.crcList.expectedCRC = binary.LittleEndian.Uint32(b.crcBytes)
+		b.crcList = nil
 	}
 
 	if b.bytesLeft == 0 {
-		if !b.client.OnElementEnd(b.offset, b.currentId) {
-			return false
+		if err := b.client.OnElementEnd(b.offset, b.currentId); err != nil {
+			return b.fail(err, b.currentId)
 		}
 
 		if len(b.lists) > 0 {
 			b.currentId = b.lists[len(b.lists)-1].id
 		}
 	}
-	return b.consumeBytes(byteCount)
+
+	b.offset += int64(byteCount)
+	return b.addListBytes(int64(headerBytes + byteCount))
 }
 
-func (b *Parser) consumeBytes(byteCount int) bool {
-	if byteCount > 0 {
-		b.offset += int64(byteCount)
-	}
+func (b *Parser) consumeBytes(byteCount int) error {
+	b.offset += int64(byteCount)
+	return b.addListBytes(int64(byteCount))
+}
 
-	listByteCount := int64(byteCount)
+// addListBytes adds byteCount bytes to the innermost open list and ends the
+// lists that are complete.
+func (b *Parser) addListBytes(byteCount int64) error {
+	listByteCount := byteCount
 	for len(b.lists) > 0 {
 		li := b.lists[len(b.lists)-1]
 
@@ -222,8 +600,12 @@ func (b *Parser) consumeBytes(byteCount int) bool {
 			break
 		}
 
-		if !b.client.OnElementEnd(b.offset, li.id) {
-			return false
+		if li.crc != nil && li.crc.Sum32() != li.expectedCRC {
+			return b.fail(fmt.Errorf("%w: expected 0x%08x, computed 0x%08x", ErrCRC32Mismatch, li.expectedCRC, li.crc.Sum32()), -1)
+		}
+
+		if err := b.client.OnElementEnd(b.offset, li.id); err != nil {
+			return b.fail(err, -1)
 		}
 
 		//log.Printf("list end %s\n", idToName[li.id]);
@@ -231,7 +613,15 @@ func (b *Parser) consumeBytes(byteCount int) bool {
 		b.lists = b.lists[:len(b.lists)-1]
 	}
 
-	return true
+	return nil
+}
+
+func (b *Parser) updateCRCs(data []byte) {
+	for _, li := range b.lists {
+		if li.crc != nil {
+			li.crc.Write(data)
+		}
+	}
 }
 
 func (b *Parser) readNumber(buf []byte, isSize bool) (int, int64) {
@@ -291,6 +681,26 @@ func (b *Parser) readNumber(buf []byte, isSize bool) (int, int64) {
 	return totalBytes, value
 }
 
+// ReadElementHeader reads the ID and size of the element at offset in r.
+// The size is -1 if the element has an unknown size.
+func ReadElementHeader(r io.ReaderAt, offset int64) (id int, headerSize int, size int64, err error) {
+	buf := [12]byte{}
+	n, err := r.ReadAt(buf[:], offset)
+	if n == 0 && err != nil {
+		return 0, 0, 0, err
+	}
+
+	p := &Parser{}
+	headerSize, id, size = p.readHeader(buf[:n])
+	if headerSize == 0 {
+		return 0, 0, 0, io.ErrUnexpectedEOF
+	}
+	if headerSize < 0 {
+		return 0, 0, 0, &SyntaxError{Offset: offset, Path: []int{}, Err: ErrInvalidVarint}
+	}
+	return id, headerSize, size, nil
+}
+
 func (b *Parser) isList(id int) bool {
 	return b.listMap[id]
 }
@@ -0,0 +1,229 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const (
+	testIdSegment = 0x18538067
+	testIdCluster = 0x1F43B675
+	testIdGroup   = 0xA0
+	testIdBlock   = 0xA1
+	testIdUint    = 0xE7
+	testIdInt     = 0xFB
+	testIdFloat   = 0xB5
+	testIdString  = 0x86
+	testIdDate    = 0x4461
+)
+
+var fuzzOptions = ParserOptions{MaxElementSize: 1 << 20, MaxDepth: 4, MaxBufferedBytes: 4096}
+
+func testSchema(t testing.TB) *Schema {
+	s, err := HeaderSchema().Extend(
+		ElementDef{ID: testIdSegment, Name: "Segment", Type: TypeList, Parent: -1, UnknownSizeAllowed: true},
+		ElementDef{ID: testIdCluster, Name: "Cluster", Type: TypeList, Parent: testIdSegment, UnknownSizeAllowed: true},
+		ElementDef{ID: testIdGroup, Name: "Group", Type: TypeList, Parent: testIdCluster, Recursive: true},
+		ElementDef{ID: testIdBlock, Name: "Block", Type: TypeBinary, Parent: testIdGroup},
+		ElementDef{ID: testIdUint, Name: "Uint", Type: TypeUint, Parent: testIdCluster},
+		ElementDef{ID: testIdInt, Name: "Int", Type: TypeInt, Parent: testIdCluster},
+		ElementDef{ID: testIdFloat, Name: "Float", Type: TypeFloat, Parent: testIdCluster},
+		ElementDef{ID: testIdString, Name: "String", Type: TypeString, Parent: testIdCluster},
+		ElementDef{ID: testIdDate, Name: "Date", Type: TypeDate, Parent: testIdCluster},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// limitClient checks the parser's limits from inside every callback.
+type limitClient struct {
+	t        *testing.T
+	parser   *Parser
+	options  ParserOptions
+	bodySize int64
+}
+
+func (c *limitClient) check() {
+	if c.options.MaxDepth > 0 && len(c.parser.lists) > c.options.MaxDepth {
+		c.t.Fatalf("%d open lists, limit is %d", len(c.parser.lists), c.options.MaxDepth)
+	}
+	if c.options.MaxBufferedBytes > 0 && c.bodySize > c.options.MaxBufferedBytes {
+		c.t.Fatalf("%d body bytes buffered, limit is %d", c.bodySize, c.options.MaxBufferedBytes)
+	}
+}
+
+func (c *limitClient) OnHeader(offset int64, hdr []byte, id int, size int64) error {
+	if c.options.MaxElementSize > 0 && size > c.options.MaxElementSize {
+		c.t.Fatalf("element 0x%X with size %d accepted, limit is %d", id, size, c.options.MaxElementSize)
+	}
+	c.bodySize = 0
+	c.check()
+	return nil
+}
+
+func (c *limitClient) OnBody(offset int64, body []byte) error {
+	c.bodySize += int64(len(body))
+	c.check()
+	return nil
+}
+
+func (c *limitClient) OnElementEnd(offset int64, id int) error {
+	c.check()
+	return nil
+}
+
+func FuzzParser(f *testing.F) {
+	// A Block that declares 2^56-2 bytes, the largest known size.
+	f.Add([]byte{0x18, 0x53, 0x80, 0x67, 0xFF, 0x1F, 0x43, 0xB6, 0x75, 0xFF, 0xA0, 0xFF,
+		0xA1, 0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFE, 0x00}, uint8(7))
+	f.Add([]byte{0x1A, 0x45, 0xDF, 0xA3, 0x84, 0x42, 0x86, 0x81, 0x01}, uint8(1))
+	// Deeply nested unknown sized Groups.
+	f.Add([]byte{0x18, 0x53, 0x80, 0x67, 0xFF, 0x1F, 0x43, 0xB6, 0x75, 0xFF,
+		0xA0, 0x88, 0xA0, 0x86, 0xA0, 0x84, 0xA0, 0x82, 0xA0, 0x80}, uint8(3))
+	// Data that arrives without ever completing an element header.
+	f.Add([]byte{0x18, 0x53, 0x80, 0x67, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, uint8(255))
+
+	f.Fuzz(func(t *testing.T, data []byte, chunkSize uint8) {
+		schema := testSchema(t)
+		for _, resync := range []bool{false, true} {
+			c := &limitClient{t: t, options: fuzzOptions}
+			p := NewParser(schema, c)
+			p.SetOptions(fuzzOptions)
+			p.SetVerifyCRC32(true)
+			if resync {
+				p.SetResyncIDs(map[int]int{testIdCluster: testIdSegment})
+			}
+			c.parser = p
+
+			size := int(chunkSize) + 1
+			for i := 0; i < len(data); i += size {
+				end := i + size
+				if end > len(data) {
+					end = len(data)
+				}
+				if err := p.Append(data[i:end]); err != nil {
+					break
+				}
+				if n := int64(p.buf.Len()); n > fuzzOptions.MaxBufferedBytes {
+					t.Fatalf("%d bytes of unparsed input buffered, limit is %d", n, fuzzOptions.MaxBufferedBytes)
+				}
+			}
+			p.EndOfData()
+		}
+	})
+}
+
+// resyncRecorder records element starts, ends and resyncs.
+type resyncRecorder struct {
+	events []string
+}
+
+func (r *resyncRecorder) OnHeader(offset int64, hdr []byte, id int, size int64) error {
+	r.events = append(r.events, fmt.Sprintf("0x%X at %d", id, offset))
+	return nil
+}
+
+func (r *resyncRecorder) OnBody(offset int64, body []byte) error { return nil }
+
+func (r *resyncRecorder) OnElementEnd(offset int64, id int) error {
+	r.events = append(r.events, fmt.Sprintf("end 0x%X", id))
+	return nil
+}
+
+func (r *resyncRecorder) OnResync(offset int64, size int64) error {
+	r.events = append(r.events, fmt.Sprintf("resync %d+%d", offset, size))
+	return nil
+}
+
+func TestParserResync(t *testing.T) {
+	// An unknown sized Segment at 0 holding a Cluster at 12.
+	const start = "18538067 01FFFFFFFFFFFFFF 1F43B675 83 E7 81 05"
+	startEvents := []string{"0x18538067 at 0", "0x1F43B675 at 12", "0xE7 at 17", "end 0xE7", "end 0x1F43B675"}
+
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			"invalid ID between Clusters",
+			start + " 00 00 1F43B675 83 E7 81 06",
+			append(startEvents, "resync 20+2", "0x1F43B675 at 22", "0xE7 at 27", "end 0xE7", "end 0x1F43B675", "end 0x18538067"),
+		},
+		{
+			"garbage that contains part of a Cluster ID",
+			start + " 00 1F43 B6 1F43B675 83 E7 81 06",
+			append(startEvents, "resync 20+4", "0x1F43B675 at 24", "0xE7 at 29", "end 0xE7", "end 0x1F43B675", "end 0x18538067"),
+		},
+		{
+			"element larger than its Cluster",
+			"18538067 01FFFFFFFFFFFFFF 1F43B675 83 E7 85 05 1F43B675 83 E7 81 06",
+			[]string{"0x18538067 at 0", "0x1F43B675 at 12", "resync 17+3", "end 0x1F43B675",
+				"0x1F43B675 at 20", "0xE7 at 25", "end 0xE7", "end 0x1F43B675", "end 0x18538067"},
+		},
+		{
+			"unknown sized Uint",
+			"18538067 01FFFFFFFFFFFFFF 1F43B675 FF E7 FF 05 1F43B675 83 E7 81 06",
+			[]string{"0x18538067 at 0", "0x1F43B675 at 12", "resync 17+3", "end 0x1F43B675",
+				"0x1F43B675 at 20", "0xE7 at 25", "end 0xE7", "end 0x1F43B675", "end 0x18538067"},
+		},
+		{
+			"truncated element",
+			start + " 1F43B675 83 E7 81",
+			append(startEvents, "0x1F43B675 at 20", "0xE7 at 25", "resync 25+2", "end 0x1F43B675", "end 0x18538067"),
+		},
+		{
+			"garbage at the end",
+			start + " 00 01 02",
+			append(startEvents, "resync 20+3", "end 0x18538067"),
+		},
+	}
+
+	schema := testSchema(t)
+	for _, test := range tests {
+		data := fromHex(t, test.data)
+		for _, chunkSize := range []int{len(data), 1, 3} {
+			r := &resyncRecorder{}
+			p := NewParser(schema, r)
+			p.SetResyncIDs(map[int]int{testIdCluster: testIdSegment})
+			for i := 0; i < len(data); i += chunkSize {
+				end := i + chunkSize
+				if end > len(data) {
+					end = len(data)
+				}
+				if err := p.Append(data[i:end]); err != nil {
+					t.Fatalf("%s: %v", test.name, err)
+				}
+			}
+			if err := p.EndOfData(); err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+			if !reflect.DeepEqual(r.events, test.want) {
+				t.Errorf("%s in %d byte chunks: got %q, want %q", test.name, chunkSize, r.events, test.want)
+			}
+		}
+	}
+
+	// Without resync IDs the same data fails.
+	p := NewParser(schema, &resyncRecorder{})
+	if err := p.Append(fromHex(t, tests[0].data)); err == nil {
+		t.Error("parsing an invalid ID without resync IDs succeeded")
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type queryPredicate struct {
+	name  string
+	value string
+}
+
+type queryStep struct {
+	name       string
+	index      int
+	predicates []queryPredicate
+}
+
+// parseQuery splits a path like "Segment/Tracks/TrackEntry[TrackType=1]"
+// into steps. A step is an element name or "*" followed by any number of
+// [Name=value] predicates, which require a child with that value, and an
+// optional [n] that selects the n-th match, starting at 1.
+func parseQuery(path string) ([]queryStep, error) {
+	invalid := fmt.Errorf("ebml: invalid path %q", path)
+
+	steps := []queryStep{}
+	for _, part := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		name := part
+		predicates := ""
+		if i := strings.Index(part, "["); i != -1 {
+			name, predicates = part[:i], part[i:]
+		}
+		if name == "" {
+			return nil, invalid
+		}
+
+		step := queryStep{name: name, predicates: []queryPredicate{}}
+		for predicates != "" {
+			end := strings.Index(predicates, "]")
+			if predicates[0] != '[' || end == -1 {
+				return nil, invalid
+			}
+			predicate := predicates[1:end]
+			predicates = predicates[end+1:]
+
+			if index, err := strconv.Atoi(predicate); err == nil {
+				if index < 1 || step.index != 0 {
+					return nil, invalid
+				}
+				step.index = index
+				continue
+			}
+
+			i := strings.Index(predicate, "=")
+			if i < 1 {
+				return nil, invalid
+			}
+			value := predicate[i+1:]
+			if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+				value = value[1 : len(value)-1]
+			}
+			step.predicates = append(step.predicates, queryPredicate{name: predicate[:i], value: value})
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func valueEquals(value interface{}, s string) bool {
+	switch v := value.(type) {
+	case uint64:
+		u, err := strconv.ParseUint(s, 0, 64)
+		return err == nil && u == v
+	case int64:
+		i, err := strconv.ParseInt(s, 0, 64)
+		return err == nil && i == v
+	case float64:
+		f, err := strconv.ParseFloat(s, 64)
+		return err == nil && f == v
+	case string:
+		return v == s
+	case time.Time:
+		t, err := time.Parse(time.RFC3339Nano, s)
+		return err == nil && t.Equal(v)
+	case []byte:
+		if strings.HasPrefix(s, "0x") {
+			b, err := hex.DecodeString(s[2:])
+			return err == nil && bytes.Equal(b, v)
+		}
+		return string(v) == s
+	}
+	return false
+}
+
+func (s *queryStep) matches(n *Node, schema *Schema) bool {
+	if s.name != "*" && schema.Name(n.ID) != s.name {
+		return false
+	}
+
+	for _, p := range s.predicates {
+		found := false
+		for _, child := range n.Children {
+			if schema.Name(child.ID) == p.name && valueEquals(child.Value, p.value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// FindAllNodes returns the nodes that match path, starting from the nodes
+// passed in as the top level. Element names are resolved with schema.
+func FindAllNodes(nodes []*Node, path string, schema *Schema) ([]*Node, error) {
+	steps, err := parseQuery(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := []*Node{NewListNode(-1, nodes...)}
+	for i := range steps {
+		step := &steps[i]
+		next := []*Node{}
+		for _, parent := range current {
+			matches := []*Node{}
+			for _, child := range parent.Children {
+				if step.matches(child, schema) {
+					matches = append(matches, child)
+				}
+			}
+
+			if step.index > 0 {
+				if step.index > len(matches) {
+					continue
+				}
+				matches = matches[step.index-1 : step.index]
+			}
+			next = append(next, matches...)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// FindAll parses data and returns the elements that match path. See
+// FindAllNodes(). Only the elements that path can reach, their predicate
+// children and the subtrees of the final matches are kept in memory, but
+// the whole of data is still decoded.
+func FindAll(data []byte, path string, schema *Schema) ([]*Node, error) {
+	steps, err := parseQuery(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Catch misspelled names since they would silently match nothing.
+	for _, step := range steps {
+		if _, present := schema.ID(step.name); !present && step.name != "*" {
+			return nil, fmt.Errorf("ebml: unknown element %s in path %q", step.name, path)
+		}
+		for _, p := range step.predicates {
+			if _, present := schema.ID(p.name); !present {
+				return nil, fmt.Errorf("ebml: unknown element %s in path %q", p.name, path)
+			}
+		}
+	}
+
+	builder := NewNodeBuilder()
+	builder.Skip = func(e Element, depth int) bool {
+		return !stepsReach(steps, e, depth, schema)
+	}
+	nodes, err := ReadNodes(bytes.NewReader(data), schema, builder)
+	if err != nil {
+		return nil, err
+	}
+	return FindAllNodes(nodes, path, schema)
+}
+
+// stepsReach returns whether the element e at depth can affect the result of
+// the query. Elements deeper than the last step are inside a final match
+// since the builder only descends into elements that match their step.
+func stepsReach(steps []queryStep, e Element, depth int, schema *Schema) bool {
+	if depth >= len(steps) {
+		return true
+	}
+	if step := steps[depth]; step.name == "*" || step.name == schema.Name(e.ID) {
+		return true
+	}
+	if depth > 0 && e.Kind == ElementLeaf {
+		for _, p := range steps[depth-1].predicates {
+			if p.name == schema.Name(e.ID) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Find returns the first element in data that matches path or nil if there
+// isn't one. See FindAllNodes().
+func Find(data []byte, path string, schema *Schema) (*Node, error) {
+	nodes, err := FindAll(data, path, schema)
+	if err != nil || len(nodes) == 0 {
+		return nil, err
+	}
+	return nodes[0], nil
+}
@@ -0,0 +1,190 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// queryTestNodes returns a Segment with two Clusters. The first holds one
+// element of every leaf type and two Groups, the second a Uint, a String
+// and one Group.
+func queryTestNodes() []*Node {
+	return []*Node{
+		NewListNode(testIdSegment,
+			NewListNode(testIdCluster,
+				NewNode(testIdUint, TypeUint, uint64(5)),
+				NewNode(testIdInt, TypeInt, int64(-3)),
+				NewNode(testIdFloat, TypeFloat, 1.5),
+				NewNode(testIdString, TypeString, "hi"),
+				NewNode(testIdDate, TypeDate, DateEpoch.Add(time.Hour)),
+				NewListNode(testIdGroup, NewNode(testIdBlock, TypeBinary, []byte{1, 2})),
+				NewListNode(testIdGroup, NewNode(testIdBlock, TypeBinary, []byte("ab"))),
+			),
+			NewListNode(testIdCluster,
+				NewNode(testIdUint, TypeUint, uint64(7)),
+				NewNode(testIdString, TypeString, "there"),
+				NewListNode(testIdGroup, NewNode(testIdBlock, TypeBinary, []byte{3})),
+			),
+		),
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, path := range []string{
+		"",
+		"Segment//Cluster",
+		"[Uint=5]",
+		"Cluster[",
+		"Cluster[Uint=5",
+		"Cluster[0]",
+		"Cluster[-1]",
+		"Cluster[1][2]",
+		"Cluster[=5]",
+		"Cluster[Uint]",
+		"Cluster[Uint=5]x",
+	} {
+		if _, err := parseQuery(path); err == nil {
+			t.Errorf("parseQuery(%q) succeeded", path)
+		}
+		if _, err := FindAllNodes(queryTestNodes(), path, testSchema(t)); err == nil {
+			t.Errorf("FindAllNodes(%q) succeeded", path)
+		}
+	}
+}
+
+// findTests holds paths into queryTestNodes() and the values of the nodes
+// they match.
+var findTests = []struct {
+	path string
+	want []interface{}
+}{
+	{"Segment/Cluster/Uint", []interface{}{uint64(5), uint64(7)}},
+	{"/Segment/*/String", []interface{}{"hi", "there"}},
+	{"Segment/Cluster/Group/Block", []interface{}{[]byte{1, 2}, []byte("ab"), []byte{3}}},
+
+	// Predicates for every value type.
+	{"Segment/Cluster[Uint=7]/String", []interface{}{"there"}},
+	{"Segment/Cluster[Uint=0x5]/String", []interface{}{"hi"}},
+	{"Segment/Cluster[Int=-3]/String", []interface{}{"hi"}},
+	{"Segment/Cluster[Float=1.5]/String", []interface{}{"hi"}},
+	{"Segment/Cluster[String=there]/Uint", []interface{}{uint64(7)}},
+	{"Segment/Cluster[String='there']/Uint", []interface{}{uint64(7)}},
+	{`Segment/Cluster[String="hi"]/Uint`, []interface{}{uint64(5)}},
+	{"Segment/Cluster[Date=2001-01-01T01:00:00Z]/Uint", []interface{}{uint64(5)}},
+	{"Segment/Cluster[Date=2001-01-01T02:00:00+01:00]/Uint", []interface{}{uint64(5)}},
+	{"Segment/Cluster/Group[Block=0x0102]/Block", []interface{}{[]byte{1, 2}}},
+	{"Segment/Cluster/Group[Block=ab]/Block", []interface{}{[]byte("ab")}},
+	{"Segment/Cluster[Uint=5][String=hi]/Int", []interface{}{int64(-3)}},
+
+	// Indexes count the matches inside each parent.
+	{"Segment/Cluster[2]/Uint", []interface{}{uint64(7)}},
+	{"Segment/Cluster/Group[2]/Block", []interface{}{[]byte("ab")}},
+	{"Segment/Cluster/Group[1]/Block", []interface{}{[]byte{1, 2}, []byte{3}}},
+	{"Segment/Cluster[Uint=7][1]/String", []interface{}{"there"}},
+
+	// Paths that don't match anything.
+	{"Segment/Cluster[3]/Uint", []interface{}{}},
+	{"Segment/Cluster/Block", []interface{}{}},
+	{"Cluster/Uint", []interface{}{}},
+	{"Segment/Cluster[Uint=6]/String", []interface{}{}},
+	{"Segment/Cluster[Uint=abc]/String", []interface{}{}},
+	{"Segment/Cluster[Int=x]/String", []interface{}{}},
+	{"Segment/Cluster[Float=1.25]/String", []interface{}{}},
+	{"Segment/Cluster[Date=2001-01-01T00:00:00Z]/String", []interface{}{}},
+	{"Segment/Cluster[Date=yesterday]/String", []interface{}{}},
+	{"Segment/Cluster/Group[Block=0x01]/Block", []interface{}{}},
+	{"Segment/Cluster/Group[Block=0xZZ]/Block", []interface{}{}},
+	{"Segment/Cluster[Group=0x]/Uint", []interface{}{}},
+}
+
+func TestFindAllNodes(t *testing.T) {
+	schema := testSchema(t)
+	for _, test := range findTests {
+		nodes, err := FindAllNodes(queryTestNodes(), test.path, schema)
+		if err != nil {
+			t.Errorf("%s: %v", test.path, err)
+			continue
+		}
+		got := []interface{}{}
+		for _, n := range nodes {
+			got = append(got, n.Value)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.path, got, test.want)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	schema := testSchema(t)
+	data, err := EncodeNodes(queryTestNodes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := Find(data, "Segment/Cluster[String=there]/Group/Block", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n == nil || !reflect.DeepEqual(n.Value, []byte{3}) {
+		t.Errorf("got %+v, want the Block in the second Cluster", n)
+	}
+
+	clusters, err := FindAll(data, "Segment/Cluster", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 2 || clusters[1].Offset <= clusters[0].Offset {
+		t.Errorf("got %d Clusters, want 2 in order", len(clusters))
+	}
+
+	// Parsing only the elements a path reaches finds the same nodes.
+	for _, test := range findTests {
+		nodes, err := FindAll(data, test.path, schema)
+		if err != nil {
+			t.Errorf("%s: %v", test.path, err)
+			continue
+		}
+		got := []interface{}{}
+		for _, n := range nodes {
+			got = append(got, n.Value)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("FindAll(%q) got %v, want %v", test.path, got, test.want)
+		}
+	}
+
+	cluster, err := Find(data, "Segment/Cluster[String=there]", schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cluster == nil || len(cluster.Children) != 3 || cluster.Children[2].Lookup(testIdBlock) == nil {
+		t.Errorf("got %+v, want the whole second Cluster", cluster)
+	}
+
+	if n, err := Find(data, "Segment/Cluster[Uint=8]", schema); n != nil || err != nil {
+		t.Errorf("got %+v, %v for a path without a match", n, err)
+	}
+
+	// Unknown names are reported instead of silently matching nothing.
+	for _, path := range []string{"Segment/Clustr", "Segment/Cluster[Unit=5]"} {
+		if _, err := FindAll(data, path, schema); err == nil {
+			t.Errorf("FindAll(%q) succeeded", path)
+		}
+	}
+}
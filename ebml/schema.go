@@ -0,0 +1,272 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import "fmt"
+
+// ElementDef describes an element for a Schema. Parent is the ID of the
+// list the element occurs in or -1 for top level elements. Global elements,
+// like Void, can occur in any list. Name may be empty for elements that are
+// only identified by ID.
+type ElementDef struct {
+	ID                 int
+	Name               string
+	Type               int
+	Parent             int
+	UnknownSizeAllowed bool
+	Recursive          bool
+	Global             bool
+}
+
+// Schema is a set of element definitions. A Schema can't be changed after
+// it is created so it is safe to share between goroutines.
+type Schema struct {
+	defs            map[int]ElementDef
+	order           []int
+	ids             map[string]int
+	levels          map[int]int
+	typeMap         map[int]int
+	listIDs         []int
+	unknownSizeInfo map[int][]int
+}
+
+// NewSchema returns a Schema containing defs.
+func NewSchema(defs ...ElementDef) (*Schema, error) {
+	return (&Schema{}).Extend(defs...)
+}
+
+// Extend returns a new Schema with defs added to the ones in s. A def with
+// the same ID as an element in s replaces it.
+func (s *Schema) Extend(defs ...ElementDef) (*Schema, error) {
+	all := map[int]ElementDef{}
+	order := []int{}
+	for _, id := range s.order {
+		all[id] = s.defs[id]
+		order = append(order, id)
+	}
+
+	added := map[int]bool{}
+	for _, def := range defs {
+		if def.ID <= 0 {
+			return nil, fmt.Errorf("ebml: invalid id %d for %s", def.ID, def.Name)
+		}
+		if def.Type < TypeList || def.Type > TypeDate {
+			return nil, fmt.Errorf("ebml: invalid type %d for %s", def.Type, def.Name)
+		}
+		if def.UnknownSizeAllowed && def.Type != TypeList {
+			return nil, fmt.Errorf("ebml: unknown size allowed for non-list %s", def.Name)
+		}
+		if added[def.ID] {
+			return nil, fmt.Errorf("ebml: duplicate definition for 0x%X", def.ID)
+		}
+		added[def.ID] = true
+
+		if _, present := all[def.ID]; !present {
+			order = append(order, def.ID)
+		}
+		all[def.ID] = def
+	}
+	return newSchema(all, order)
+}
+
+func newSchema(defs map[int]ElementDef, order []int) (*Schema, error) {
+	s := &Schema{
+		defs:            defs,
+		order:           order,
+		ids:             map[string]int{},
+		levels:          map[int]int{},
+		typeMap:         map[int]int{},
+		listIDs:         []int{},
+		unknownSizeInfo: map[int][]int{},
+	}
+
+	for _, id := range order {
+		def := defs[id]
+		if def.Name != "" {
+			if otherID, present := s.ids[def.Name]; present {
+				return nil, fmt.Errorf("ebml: elements 0x%X and 0x%X are both named %s", otherID, id, def.Name)
+			}
+			s.ids[def.Name] = id
+		}
+		if def.Parent != -1 {
+			if _, present := defs[def.Parent]; !present {
+				return nil, fmt.Errorf("ebml: unknown parent 0x%X for %s", def.Parent, s.Name(id))
+			}
+		}
+
+		s.typeMap[id] = def.Type
+		if def.Type == TypeList {
+			s.listIDs = append(s.listIDs, id)
+		}
+	}
+
+	for _, id := range order {
+		level := 0
+		for parent := defs[id].Parent; parent != -1; parent = defs[parent].Parent {
+			level++
+			if level > len(defs) {
+				return nil, fmt.Errorf("ebml: %s is its own ancestor", s.Name(id))
+			}
+		}
+		s.levels[id] = level
+	}
+
+	for _, id := range order {
+		if defs[id].UnknownSizeAllowed {
+			s.unknownSizeInfo[id] = s.unknownSizeEnds(id)
+		}
+	}
+	return s, nil
+}
+
+// unknownSizeEnds returns the elements that end an unknown sized element:
+// its ancestors, the children of each ancestor and the top level elements.
+func (s *Schema) unknownSizeEnds(id int) []int {
+	ids := []int{}
+	seen := map[int]bool{}
+	add := func(id int) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for parent := s.defs[id].Parent; parent != -1; parent = s.defs[parent].Parent {
+		add(parent)
+		for _, child := range s.order {
+			if def := s.defs[child]; def.Parent == parent && !def.Global {
+				add(child)
+			}
+		}
+	}
+
+	for _, child := range s.order {
+		if def := s.defs[child]; def.Parent == -1 && !def.Global {
+			add(child)
+		}
+	}
+	return ids
+}
+
+// WithTypes returns a copy of s where the elements in types have the given
+// type and every other element is treated as binary. Parsers don't look
+// inside lists that are treated as binary, so this is a cheap way to only
+// parse the parts of a file that are needed. The unknown size rules of s are
+// kept.
+func (s *Schema) WithTypes(types map[int]int) *Schema {
+	defs := map[int]ElementDef{}
+	order := []int{}
+	for _, id := range s.order {
+		def := s.defs[id]
+		def.Type = TypeBinary
+		defs[id] = def
+		order = append(order, id)
+	}
+	for id, elementType := range types {
+		def, present := defs[id]
+		if !present {
+			def = ElementDef{ID: id, Parent: -1}
+			order = append(order, id)
+		}
+		def.Type = elementType
+		defs[id] = def
+	}
+
+	result := &Schema{
+		defs:            defs,
+		order:           order,
+		ids:             s.ids,
+		levels:          map[int]int{},
+		typeMap:         map[int]int{},
+		listIDs:         []int{},
+		unknownSizeInfo: s.unknownSizeInfo,
+	}
+	for _, id := range order {
+		result.levels[id] = s.levels[id]
+		result.typeMap[id] = defs[id].Type
+		if defs[id].Type == TypeList {
+			result.listIDs = append(result.listIDs, id)
+		}
+	}
+	return result
+}
+
+// Definition returns the definition of the element with the given ID.
+func (s *Schema) Definition(id int) (ElementDef, bool) {
+	def, present := s.defs[id]
+	return def, present
+}
+
+// Definitions returns all definitions in the order they were added.
+func (s *Schema) Definitions() []ElementDef {
+	defs := []ElementDef{}
+	for _, id := range s.order {
+		defs = append(defs, s.defs[id])
+	}
+	return defs
+}
+
+// Name returns the name of the element or a placeholder containing the ID
+// for unknown and unnamed elements.
+func (s *Schema) Name(id int) string {
+	if def, present := s.defs[id]; present && def.Name != "" {
+		return def.Name
+	}
+	return fmt.Sprintf("UnknownID(0x%x)", id)
+}
+
+// ID returns the ID of the element called name.
+func (s *Schema) ID(name string) (int, bool) {
+	id, present := s.ids[name]
+	return id, present
+}
+
+// Type returns the type of the element with the given ID.
+func (s *Schema) Type(id int) (int, bool) {
+	elementType, present := s.typeMap[id]
+	return elementType, present
+}
+
+// Level returns the depth of the element below the top level or -1 for
+// unknown elements.
+func (s *Schema) Level(id int) int {
+	if level, present := s.levels[id]; present {
+		return level
+	}
+	return -1
+}
+
+// TypeMap returns a map from element ID to type.
+func (s *Schema) TypeMap() map[int]int {
+	typeMap := map[int]int{}
+	for id, elementType := range s.typeMap {
+		typeMap[id] = elementType
+	}
+	return typeMap
+}
+
+func (s *Schema) ListIDs() []int {
+	return append([]int{}, s.listIDs...)
+}
+
+// UnknownSizeInfo returns, for each element that may have an unknown size,
+// the IDs of the elements that end it.
+func (s *Schema) UnknownSizeInfo() map[int][]int {
+	unknownSizeInfo := map[int][]int{}
+	for id, ids := range s.unknownSizeInfo {
+		unknownSizeInfo[id] = append([]int{}, ids...)
+	}
+	return unknownSizeInfo
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+const testIdPrivate = 0xC0
+
+func TestSchemaExtend(t *testing.T) {
+	base := testSchema(t)
+	baseDefs := base.Definitions()
+
+	ext, err := base.Extend(
+		ElementDef{ID: testIdPrivate, Name: "Private", Type: TypeString, Parent: testIdGroup},
+		ElementDef{ID: testIdUint, Name: "Uint", Type: TypeInt, Parent: testIdCluster},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id, present := ext.ID("Private"); !present || id != testIdPrivate {
+		t.Errorf("got ID %d, %v for Private", id, present)
+	}
+	if ext.Name(testIdPrivate) != "Private" || ext.Level(testIdPrivate) != 3 {
+		t.Errorf("got name %s and level %d", ext.Name(testIdPrivate), ext.Level(testIdPrivate))
+	}
+	if elementType, _ := ext.Type(testIdUint); elementType != TypeInt {
+		t.Errorf("got type %d for the replaced Uint, want %d", elementType, TypeInt)
+	}
+	if defs := ext.Definitions(); len(defs) != len(baseDefs)+1 || defs[len(defs)-1].ID != testIdPrivate {
+		t.Errorf("got %d definitions, want the %d old ones followed by Private", len(defs), len(baseDefs))
+	}
+
+	// The original schema is unchanged.
+	if !reflect.DeepEqual(base.Definitions(), baseDefs) {
+		t.Error("Extend() changed the definitions of the original schema")
+	}
+	if _, present := base.ID("Private"); present {
+		t.Error("the original schema has a Private element")
+	}
+	if _, present := base.Definition(testIdPrivate); present || base.Level(testIdPrivate) != -1 {
+		t.Error("the original schema has a definition for Private")
+	}
+	if elementType, _ := base.Type(testIdUint); elementType != TypeUint {
+		t.Errorf("got type %d for Uint in the original schema, want %d", elementType, TypeUint)
+	}
+}
+
+func TestSchemaExtendErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		defs []ElementDef
+	}{
+		{"invalid id", []ElementDef{{ID: 0, Name: "Zero", Type: TypeUint, Parent: -1}}},
+		{"invalid type", []ElementDef{{ID: testIdPrivate, Name: "Private", Type: TypeDate + 1, Parent: -1}}},
+		{"unknown size leaf", []ElementDef{{ID: testIdPrivate, Name: "Private", Type: TypeUint, Parent: -1, UnknownSizeAllowed: true}}},
+		{"unknown parent", []ElementDef{{ID: testIdPrivate, Name: "Private", Type: TypeUint, Parent: 0xC1}}},
+		{"duplicate id", []ElementDef{
+			{ID: testIdPrivate, Name: "Private", Type: TypeUint, Parent: -1},
+			{ID: testIdPrivate, Name: "Private2", Type: TypeUint, Parent: -1},
+		}},
+		{"duplicate name", []ElementDef{{ID: testIdPrivate, Name: "Cluster", Type: TypeList, Parent: -1}}},
+		{"cycle", []ElementDef{
+			{ID: testIdPrivate, Name: "Private", Type: TypeList, Parent: 0xC1},
+			{ID: 0xC1, Name: "Private2", Type: TypeList, Parent: testIdPrivate},
+		}},
+	}
+
+	base := testSchema(t)
+	for _, test := range tests {
+		if _, err := base.Extend(test.defs...); err == nil {
+			t.Errorf("%s: Extend() succeeded", test.name)
+		}
+	}
+}
+
+func TestSchemaWithTypes(t *testing.T) {
+	base := testSchema(t)
+	baseTypes := base.TypeMap()
+
+	wt := base.WithTypes(map[int]int{testIdCluster: TypeList, testIdUint: TypeUint, testIdPrivate: TypeString})
+	for id, want := range map[int]int{
+		testIdSegment: TypeBinary,
+		testIdCluster: TypeList,
+		testIdUint:    TypeUint,
+		testIdString:  TypeBinary,
+		testIdPrivate: TypeString,
+	} {
+		if got, present := wt.Type(id); !present || got != want {
+			t.Errorf("got type %d, %v for %s, want %d", got, present, base.Name(id), want)
+		}
+	}
+	if !reflect.DeepEqual(wt.ListIDs(), []int{testIdCluster}) {
+		t.Errorf("got list IDs %v", wt.ListIDs())
+	}
+	if wt.Name(testIdCluster) != "Cluster" || wt.Level(testIdUint) != 2 {
+		t.Error("WithTypes() lost the names or levels")
+	}
+	if !reflect.DeepEqual(wt.UnknownSizeInfo(), base.UnknownSizeInfo()) {
+		t.Error("WithTypes() changed the unknown size rules")
+	}
+
+	if !reflect.DeepEqual(base.TypeMap(), baseTypes) {
+		t.Error("WithTypes() changed the types of the original schema")
+	}
+}
+
+func TestSchemaAccessorsReturnCopies(t *testing.T) {
+	s := testSchema(t)
+
+	s.TypeMap()[testIdUint] = TypeString
+	if elementType, _ := s.Type(testIdUint); elementType != TypeUint {
+		t.Error("changing the TypeMap() result changed the schema")
+	}
+
+	s.ListIDs()[0] = testIdUint
+	if s.ListIDs()[0] == testIdUint {
+		t.Error("changing the ListIDs() result changed the schema")
+	}
+
+	info := s.UnknownSizeInfo()
+	info[testIdCluster][0] = testIdPrivate
+	info[testIdUint] = []int{}
+	if got := s.UnknownSizeInfo(); got[testIdCluster][0] == testIdPrivate || got[testIdUint] != nil {
+		t.Error("changing the UnknownSizeInfo() result changed the schema")
+	}
+
+	s.Definitions()[0].Name = "Changed"
+	if s.Definitions()[0].Name == "Changed" {
+		t.Error("changing the Definitions() result changed the schema")
+	}
+
+	IdTypes()[IdVoid] = TypeString
+	if elementType, _ := HeaderSchema().Type(IdVoid); elementType != TypeBinary {
+		t.Error("changing the IdTypes() result changed the header schema")
+	}
+}
+
+// TestSchemaConcurrentUse is meant to be run with -race.
+func TestSchemaConcurrentUse(t *testing.T) {
+	s := testSchema(t)
+	data := fromHex(t, nodeData)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ext, err := s.Extend(ElementDef{ID: testIdPrivate, Name: "Private", Type: TypeUint, Parent: testIdCluster})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			wt := s.WithTypes(map[int]int{testIdSegment: TypeList})
+			for _, schema := range []*Schema{s, ext, wt, HeaderSchema()} {
+				schema.Name(testIdCluster)
+				schema.Level(testIdBlock)
+				schema.TypeMap()[testIdUint] = i
+				schema.UnknownSizeInfo()
+				schema.ListIDs()
+				if _, err := ParseNodes(data, schema); err != nil {
+					t.Error(err)
+				}
+			}
+			IdTypes()[IdVoid] = i
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+// TransformFunc is called by Transform() for elements with a registered ID
+// and returns the nodes that take the element's place. Returning nil drops
+// the element, returning n keeps it, with any changes made to it, and
+// additional nodes are inserted as siblings after it. The children of a
+// list have already been transformed when the list's function is called.
+type TransformFunc func(n *Node) ([]*Node, error)
+
+func transformNodes(nodes []*Node, funcs map[int]TransformFunc) ([]*Node, error) {
+	result := []*Node{}
+	for _, n := range nodes {
+		if n.Type == TypeList {
+			children, err := transformNodes(n.Children, funcs)
+			if err != nil {
+				return nil, err
+			}
+			n.Children = children
+		}
+
+		f, ok := funcs[n.ID]
+		if !ok {
+			result = append(result, n)
+			continue
+		}
+
+		replacements, err := f(n)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, replacements...)
+	}
+	return result, nil
+}
+
+// writeTransformed writes lists with WriteListStart() and WriteListEnd() so
+// the output is encoded the same way as a streaming copy of the input.
+func writeTransformed(w *Writer, n *Node) error {
+	if n.Type != TypeList {
+		_, err := n.Write(w)
+		return err
+	}
+
+	if err := w.WriteListStart(n.ID); err != nil {
+		return err
+	}
+	for _, child := range n.Children {
+		if err := writeTransformed(w, child); err != nil {
+			return err
+		}
+	}
+	return w.WriteListEnd(n.ID)
+}
+
+// Transform parses input, applies the functions in funcs to the elements
+// with matching IDs and returns the re-encoded result. The whole input is
+// parsed into memory so it should be a single element body, like Tracks,
+// rather than a complete file.
+func Transform(input []byte, funcs map[int]TransformFunc, schema *Schema) ([]byte, error) {
+	nodes, err := ParseNodes(input, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if nodes, err = transformNodes(nodes, funcs); err != nil {
+		return nil, err
+	}
+
+	buf := NewBufferWriter(len(input) + 1)
+	w := NewWriter(buf)
+	for _, n := range nodes {
+		if err := writeTransformed(w, n); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,204 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// transformTestData returns a Cluster with a Uint, a String and two Groups
+// that each hold a Block and a nested Group.
+func transformTestData(t *testing.T) []byte {
+	data, err := EncodeNodes([]*Node{
+		NewListNode(testIdCluster,
+			NewNode(testIdUint, TypeUint, uint64(5)),
+			NewNode(testIdString, TypeString, "abc"),
+			NewListNode(testIdGroup,
+				NewNode(testIdBlock, TypeBinary, []byte{1}),
+				NewListNode(testIdGroup, NewNode(testIdBlock, TypeBinary, []byte{2})),
+			),
+			NewListNode(testIdGroup, NewNode(testIdBlock, TypeBinary, []byte{3})),
+		),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// describe returns the IDs and values of nodes and their descendants in
+// document order, with lists shown as their ID followed by their children.
+func describe(nodes []*Node) []interface{} {
+	result := []interface{}{}
+	for _, n := range nodes {
+		if n.Type == TypeList {
+			result = append(result, n.ID, describe(n.Children))
+			continue
+		}
+		result = append(result, n.ID, n.Value)
+	}
+	return result
+}
+
+func TestTransform(t *testing.T) {
+	tests := []struct {
+		name  string
+		funcs map[int]TransformFunc
+		want  []interface{}
+	}{
+		{
+			"no functions",
+			map[int]TransformFunc{},
+			[]interface{}{testIdCluster, []interface{}{
+				testIdUint, uint64(5), testIdString, "abc",
+				testIdGroup, []interface{}{testIdBlock, []byte{1}, testIdGroup, []interface{}{testIdBlock, []byte{2}}},
+				testIdGroup, []interface{}{testIdBlock, []byte{3}},
+			}},
+		},
+		{
+			"drop leaves",
+			map[int]TransformFunc{
+				testIdUint:  func(n *Node) ([]*Node, error) { return nil, nil },
+				testIdBlock: func(n *Node) ([]*Node, error) { return nil, nil },
+			},
+			[]interface{}{testIdCluster, []interface{}{
+				testIdString, "abc",
+				testIdGroup, []interface{}{testIdGroup, []interface{}{}},
+				testIdGroup, []interface{}{},
+			}},
+		},
+		{
+			"drop lists with their children",
+			map[int]TransformFunc{testIdGroup: func(n *Node) ([]*Node, error) { return nil, nil }},
+			[]interface{}{testIdCluster, []interface{}{testIdUint, uint64(5), testIdString, "abc"}},
+		},
+		{
+			"replace values",
+			map[int]TransformFunc{
+				testIdUint: func(n *Node) ([]*Node, error) {
+					n.Value = n.Value.(uint64) + 1000
+					return []*Node{n}, nil
+				},
+				testIdString: func(n *Node) ([]*Node, error) {
+					return []*Node{NewNode(testIdString, TypeString, "replaced")}, nil
+				},
+			},
+			[]interface{}{testIdCluster, []interface{}{
+				testIdUint, uint64(1005), testIdString, "replaced",
+				testIdGroup, []interface{}{testIdBlock, []byte{1}, testIdGroup, []interface{}{testIdBlock, []byte{2}}},
+				testIdGroup, []interface{}{testIdBlock, []byte{3}},
+			}},
+		},
+		{
+			"insert siblings",
+			map[int]TransformFunc{
+				testIdBlock: func(n *Node) ([]*Node, error) {
+					copied := append([]byte{}, n.Value.([]byte)...)
+					return []*Node{n, NewNode(testIdBlock, TypeBinary, append(copied, 0xFF))}, nil
+				},
+				testIdString: func(n *Node) ([]*Node, error) {
+					return []*Node{n, NewNode(testIdUint, TypeUint, uint64(7)), NewNode(testIdInt, TypeInt, int64(-7))}, nil
+				},
+			},
+			[]interface{}{testIdCluster, []interface{}{
+				testIdUint, uint64(5), testIdString, "abc", testIdUint, uint64(7), testIdInt, int64(-7),
+				testIdGroup, []interface{}{
+					testIdBlock, []byte{1}, testIdBlock, []byte{1, 0xFF},
+					testIdGroup, []interface{}{testIdBlock, []byte{2}, testIdBlock, []byte{2, 0xFF}},
+				},
+				testIdGroup, []interface{}{testIdBlock, []byte{3}, testIdBlock, []byte{3, 0xFF}},
+			}},
+		},
+		{
+			// Lists see their transformed children, so only the outer
+			// Group that still has a child Group is dropped.
+			"children before lists",
+			map[int]TransformFunc{
+				testIdBlock: func(n *Node) ([]*Node, error) { return nil, nil },
+				testIdGroup: func(n *Node) ([]*Node, error) {
+					if len(n.Children) > 0 {
+						return nil, nil
+					}
+					return []*Node{n}, nil
+				},
+			},
+			[]interface{}{testIdCluster, []interface{}{
+				testIdUint, uint64(5), testIdString, "abc", testIdGroup, []interface{}{},
+			}},
+		},
+	}
+
+	schema := testSchema(t)
+	for _, test := range tests {
+		output, err := Transform(transformTestData(t), test.funcs, schema)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		nodes, err := ParseNodes(output, schema)
+		if err != nil {
+			t.Errorf("%s: can't parse the output: %v", test.name, err)
+			continue
+		}
+		if got := describe(nodes); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestTransformErrors(t *testing.T) {
+	schema := testSchema(t)
+	errTransform := errors.New("transform error")
+	fail := map[int]TransformFunc{testIdBlock: func(n *Node) ([]*Node, error) { return nil, errTransform }}
+
+	if output, err := Transform(transformTestData(t), fail, schema); err != errTransform || output != nil {
+		t.Errorf("got %X, %v, want %v", output, err, errTransform)
+	}
+
+	// A Uint that is larger than its Cluster.
+	if _, err := Transform(fromHex(t, "1F43B675 83 E7 85 05"), map[int]TransformFunc{}, schema); !errors.Is(err, ErrSizeOverflow) {
+		t.Errorf("got %v for invalid input, want ErrSizeOverflow", err)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	schema := testSchema(t)
+	output, err := Filter(transformTestData(t), []int{testIdString, testIdGroup}, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := ParseNodes(output, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{testIdCluster, []interface{}{testIdUint, uint64(5)}}; !reflect.DeepEqual(describe(nodes), want) {
+		t.Errorf("got %v, want %v", describe(nodes), want)
+	}
+
+	// Unknown sized lists are written with their size.
+	output, err = Filter(fromHex(t, "1F43B675 01FFFFFFFFFFFFFF E7 81 05 86 81 61"), []int{testIdString}, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err = ParseNodes(output, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || nodes[0].Size != 3 || !reflect.DeepEqual(describe(nodes[0].Children), []interface{}{testIdUint, uint64(5)}) {
+		t.Errorf("got %X", output)
+	}
+}
@@ -22,6 +22,7 @@ const (
 	TypeFloat  = 4
 	TypeString = 5
 	TypeUTF8   = 6
+	TypeDate   = 7
 )
 
 func GetListIDs(typeInfo map[int]int) []int {
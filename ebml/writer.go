@@ -19,25 +19,37 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
+	"math"
 	"os"
+	"time"
 )
 
 const (
 	UNKNOWN_SIZE int64 = 0xffffffffffffff
+
+	crc32ElementSize = 6
 )
 
 type writerListInfo struct {
 	id           int
 	headerOffset int64
 	bodyOffset   int64
+	buffer       *BufferWriter
+	crc32        bool
 }
 
 type Writer struct {
-	offset   int64
-	writer   io.Writer
-	seeker   io.Seeker
-	listInfo []writerListInfo
+	offset         int64
+	writer         io.Writer
+	seeker         io.Seeker
+	listInfo       []writerListInfo
+	crc32Ids       map[int]bool
+	bufferLists    bool
+	maxBufferSize  int
+	unknownSizeIds map[int]bool
+	bufferErr      error
 }
 
 func NewWriter(writerSeeker io.WriteSeeker) *Writer {
@@ -48,10 +60,68 @@ func NewNonSeekableWriter(writer io.Writer) *Writer {
 	return &Writer{offset: 0, writer: writer, seeker: nil, listInfo: []writerListInfo{}}
 }
 
+// SetCRC32Elements makes the writer emit a CRC-32 element as the first
+// child of every element with one of the specified IDs. Lists started with
+// WriteListStart() are buffered in memory until WriteListEnd() so the
+// checksum can be computed. Binary data passed to Write() is treated as a
+// list body and any existing leading CRC-32 element is replaced.
+func (w *Writer) SetCRC32Elements(ids []int) {
+	w.crc32Ids = map[int]bool{}
+	for _, id := range ids {
+		w.crc32Ids[id] = true
+	}
+}
+
+// SetBufferedLists makes the writer assemble lists in memory so that
+// WriteListEnd() can emit them with their real size even if the output
+// can't seek. Lists with one of the unknownSizeIds are written directly
+// with an unknown size. Writes that would buffer more than maxBufferSize
+// bytes fail with ErrBufferLimit. A maxBufferSize of 0 means there is no
+// limit.
+func (w *Writer) SetBufferedLists(maxBufferSize int, unknownSizeIds []int) {
+	w.bufferLists = true
+	w.maxBufferSize = maxBufferSize
+	w.unknownSizeIds = map[int]bool{}
+	for _, id := range unknownSizeIds {
+		w.unknownSizeIds[id] = true
+	}
+}
+
 func (w *Writer) WriteUnknownSizeHeader(id int) (int, error) {
 	return w.writeHeader(id, UNKNOWN_SIZE)
 }
 
+// WriteElementHeader writes an element header with a size field that is
+// exactly sizeLength bytes long. A size of -1 writes an unknown size.
+func (w *Writer) WriteElementHeader(id int, size int64, sizeLength int) (int, error) {
+	if sizeLength < 1 || sizeLength > 8 {
+		return 0, fmt.Errorf("ebml: invalid size length %d", sizeLength)
+	}
+
+	// The value with all bits set is reserved for unknown sizes.
+	unknownSize := int64(1)<<uint(7*sizeLength) - 1
+	if size == -1 {
+		size = unknownSize
+	} else if size < 0 || size >= unknownSize {
+		return 0, fmt.Errorf("ebml: size %d doesn't fit in %d bytes", size, sizeLength)
+	}
+
+	buf := make([]byte, sizeLength)
+	for i := sizeLength - 1; i >= 0; i-- {
+		buf[i] = byte(size & 0xff)
+		size >>= 8
+	}
+	buf[0] |= byte(0x80 >> uint(sizeLength-1))
+
+	id_bytes, err := w.writeId(id)
+	if err != nil {
+		return id_bytes, err
+	}
+
+	size_bytes, err := w.writeToOutput(buf)
+	return id_bytes + size_bytes, err
+}
+
 func (w *Writer) Write(id int, data interface{}) (int, error) {
 	switch v := data.(type) {
 	case uint8:
@@ -73,43 +143,80 @@ func (w *Writer) Write(id int, data interface{}) (int, error) {
 	case int64:
 		return w.writeInt64(id, int64(v))
 	case float32:
+		return w.writeFloat(id, v)
 	case float64:
 		return w.writeFloat(id, v)
 	case string:
 		return w.writeBinary(id, []byte(v))
+	case time.Time:
+		return w.writeDate(id, v)
 	case []byte:
+		if w.crc32Ids[id] {
+			return w.writeBinaryWithCRC32(id, v)
+		}
 		return w.writeBinary(id, v)
 	}
 
-	panic(fmt.Sprintf("Unexpected type %s", data))
-	return 0, errors.New("Unexpected type")
+	panic(fmt.Sprintf("Unexpected type %T", data))
 }
 
-func (w *Writer) WriteListStart(id int) {
+// WriteListStart starts a list with the specified ID. The list's size is
+// filled in by the matching WriteListEnd() call.
+func (w *Writer) WriteListStart(id int) error {
+	if w.bufferErr != nil {
+		return w.bufferErr
+	}
+
 	headerOffset := w.Offset()
+	crc32 := w.crc32Ids[id]
+	if crc32 || (w.bufferLists && !w.unknownSizeIds[id]) {
+		// Leave room for an 8 byte size and the CRC-32 element. Both are
+		// written along with the buffered body in WriteListEnd().
+		w.offset = headerOffset + int64(idLength(id)) + 8
+		if crc32 {
+			w.offset += crc32ElementSize
+		}
+		w.listInfo = append(w.listInfo, writerListInfo{id: id, headerOffset: headerOffset, bodyOffset: w.offset, buffer: NewBufferWriter(4096), crc32: crc32})
+		return nil
+	}
+
 	if _, err := w.WriteUnknownSizeHeader(id); err != nil {
-		panic(fmt.Sprintf("Failed to write header. err=%s", err.Error()))
+		return err
 	}
 	bodyOffset := w.Offset()
 
 	w.listInfo = append(w.listInfo, writerListInfo{id: id, headerOffset: headerOffset, bodyOffset: bodyOffset})
+	return nil
 }
 
-func (w *Writer) WriteListEnd(id int) {
+// WriteListEnd finishes the innermost list with the specified ID along with
+// any lists nested inside it. Errors, including ErrBufferLimit, leave the
+// output unfinished and the writer shouldn't be used after them.
+func (w *Writer) WriteListEnd(id int) error {
+	if w.bufferErr != nil {
+		return w.bufferErr
+	}
+
 	currentOffset := w.Offset()
 
 	rewroteHeaders := false
 	for {
+		if len(w.listInfo) == 0 {
+			return fmt.Errorf("ebml: no open list with ID 0x%x", id)
+		}
 		li := w.listInfo[len(w.listInfo)-1]
 		w.listInfo = w.listInfo[:len(w.listInfo)-1]
 
-		if w.seeker != nil {
-			if _, err := w.seeker.Seek(li.headerOffset, os.SEEK_SET); err == nil {
-				w.offset = li.headerOffset
-				rewroteHeaders = true
-				if _, err = w.writeHeader8(li.id, currentOffset-li.bodyOffset); err != nil {
-					panic(fmt.Sprintf("Header rewrite failed. err=%s", err.Error()))
-				}
+		if li.buffer != nil {
+			// Any headers rewritten so far were inside this buffer.
+			rewroteHeaders = false
+			if err := w.writeBufferedList(li); err != nil {
+				return err
+			}
+		} else if w.seek(li.headerOffset) == nil {
+			rewroteHeaders = true
+			if _, err := w.writeHeader8(li.id, currentOffset-li.bodyOffset); err != nil {
+				return err
 			}
 		}
 
@@ -119,12 +226,82 @@ func (w *Writer) WriteListEnd(id int) {
 	}
 
 	if rewroteHeaders {
-		_, err := w.seeker.Seek(currentOffset, os.SEEK_SET)
-		if err != nil {
-			panic(fmt.Sprintf("Seek back to original offset failed. err=%s", err.Error()))
+		return w.seek(currentOffset)
+	}
+	return nil
+}
+
+// Err returns the error that stopped a buffered list from being written,
+// if any. Once set, every later write fails with the same error.
+func (w *Writer) Err() error {
+	return w.bufferErr
+}
+
+func (w *Writer) writeBufferedList(li writerListInfo) error {
+	body := li.buffer.Bytes()
+	size := int64(len(body))
+	if li.crc32 {
+		size += crc32ElementSize
+	}
+
+	w.offset = li.headerOffset
+	if _, err := w.writeHeader8(li.id, size); err != nil {
+		return err
+	}
+
+	if li.crc32 {
+		if _, err := w.writeCRC32(body); err != nil {
+			return err
 		}
-		w.offset = currentOffset
 	}
+
+	_, err := w.writeToOutput(body)
+	return err
+}
+
+// checkBufferLimit fails if buffering byteCount more bytes would exceed
+// maxBufferSize. Falling back to an unknown size isn't an option since WebM
+// only allows unknown sizes for the lists that aren't buffered. The error
+// is returned by every later write so callers can't silently produce a
+// truncated file.
+func (w *Writer) checkBufferLimit(byteCount int) error {
+	total := byteCount
+	for _, li := range w.listInfo {
+		if li.buffer != nil {
+			total += len(li.buffer.Bytes())
+		}
+	}
+	if total > w.maxBufferSize {
+		w.bufferErr = fmt.Errorf("ebml: %w: %d bytes of buffered lists, limit is %d", ErrBufferLimit, total, w.maxBufferSize)
+		return w.bufferErr
+	}
+	return nil
+}
+
+func (w *Writer) writeBinaryWithCRC32(id int, body []byte) (int, error) {
+	// Drop an existing CRC-32 element since it may not match the body.
+	if len(body) >= crc32ElementSize && body[0] == 0xBF && body[1] == 0x84 {
+		body = body[crc32ElementSize:]
+	}
+
+	header_bytes, err := w.writeHeader(id, int64(len(body)+crc32ElementSize))
+	if err != nil {
+		return header_bytes, err
+	}
+
+	crc_bytes, err := w.writeCRC32(body)
+	if err != nil {
+		return header_bytes + crc_bytes, err
+	}
+
+	body_bytes, err := w.writeToOutput(body)
+	return header_bytes + crc_bytes + body_bytes, err
+}
+
+func (w *Writer) writeCRC32(body []byte) (int, error) {
+	buf := [4]byte{}
+	binary.LittleEndian.PutUint32(buf[:], crc32.ChecksumIEEE(body))
+	return w.writeBinary(IdCRC32, buf[:])
 }
 
 func (w *Writer) WriteVoid(size int) (int, error) {
@@ -156,7 +333,7 @@ func (w *Writer) CanSeek() bool {
 }
 
 func (w *Writer) Offset() int64 {
-	if w.seeker != nil {
+	if w.seeker != nil && w.bufferedList() == nil {
 		offset, err := w.seeker.Seek(0, os.SEEK_CUR)
 		if err == nil && offset != w.offset {
 			panic(fmt.Sprintf("Offset mismatch %d %d\n", offset, w.offset))
@@ -166,15 +343,41 @@ func (w *Writer) Offset() int64 {
 }
 
 func (w *Writer) SetOffset(offset int64) bool {
+	return w.seek(offset) == nil
+}
+
+// bufferedList returns the innermost list that is being buffered in memory
+// or nil if output goes directly to the underlying writer.
+func (w *Writer) bufferedList() *writerListInfo {
+	for i := len(w.listInfo) - 1; i >= 0; i-- {
+		if w.listInfo[i].buffer != nil {
+			return &w.listInfo[i]
+		}
+	}
+	return nil
+}
+
+func (w *Writer) seek(offset int64) error {
+	if li := w.bufferedList(); li != nil {
+		if offset < li.bodyOffset {
+			return errors.New("ebml: can't seek before the start of a buffered list")
+		}
+		if _, err := li.buffer.Seek(offset-li.bodyOffset, os.SEEK_SET); err != nil {
+			return err
+		}
+		w.offset = offset
+		return nil
+	}
+
 	if w.seeker == nil {
-		return false
+		return errors.New("ebml: writer is not seekable")
 	}
 
 	if _, err := w.seeker.Seek(offset, os.SEEK_SET); err != nil {
-		return false
+		return err
 	}
 	w.offset = offset
-	return true
+	return nil
 }
 
 func (w *Writer) WriteToOutput(p []byte) (int, error) {
@@ -182,7 +385,22 @@ func (w *Writer) WriteToOutput(p []byte) (int, error) {
 }
 
 func (w *Writer) writeToOutput(p []byte) (int, error) {
-	n, err := w.writer.Write(p)
+	if w.bufferErr != nil {
+		return 0, w.bufferErr
+	}
+
+	var n int
+	var err error
+	if li := w.bufferedList(); li != nil {
+		if w.maxBufferSize > 0 {
+			if err := w.checkBufferLimit(len(p)); err != nil {
+				return 0, err
+			}
+		}
+		n, err = li.buffer.Write(p)
+	} else {
+		n, err = w.writer.Write(p)
+	}
 	if err == nil {
 		w.offset += int64(n)
 	}
@@ -244,6 +462,23 @@ func (w *Writer) writeUInt64(id int, value uint64) (int, error) {
 	return w.writeBinary(id, buf[:count+1])
 }
 
+// Dates are stored as a signed nanosecond offset from DateEpoch so only
+// about 292 years either side of it can be represented.
+var (
+	minDate = DateEpoch.Add(math.MinInt64)
+	maxDate = DateEpoch.Add(math.MaxInt64)
+)
+
+func (w *Writer) writeDate(id int, value time.Time) (int, error) {
+	if value.Before(minDate) || value.After(maxDate) {
+		return 0, fmt.Errorf("ebml: %w: date %s is outside the range of a Date element", ErrInvalidValue, value.Format(time.RFC3339))
+	}
+
+	buf := [8]byte{}
+	binary.BigEndian.PutUint64(buf[:], uint64(value.Sub(DateEpoch).Nanoseconds()))
+	return w.writeBinary(id, buf[:])
+}
+
 func (w *Writer) writeFloat(id int, value interface{}) (int, error) {
 	buf := new(bytes.Buffer)
 	err := binary.Write(buf, binary.BigEndian, value)
@@ -273,14 +508,19 @@ func (w *Writer) writeHeader8(id int, size int64) (int, error) {
 	return id_bytes + size_bytes, err
 }
 
-func (w *Writer) writeId(id int) (int, error) {
-	buf := [4]byte{0, 0, 0, 0}
+func idLength(id int) int {
 	count := 0
 	mask := 0xff
 
 	for ; id > mask && count < 3; count++ {
 		mask = (mask << 7) | 0x7f
 	}
+	return count + 1
+}
+
+func (w *Writer) writeId(id int) (int, error) {
+	buf := [4]byte{0, 0, 0, 0}
+	count := idLength(id) - 1
 
 	for i := count; i >= 0; i-- {
 		buf[i] = byte(id & 0xff)
@@ -0,0 +1,247 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebml
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"testing"
+	"time"
+)
+
+func verifyCRC32(t *testing.T, data []byte) error {
+	decoder := NewDecoder(bytes.NewReader(data), testSchema(t))
+	decoder.SetVerifyCRC32(true)
+	for {
+		if _, err := decoder.Next(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// checkCRC32 checks that the list at the start of data begins with a CRC-32
+// element holding the checksum of the rest of its body.
+func checkCRC32(t *testing.T, data []byte) {
+	_, headerSize, size, err := ReadElementHeader(bytes.NewReader(data), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := data[headerSize : int64(headerSize)+size]
+	if len(body) < crc32ElementSize || body[0] != 0xBF || body[1] != 0x84 {
+		t.Fatalf("body %X doesn't start with a CRC-32 element", body)
+	}
+	if got, want := binary.LittleEndian.Uint32(body[2:6]), crc32.ChecksumIEEE(body[6:]); got != want {
+		t.Errorf("got CRC-32 0x%08x, want 0x%08x", got, want)
+	}
+}
+
+func TestWriteListCRC32(t *testing.T) {
+	bw := NewBufferWriter(1024)
+	w := NewWriter(bw)
+	w.SetCRC32Elements([]int{testIdCluster})
+	w.WriteListStart(testIdSegment)
+	w.WriteListStart(testIdCluster)
+	w.Write(testIdUint, uint64(300))
+	w.Write(testIdString, "hello")
+	w.WriteListEnd(testIdCluster)
+	w.WriteListEnd(testIdSegment)
+	data := append([]byte{}, bw.Bytes()...)
+
+	_, segmentHeaderSize, _, err := ReadElementHeader(bytes.NewReader(data), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCRC32(t, data[segmentHeaderSize:])
+	if err := verifyCRC32(t, data); err != nil {
+		t.Fatalf("parsing the written data failed: %v", err)
+	}
+
+	// Corrupt the last byte of the String.
+	data[len(data)-1] ^= 0xFF
+	if err := verifyCRC32(t, data); !errors.Is(err, ErrCRC32Mismatch) {
+		t.Errorf("got %v, want ErrCRC32Mismatch", err)
+	}
+}
+
+func TestWriteBinaryCRC32(t *testing.T) {
+	body := fromHex(t, "E7 81 05 86 82 6869")
+	bw := NewBufferWriter(1024)
+	w := NewWriter(bw)
+	w.SetCRC32Elements([]int{testIdCluster})
+	if _, err := w.Write(testIdCluster, body); err != nil {
+		t.Fatal(err)
+	}
+	first := append([]byte{}, bw.Bytes()...)
+	checkCRC32(t, first)
+
+	// Writing the encoded body again replaces the CRC-32 element instead of
+	// adding another one.
+	bw = NewBufferWriter(1024)
+	w = NewWriter(bw)
+	w.SetCRC32Elements([]int{testIdCluster})
+	if _, err := w.Write(testIdCluster, first[5:]); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(bw.Bytes(), first) {
+		t.Errorf("got %X, want %X", bw.Bytes(), first)
+	}
+
+	// Elements without a CRC-32 aren't checked.
+	if err := verifyCRC32(t, fromHex(t, "1F43B675 87 E7 81 05 86 82 6869")); err != nil {
+		t.Errorf("got %v for a list without a CRC-32 element", err)
+	}
+}
+
+// writeBufferedTestLists writes a Segment holding a Cluster with a nested
+// Group and stops at the first error.
+func writeBufferedTestLists(w *Writer) error {
+	steps := []func() error{
+		func() error { return w.WriteListStart(testIdSegment) },
+		func() error { return w.WriteListStart(testIdCluster) },
+		func() error { return w.WriteListStart(testIdGroup) },
+		func() error { _, err := w.Write(testIdBlock, []byte("hi")); return err },
+		func() error { return w.WriteListEnd(testIdGroup) },
+		func() error { _, err := w.Write(testIdUint, uint64(5)); return err },
+		func() error { return w.WriteListEnd(testIdCluster) },
+		func() error { return w.WriteListEnd(testIdSegment) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestWriteBufferedLists(t *testing.T) {
+	tests := []struct {
+		name           string
+		unknownSizeIds []int
+		want           string
+	}{
+		{
+			name:           "unknown size Segment",
+			unknownSizeIds: []int{testIdSegment},
+			want: "18538067 01FFFFFFFFFFFFFF " +
+				"1F43B675 0100000000000010 A0 0100000000000004 A1 82 6869 E7 81 05",
+		},
+		{
+			name: "known size Segment",
+			want: "18538067 010000000000001C " +
+				"1F43B675 0100000000000010 A0 0100000000000004 A1 82 6869 E7 81 05",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := NewNonSeekableWriter(&out)
+			w.SetBufferedLists(0, test.unknownSizeIds)
+			if err := writeBufferedTestLists(w); err != nil {
+				t.Fatal(err)
+			}
+			if want := fromHex(t, test.want); !bytes.Equal(out.Bytes(), want) {
+				t.Errorf("got %X, want %X", out.Bytes(), want)
+			}
+			if w.Offset() != int64(out.Len()) {
+				t.Errorf("got offset %d, want %d", w.Offset(), out.Len())
+			}
+			if _, err := ParseNodes(out.Bytes(), testSchema(t)); err != nil {
+				t.Errorf("parsing the written data failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestWriteBufferedListsLimit(t *testing.T) {
+	var out bytes.Buffer
+	w := NewNonSeekableWriter(&out)
+	w.SetBufferedLists(8, []int{testIdSegment})
+	if err := writeBufferedTestLists(w); !errors.Is(err, ErrBufferLimit) {
+		t.Fatalf("got %v, want ErrBufferLimit", err)
+	}
+	if !errors.Is(w.Err(), ErrBufferLimit) {
+		t.Errorf("Err() returned %v, want ErrBufferLimit", w.Err())
+	}
+
+	// Later calls keep failing and nothing from the Cluster is written.
+	if _, err := w.Write(testIdUint, uint64(1)); !errors.Is(err, ErrBufferLimit) {
+		t.Errorf("Write() returned %v, want ErrBufferLimit", err)
+	}
+	if err := w.WriteListEnd(testIdSegment); !errors.Is(err, ErrBufferLimit) {
+		t.Errorf("WriteListEnd() returned %v, want ErrBufferLimit", err)
+	}
+	if want := fromHex(t, "18538067 01FFFFFFFFFFFFFF"); !bytes.Equal(out.Bytes(), want) {
+		t.Errorf("got %X, want %X", out.Bytes(), want)
+	}
+}
+
+func TestWriteListEndWithoutStart(t *testing.T) {
+	w := NewNonSeekableWriter(&bytes.Buffer{})
+	if err := w.WriteListEnd(testIdCluster); err == nil {
+		t.Error("WriteListEnd() without an open list succeeded")
+	}
+}
+
+func TestWriteDate(t *testing.T) {
+	tests := []struct {
+		value time.Time
+		ok    bool
+	}{
+		{DateEpoch, true},
+		{time.Date(2026, time.October, 17, 12, 30, 0, 123456789, time.UTC), true},
+		{time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC), true},
+		{minDate, true},
+		{maxDate, true},
+		{minDate.Add(-1), false},
+		{maxDate.Add(1), false},
+		{DateEpoch.AddDate(300, 0, 0), false},
+		{DateEpoch.AddDate(-300, 0, 0), false},
+		{time.Time{}, false},
+	}
+
+	for _, test := range tests {
+		bw := NewBufferWriter(16)
+		w := NewWriter(bw)
+		_, err := w.Write(testIdDate, test.value)
+		if !test.ok {
+			if !errors.Is(err, ErrInvalidValue) {
+				t.Errorf("%s: got %v, want ErrInvalidValue", test.value, err)
+			}
+			if len(bw.Bytes()) != 0 || w.Offset() != 0 {
+				t.Errorf("%s: wrote %X", test.value, bw.Bytes())
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: %v", test.value, err)
+			continue
+		}
+		nodes, err := ParseNodes(bw.Bytes(), testSchema(t))
+		if err != nil {
+			t.Errorf("%s: %v", test.value, err)
+			continue
+		}
+		if got := nodes[0].Value.(time.Time); !got.Equal(test.value) {
+			t.Errorf("got %s, want %s", got, test.value)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/acolwell/mse-tools/ebml"
+	"github.com/acolwell/mse-tools/webm"
+	"io/ioutil"
+	"os"
+)
+
+func checkError(str string, err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s - %s\n", str, err.Error())
+		os.Exit(-1)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintf(os.Stderr, "Usage: %s <infile> [<outfile>]\n", os.Args[0])
+		return
+	}
+
+	var data []byte
+	var err error
+	if os.Args[1] == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(os.Args[1])
+	}
+	checkError(fmt.Sprintf("can't read %s", os.Args[1]), err)
+
+	elements, err := ebml.ToJSONElements(data, webm.Schema())
+	checkError("Parse failed", err)
+
+	output, err := json.MarshalIndent(elements, "", "  ")
+	checkError("JSON encoding failed", err)
+	output = append(output, '\n')
+
+	if len(os.Args) < 3 || os.Args[2] == "-" {
+		_, err = os.Stdout.Write(output)
+	} else {
+		err = ioutil.WriteFile(os.Args[2], output, 0644)
+	}
+	checkError("Write failed", err)
+}
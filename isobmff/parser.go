@@ -17,42 +17,94 @@ package isobmff
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 )
 
+var (
+	ErrUnsupportedBoxSize = errors.New("unsupported box size")
+	ErrUnsupportedUUID    = errors.New("uuid boxes not supported")
+	ErrBoxTooLarge        = errors.New("box size exceeds the limit")
+	ErrBufferLimit        = errors.New("buffered data exceeds the limit")
+)
+
+// ParserOptions limits the resources used while parsing untrusted input.
+// A zero field means there is no limit. The parser reports top level boxes
+// without descending into them so there is no nesting depth to limit.
+type ParserOptions struct {
+	// MaxBoxSize is the largest size any box may declare.
+	MaxBoxSize int64
+
+	// MaxBufferedBytes limits the data held in memory at once: input that
+	// hasn't been parsed yet plus the body of the current box, which
+	// clients may accumulate.
+	MaxBufferedBytes int64
+}
+
+// SyntaxError describes where parsing failed. Box is the type of the box
+// being parsed, if known. Err is one of the Err* values above or the error
+// returned by the client.
+type SyntaxError struct {
+	Offset int64
+	Box    string
+	Err    error
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Box == "" {
+		return fmt.Sprintf("isobmff: %s at offset %d", e.Err.Error(), e.Offset)
+	}
+	return fmt.Sprintf("isobmff: %s at offset %d (box '%s')", e.Err.Error(), e.Offset, e.Box)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
 type ParserClient interface {
-	OnHeader(offset int64, hdr []byte, id string, size int64) bool
-	OnBody(offset int64, body []byte) bool
-	OnElementEnd(offset int64, id string) bool
+	OnHeader(offset int64, hdr []byte, id string, size int64) error
+	OnBody(offset int64, body []byte) error
+	OnElementEnd(offset int64, id string) error
 	OnEndOfData(offset int64)
 }
 
 type Parser struct {
-	buf         *bytes.Buffer
-	offset      int64
-	bytesLeft   int64
-	currentId   string
-	client      ParserClient
-	parserError bool
+	buf       *bytes.Buffer
+	offset    int64
+	bytesLeft int64
+	currentId string
+	client    ParserClient
+	err       error
+	options   ParserOptions
 }
 
-func (p *Parser) Append(buf []byte) bool {
-	if p.parserError {
-		return false
+func (p *Parser) SetOptions(options ParserOptions) {
+	p.options = options
+}
+
+func (p *Parser) Append(buf []byte) error {
+	if p.err != nil {
+		return p.err
 	}
 
 	p.buf.Write(buf)
 
 	for p.buf.Len() > 0 {
 		if p.bytesLeft == 0 {
-			totalParsed, id, size := p.readHeader(p.buf.Bytes())
-			if totalParsed <= 0 {
+			totalParsed, id, size, err := p.readHeader(p.buf.Bytes())
+			if err != nil {
+				return p.fail(err, id)
+			}
+			if totalParsed == 0 {
 				break
 			}
 
-			if !p.consumeHeader(totalParsed, id, size) {
-				p.parserError = true
-				return false
+			if err := p.checkLimits(totalParsed, size); err != nil {
+				return p.fail(err, id)
+			}
+
+			if err := p.consumeHeader(totalParsed, id, size); err != nil {
+				return p.fail(err, id)
 			}
 			p.bytesLeft = size - int64(totalParsed)
 		}
@@ -63,73 +115,93 @@ func (p *Parser) Append(buf []byte) bool {
 		}
 
 		p.bytesLeft -= bytesToConsume
-		if !p.consumeBody(int(bytesToConsume)) {
-			p.parserError = true
-			return false
+		if err := p.consumeBody(int(bytesToConsume)); err != nil {
+			return p.fail(err, p.currentId)
 		}
 	}
-	return true
+
+	if p.options.MaxBufferedBytes > 0 && int64(p.buf.Len()) > p.options.MaxBufferedBytes {
+		return p.fail(fmt.Errorf("%w: %d bytes of unparsed input, limit is %d", ErrBufferLimit, p.buf.Len(), p.options.MaxBufferedBytes), "")
+	}
+	return nil
+}
+
+func (p *Parser) checkLimits(headerSize int, size int64) error {
+	if p.options.MaxBoxSize > 0 && size > p.options.MaxBoxSize {
+		return fmt.Errorf("%w: %d > %d", ErrBoxTooLarge, size, p.options.MaxBoxSize)
+	}
+	if bodySize := size - int64(headerSize); p.options.MaxBufferedBytes > 0 && bodySize > p.options.MaxBufferedBytes {
+		return fmt.Errorf("%w: %d byte box, limit is %d", ErrBufferLimit, bodySize, p.options.MaxBufferedBytes)
+	}
+	return nil
 }
 
-func (p *Parser) EndOfData() {
+func (p *Parser) EndOfData() error {
+	if p.err != nil {
+		return p.err
+	}
+
 	p.client.OnEndOfData(p.offset)
+	return nil
+}
+
+func (p *Parser) fail(err error, id string) error {
+	p.err = &SyntaxError{Offset: p.offset, Box: id, Err: err}
+	return p.err
 }
 
-func (p *Parser) readHeader(buf []byte) (int, string, int64) {
+func (p *Parser) readHeader(buf []byte) (int, string, int64, error) {
 	if len(buf) < 8 {
-		return 0, "", 0
+		return 0, "", 0, nil
 	}
 
 	size := int64(binary.BigEndian.Uint32(buf[0:4]))
 	id := bytes.NewBuffer(buf[4:8]).String()
 
 	if size < 8 {
-		fmt.Printf("Unsupported box size %d.\n", size)
-		return -1, "", 0
+		return -1, id, 0, ErrUnsupportedBoxSize
 	}
 
 	if id == "uuid" {
-		fmt.Printf("uuid boxes not supported.\n")
-		return -1, "", 0
+		return -1, id, 0, ErrUnsupportedUUID
 	}
 
-	return 8, id, size
+	return 8, id, size, nil
 }
 
-func (p *Parser) consumeHeader(headerSize int, id string, size int64) bool {
+func (p *Parser) consumeHeader(headerSize int, id string, size int64) error {
 	p.currentId = id
-	if !p.client.OnHeader(p.offset, p.buf.Next(headerSize), id, size) {
-		return false
+	if err := p.client.OnHeader(p.offset, p.buf.Next(headerSize), id, size); err != nil {
+		return err
 	}
 
-	return p.consumeBytes(int64(headerSize))
+	p.consumeBytes(int64(headerSize))
+	return nil
 }
 
-func (p *Parser) consumeBody(byteCount int) bool {
-	if byteCount > 0 && !p.client.OnBody(p.offset, p.buf.Next(byteCount)) {
-		return false
+func (p *Parser) consumeBody(byteCount int) error {
+	if byteCount > 0 {
+		if err := p.client.OnBody(p.offset, p.buf.Next(byteCount)); err != nil {
+			return err
+		}
 	}
 
-	if !p.consumeBytes(int64(byteCount)) {
-		return false
-	}
+	p.consumeBytes(int64(byteCount))
 
 	if p.bytesLeft == 0 {
-		if !p.client.OnElementEnd(p.offset, p.currentId) {
-			return false
+		if err := p.client.OnElementEnd(p.offset, p.currentId); err != nil {
+			return err
 		}
 	}
-	return true
+	return nil
 }
 
-func (p *Parser) consumeBytes(byteCount int64) bool {
+func (p *Parser) consumeBytes(byteCount int64) {
 	if byteCount > 0 {
 		p.offset += byteCount
 	}
-
-	return true
 }
 
 func NewParser(client ParserClient) *Parser {
-	return &Parser{buf: bytes.NewBuffer([]byte{}), offset: 0, bytesLeft: 0, client: client, parserError: false}
+	return &Parser{buf: bytes.NewBuffer([]byte{}), offset: 0, bytesLeft: 0, client: client, err: nil}
 }
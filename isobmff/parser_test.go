@@ -0,0 +1,80 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package isobmff
+
+import (
+	"testing"
+)
+
+// The parser doesn't descend into boxes so MaxBoxSize and MaxBufferedBytes
+// are the only limits.
+var fuzzOptions = ParserOptions{MaxBoxSize: 1 << 20, MaxBufferedBytes: 4096}
+
+type limitClient struct {
+	t        *testing.T
+	bodySize int64
+}
+
+func (c *limitClient) OnHeader(offset int64, hdr []byte, id string, size int64) error {
+	if size > fuzzOptions.MaxBoxSize {
+		c.t.Fatalf("box '%s' with size %d accepted, limit is %d", id, size, fuzzOptions.MaxBoxSize)
+	}
+	c.bodySize = 0
+	return nil
+}
+
+func (c *limitClient) OnBody(offset int64, body []byte) error {
+	c.bodySize += int64(len(body))
+	if c.bodySize > fuzzOptions.MaxBufferedBytes {
+		c.t.Fatalf("%d body bytes buffered, limit is %d", c.bodySize, fuzzOptions.MaxBufferedBytes)
+	}
+	return nil
+}
+
+func (c *limitClient) OnElementEnd(offset int64, id string) error {
+	return nil
+}
+
+func (c *limitClient) OnEndOfData(offset int64) {
+}
+
+func FuzzParser(f *testing.F) {
+	// A 64-bit largesize of 2^56 bytes.
+	f.Add([]byte{0x00, 0x00, 0x00, 0x01, 'm', 'd', 'a', 't',
+		0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, uint8(3))
+	f.Add([]byte{0xFF, 0xFF, 0xFF, 0xFF, 'm', 'd', 'a', 't', 0x00}, uint8(0))
+	f.Add([]byte{0x00, 0x00, 0x00, 0x10, 'f', 't', 'y', 'p', 'i', 's', 'o', 'm',
+		0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x08, 'f', 'r', 'e', 'e'}, uint8(255))
+
+	f.Fuzz(func(t *testing.T, data []byte, chunkSize uint8) {
+		p := NewParser(&limitClient{t: t})
+		p.SetOptions(fuzzOptions)
+
+		size := int(chunkSize) + 1
+		for i := 0; i < len(data); i += size {
+			end := i + size
+			if end > len(data) {
+				end = len(data)
+			}
+			if err := p.Append(data[i:end]); err != nil {
+				break
+			}
+			if n := int64(p.buf.Len()); n > fuzzOptions.MaxBufferedBytes {
+				t.Fatalf("%d bytes of unparsed input buffered, limit is %d", n, fuzzOptions.MaxBufferedBytes)
+			}
+		}
+		p.EndOfData()
+	})
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/acolwell/mse-tools/ebml"
+	"github.com/acolwell/mse-tools/webm"
+	"io/ioutil"
+	"os"
+)
+
+func checkError(str string, err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s - %s\n", str, err.Error())
+		os.Exit(-1)
+	}
+}
+
+func main() {
+	if len(os.Args) < 3 {
+		fmt.Fprintf(os.Stderr, "Usage: %s <infile> <outfile>\n", os.Args[0])
+		return
+	}
+
+	var input []byte
+	var err error
+	if os.Args[1] == "-" {
+		input, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		input, err = ioutil.ReadFile(os.Args[1])
+	}
+	checkError(fmt.Sprintf("can't read %s", os.Args[1]), err)
+
+	elements := []*ebml.JSONElement{}
+	checkError("JSON decoding failed", json.Unmarshal(input, &elements))
+
+	data, err := ebml.FromJSONElements(elements, webm.Schema())
+	checkError("Encoding failed", err)
+
+	if os.Args[2] == "-" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = ioutil.WriteFile(os.Args[2], data, 0644)
+	}
+	checkError("Write failed", err)
+}
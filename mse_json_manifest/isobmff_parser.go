@@ -15,6 +15,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"github.com/acolwell/mse-tools/isobmff"
 )
@@ -25,40 +26,36 @@ type isobmffClient struct {
 	manifest           *JSONManifest
 }
 
-func (c *isobmffClient) OnHeader(offset int64, hdr []byte, id string, size int64) bool {
+func (c *isobmffClient) OnHeader(offset int64, hdr []byte, id string, size int64) error {
 	fmt.Printf("OnHeader(%d, %s, %d)\n", offset, id, size)
 	if offset == 0 && id != "ftyp" {
-		fmt.Printf("File must start with a 'ftyp' box\n")
-		return false
+		return errors.New("file must start with a 'ftyp' box")
 	}
 
 	if id == "moov" {
 		if c.foundInitSegment {
-			fmt.Printf("Multiple 'moov' boxes not supported\n")
-			return false
+			return errors.New("multiple 'moov' boxes not supported")
 		}
 	} else if id == "moof" {
 		if !c.foundInitSegment {
-			fmt.Printf("'moof' boxes must come after the 'moov' box.\n")
-			return false
+			return errors.New("'moof' boxes must come after the 'moov' box")
 		}
 		c.mediaSegmentOffset = offset
 	} else if id == "mdat" {
 		if c.mediaSegmentOffset == -1 {
-			fmt.Printf("'mdat' boxes must come after the 'moof' box.\n")
-			return false
+			return errors.New("'mdat' boxes must come after the 'moof' box")
 		}
 	}
 
-	return true
+	return nil
 }
 
-func (c *isobmffClient) OnBody(offset int64, body []byte) bool {
+func (c *isobmffClient) OnBody(offset int64, body []byte) error {
 	//fmt.Printf("OnBody(%d, %d)\n", offset, len(body))
-	return true
+	return nil
 }
 
-func (c *isobmffClient) OnElementEnd(offset int64, id string) bool {
+func (c *isobmffClient) OnElementEnd(offset int64, id string) error {
 	fmt.Printf("OnElementEnd(%d, %s)\n", offset, id)
 
 	if id == "moov" {
@@ -73,11 +70,11 @@ func (c *isobmffClient) OnElementEnd(offset int64, id string) bool {
 
 		c.mediaSegmentOffset = -1
 	}
-	return true
+	return nil
 }
 
 func (c *isobmffClient) OnEndOfData(offset int64) {
-	fmt.Printf(c.manifest.ToJSON())
+	fmt.Print(c.manifest.ToJSON())
 }
 
 func newISOBMFFClient() *isobmffClient {
@@ -88,6 +85,8 @@ func newISOBMFFClient() *isobmffClient {
 	}
 }
 
-func NewISOBMFFParser() *isobmff.Parser {
-	return isobmff.NewParser(newISOBMFFClient())
+func NewISOBMFFParser(options isobmff.ParserOptions) *isobmff.Parser {
+	parser := isobmff.NewParser(newISOBMFFClient())
+	parser.SetOptions(options)
+	return parser
 }
@@ -49,7 +49,7 @@ func (jm *JSONManifest) ToJSON() string {
 	}
 
 	if !jm.StartDate.IsZero() {
-		str += "  \"startDate\": " + jm.StartDate.Format(time.RFC3339Nano) + ", \n"
+		str += "  \"startDate\": \"" + jm.StartDate.Format(time.RFC3339Nano) + "\",\n"
 	}
 
 	str += fmt.Sprintf("  \"init\": { \"offset\": %d, \"size\": %d},\n",
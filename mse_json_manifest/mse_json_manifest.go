@@ -17,7 +17,10 @@ package main
 import (
 	"bytes"
 	"encoding/binary"
+	"flag"
 	"fmt"
+	"github.com/acolwell/mse-tools/ebml"
+	"github.com/acolwell/mse-tools/isobmff"
 	"io"
 	"log"
 	"net/http"
@@ -26,25 +29,35 @@ import (
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <infile>\n", os.Args[0])
+	var resync bool
+	var maxBufferedBytes int64
+	var maxDepth int
+	flag.BoolVar(&resync, "resync", false, "Skip over corrupt WebM data instead of failing")
+	flag.Int64Var(&maxBufferedBytes, "max-buffer", 0, "Maximum size of a non-list WebM element or MP4 box body in bytes (0 disables the limit)")
+	flag.IntVar(&maxDepth, "max-depth", 0, "Maximum WebM list nesting depth (0 disables the limit)")
+	flag.Parse()
+
+	if len(flag.Args()) < 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-resync] [-max-buffer <bytes>] [-max-depth <depth>] <infile>\n", os.Args[0])
 		return
 	}
 
+	inputArg := flag.Arg(0)
+
 	var in io.Reader = nil
 	var err error = nil
 
-	if os.Args[1] == "-" {
+	if inputArg == "-" {
 		in = os.Stdin
-	} else if strings.HasPrefix(os.Args[1], "http://") {
-		resp, err := http.Get(os.Args[1])
+	} else if strings.HasPrefix(inputArg, "http://") {
+		resp, err := http.Get(inputArg)
 		if err != nil {
 			log.Printf("can't open url; err=%s\n", err.Error())
 			os.Exit(1)
 		}
 		in = resp.Body
 	} else {
-		in, err = os.Open(os.Args[1])
+		in, err = os.Open(inputArg)
 		if in == nil {
 			log.Printf("can't open file; err=%s\n", err.Error())
 			os.Exit(1)
@@ -60,29 +73,37 @@ func main() {
 			done = true
 			continue
 		}
+		if err != nil {
+			log.Printf("Read failed; err=%s\n", err.Error())
+			os.Exit(1)
+		}
 
 		if parser == nil {
 			if len(buf) < 8 {
 				log.Printf("Not enough bytes to detect file type.\n")
-				break
+				os.Exit(1)
 			} else if binary.BigEndian.Uint32(buf[0:4]) == 0x1a45dfa3 {
-				parser = NewWebMParser()
+				parser = NewWebMParser(resync, ebml.ParserOptions{MaxDepth: maxDepth, MaxBufferedBytes: maxBufferedBytes})
 			} else if bytes.NewBuffer(buf[4:8]).String() == "ftyp" {
-				parser = NewISOBMFFParser()
+				parser = NewISOBMFFParser(isobmff.ParserOptions{MaxBufferedBytes: maxBufferedBytes})
 			}
 
 			if parser == nil {
 				log.Printf("Unknown file type.\n")
-				break
+				os.Exit(1)
 			}
 		}
 
-		if !parser.Append(buf[0:bytesRead]) {
-			log.Printf("Parse error\n")
+		if err := parser.Append(buf[0:bytesRead]); err != nil {
+			log.Printf("Parse error; err=%s\n", err.Error())
+			os.Exit(1)
 		}
 	}
 
 	if parser != nil {
-		parser.EndOfData()
+		if err := parser.EndOfData(); err != nil {
+			log.Printf("Parse error; err=%s\n", err.Error())
+			os.Exit(1)
+		}
 	}
 }
@@ -15,6 +15,6 @@
 package main
 
 type Parser interface {
-	Append(buf []byte) bool
-	EndOfData()
+	Append(buf []byte) error
+	EndOfData() error
 }
@@ -15,9 +15,11 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"github.com/acolwell/mse-tools/ebml"
 	"github.com/acolwell/mse-tools/webm"
+	"log"
 	"time"
 )
 
@@ -34,12 +36,12 @@ type webMClient struct {
 	manifest        *JSONManifest
 }
 
-func (c *webMClient) OnListStart(offset int64, id int) bool {
+func (c *webMClient) OnListStart(offset int64, id int) error {
 	//fmt.Printf("OnListStart(%d, %s)\n", offset, webm.IdToName(id))
 
 	if id == ebml.IdHeader {
 		if c.headerSize != -1 {
-			return false
+			return errors.New("multiple EBML headers not supported")
 		}
 		c.headerOffset = offset
 		c.headerSize = -1
@@ -52,10 +54,10 @@ func (c *webMClient) OnListStart(offset int64, id int) bool {
 		}
 		c.clusterOffset = offset
 	}
-	return true
+	return nil
 }
 
-func (c *webMClient) OnListEnd(offset int64, id int) bool {
+func (c *webMClient) OnListEnd(offset int64, id int) error {
 	//fmt.Printf("OnListEnd(%d, %s)\n", offset, webm.IdToName(id))
 	scaleMult := float64(c.timecodeScale) / 1000000000.0
 
@@ -66,7 +68,7 @@ func (c *webMClient) OnListEnd(offset int64, id int) bool {
 		if c.duration != -1 {
 			c.manifest.Duration = c.duration * scaleMult
 		}
-		return true
+		return nil
 	}
 
 	if id == webm.IdTracks {
@@ -80,57 +82,70 @@ func (c *webMClient) OnListEnd(offset int64, id int) bool {
 		}
 
 		c.manifest.Type = contentType
-		return true
+		return nil
 	}
 
 	if id == webm.IdCluster {
+		if c.clusterOffset == -1 {
+			// Drop clusters that were cut short by corrupt data.
+			return nil
+		}
 		c.manifest.Media = append(c.manifest.Media, &MediaSegment{
 			Offset:   c.clusterOffset,
 			Size:     (offset - c.clusterOffset),
 			Timecode: (float64(c.clusterTimecode) * scaleMult),
 		})
-		return true
+		return nil
 	}
 
 	if id == webm.IdSegment {
-		fmt.Printf(c.manifest.ToJSON())
+		fmt.Print(c.manifest.ToJSON())
 	}
-	return true
+	return nil
+}
+
+func (c *webMClient) OnBinary(id int, value []byte) error {
+	return nil
+}
+
+// Binary elements aren't needed for the manifest so they are streamed and
+// dropped instead of being buffered.
+func (c *webMClient) OnBinaryStart(id int, size int64) error {
+	return nil
 }
 
-func (c *webMClient) OnBinary(id int, value []byte) bool {
-	return true
+func (c *webMClient) OnBinaryChunk(data []byte) error {
+	return nil
 }
 
-func (c *webMClient) OnInt(id int, value int64) bool {
-	return true
+func (c *webMClient) OnBinaryEnd() error {
+	return nil
 }
 
-func (c *webMClient) OnUint(id int, value uint64) bool {
+func (c *webMClient) OnInt(id int, value int64) error {
+	return nil
+}
+
+func (c *webMClient) OnUint(id int, value uint64) error {
 	if id == webm.IdTimecodeScale {
 		c.timecodeScale = value
-		return true
+		return nil
 	}
 	if id == webm.IdTimecode {
 		c.clusterTimecode = value
-		return true
+		return nil
 	}
-	if id == webm.IdDateUTC {
-		c.manifest.StartDate = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(value))
-
-		return true
-	}
-	return true
+	return nil
 }
 
-func (c *webMClient) OnFloat(id int, value float64) bool {
+func (c *webMClient) OnFloat(id int, value float64) error {
 	if id == webm.IdDuration {
 		c.manifest.Duration = value
 	}
-	return true
+	return nil
 }
 
-func (c *webMClient) OnString(id int, value string) bool {
+func (c *webMClient) OnString(id int, value string) error {
 	if id == webm.IdCodecID {
 		switch value {
 		case "V_VP8":
@@ -148,7 +163,20 @@ func (c *webMClient) OnString(id int, value string) bool {
 		}
 	}
 
-	return true
+	return nil
+}
+
+func (c *webMClient) OnDate(id int, value time.Time) error {
+	if id == webm.IdDateUTC {
+		c.manifest.StartDate = value
+	}
+	return nil
+}
+
+func (c *webMClient) OnResync(offset int64, size int64) error {
+	log.Printf("Skipped %d bytes of corrupt data at offset %d\n", size, offset)
+	c.clusterOffset = -1
+	return nil
 }
 
 func newWebMClient() *webMClient {
@@ -165,9 +193,13 @@ func newWebMClient() *webMClient {
 	}
 }
 
-func NewWebMParser() *ebml.Parser {
+func NewWebMParser(resync bool, options ebml.ParserOptions) *ebml.Parser {
 	c := newWebMClient()
 
-	return ebml.NewParser(ebml.GetListIDs(webm.IdTypes()), webm.UnknownSizeInfo(),
-		ebml.NewElementParser(c, webm.IdTypes()))
+	parser := ebml.NewParser(webm.Schema(), ebml.NewElementParser(c, webm.Schema()))
+	parser.SetOptions(options)
+	if resync {
+		parser.SetResyncIDs(webm.ResyncIDs())
+	}
+	return parser
 }
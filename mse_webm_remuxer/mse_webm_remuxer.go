@@ -15,7 +15,7 @@
 package main
 
 import (
-	"bytes"
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/acolwell/mse-tools/ebml"
@@ -26,6 +26,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 )
 
 const (
@@ -34,7 +35,9 @@ const (
 	LF             = 0x0a
 	END_OF_HEADERS = "\r\n\r\n"
 
-	SEEK_HEAD_RESERVE_SIZE = 5 * 30
+	// Room for a SeekHead with 6 entries. SeekHead.Write() uses 21 bytes for
+	// each entry plus a 12 byte header so the positions never change its size.
+	SEEK_HEAD_RESERVE_SIZE = 6 * 30
 )
 
 type Cue struct {
@@ -67,6 +70,8 @@ type DemuxerClient struct {
 	outputCuesOffset      int64
 	outputClusterTimecode int64
 	outputTagsOffset      int64
+	outputChaptersOffset  int64
+	pendingChapters       []byte
 
 	lastAudioTimecode int64 // Timecode of last audioBlock written.
 	lastVideoTimecode int64 // Timecode of last videoBlock written.
@@ -78,14 +83,17 @@ type Block struct {
 	isKeyframe          bool
 	timecode            int64
 	flags               uint8
-	data                []byte
+	frames              [][]byte
 	extraBlockGroupData []byte
 }
 
-func NewBlock(id uint64, isSimple bool, isKeyframe bool, timecode int64, flags uint8, data []byte,
+func NewBlock(id uint64, isSimple bool, isKeyframe bool, timecode int64, flags uint8, frames [][]byte,
 	extraBlockGroupData []byte) *Block {
-	block := &Block{id: id, isSimple: isSimple, isKeyframe: isKeyframe, flags: flags, timecode: timecode, data: make([]byte, len(data)), extraBlockGroupData: make([]byte, len(extraBlockGroupData))}
-	copy(block.data, data)
+	block := &Block{id: id, isSimple: isSimple, isKeyframe: isKeyframe, flags: flags, timecode: timecode, frames: make([][]byte, len(frames)), extraBlockGroupData: make([]byte, len(extraBlockGroupData))}
+	for i := range frames {
+		block.frames[i] = make([]byte, len(frames[i]))
+		copy(block.frames[i], frames[i])
+	}
 	copy(block.extraBlockGroupData, extraBlockGroupData)
 	return block
 }
@@ -96,7 +104,7 @@ type BlockGroupClient struct {
 	id                   uint64
 	rawTimecode          int64
 	flags                uint8
-	blockData            []byte
+	frames               [][]byte
 	writer               *ebml.Writer
 }
 
@@ -105,84 +113,83 @@ func NewBlockGroupClient(bw *ebml.BufferWriter) *BlockGroupClient {
 	return bgc
 }
 
-func (c *BlockGroupClient) OnListStart(offset int64, id int) bool {
+func (c *BlockGroupClient) OnListStart(offset int64, id int) error {
 	//log.Printf("OnListStart(%d, %s)\n", offset, webm.IdToName(id))
-	log.Printf("OnListStart() : Unexpected element %s\n", webm.IdToName(id))
-	return false
+	return fmt.Errorf("unexpected element %s", webm.IdToName(id))
 }
 
-func (c *BlockGroupClient) OnListEnd(offset int64, id int) bool {
+func (c *BlockGroupClient) OnListEnd(offset int64, id int) error {
 	//log.Printf("OnListEnd(%d, %s)\n", offset, webm.IdToName(id))
-	log.Printf("OnListEnd() : Unexpected element %s\n", webm.IdToName(id))
-	return false
+	return fmt.Errorf("unexpected element %s", webm.IdToName(id))
 }
 
-func (c *BlockGroupClient) OnBinary(id int, value []byte) bool {
+func (c *BlockGroupClient) OnBinary(id int, value []byte) error {
 	if id == webm.IdBlock {
-		blockInfo := webm.ParseSimpleBlock(value)
+		blockInfo, frames, err := webm.ParseBlockFrames(value)
+		if err != nil {
+			return fmt.Errorf("invalid block: %w", err)
+		}
 		c.id = blockInfo.Id
 		c.rawTimecode = int64(blockInfo.Timecode)
 		c.flags = blockInfo.Flags & 0x0f
-		blockData := value[blockInfo.HeaderSize:]
-		c.blockData = make([]byte, len(blockData))
-		copy(c.blockData, blockData)
+		c.frames = frames
 		c.parsedBlock = true
-		return true
+		return nil
 	} else if id == webm.IdBlockAdditions {
-		c.writer.Write(id, value)
-		return true
+		_, err := c.writer.Write(id, value)
+		return err
 	}
-	log.Printf("OnBinary() : Unexpected element %s size %d\n", webm.IdToName(id), len(value))
-	return false
+	return fmt.Errorf("unexpected element %s size %d", webm.IdToName(id), len(value))
 }
 
-func (c *BlockGroupClient) OnInt(id int, value int64) bool {
+func (c *BlockGroupClient) OnInt(id int, value int64) error {
 	if id == webm.IdReferenceBlock {
 		c.parsedReferenceBlock = true
-		c.writer.Write(id, value)
-		return true
+		_, err := c.writer.Write(id, value)
+		return err
 	} else if id == webm.IdDiscardPadding {
-		c.writer.Write(id, value)
-		return true
+		_, err := c.writer.Write(id, value)
+		return err
 	}
-	log.Printf("OnInt() : Unexpected element %s %d\n", webm.IdToName(id), value)
-	return false
+	return fmt.Errorf("unexpected element %s %d", webm.IdToName(id), value)
 }
 
-func (c *BlockGroupClient) OnUint(id int, value uint64) bool {
+func (c *BlockGroupClient) OnUint(id int, value uint64) error {
 	if id == webm.IdBlockDuration {
-		c.writer.Write(id, value)
-		return true
+		_, err := c.writer.Write(id, value)
+		return err
 	}
-	log.Printf("OnUint() : Unexpected element %s %u\n", webm.IdToName(id), value)
-	return false
+	return fmt.Errorf("unexpected element %s %d", webm.IdToName(id), value)
 }
 
-func (c *BlockGroupClient) OnFloat(id int, value float64) bool {
-	log.Printf("OnFloat() : Unexpected element %s %f\n", webm.IdToName(id), value)
-	return false
+func (c *BlockGroupClient) OnFloat(id int, value float64) error {
+	return fmt.Errorf("unexpected element %s %f", webm.IdToName(id), value)
 }
 
-func (c *BlockGroupClient) OnString(id int, value string) bool {
-	log.Printf("OnString() : Unexpected element %s %s\n", webm.IdToName(id), value)
-	return false
+func (c *BlockGroupClient) OnString(id int, value string) error {
+	return fmt.Errorf("unexpected element %s %s", webm.IdToName(id), value)
 }
 
-func (c *DemuxerClient) OnListStart(offset int64, id int) bool {
+func (c *BlockGroupClient) OnDate(id int, value time.Time) error {
+	return fmt.Errorf("unexpected element %s %s", webm.IdToName(id), value.Format(time.RFC3339Nano))
+}
+
+func (c *DemuxerClient) OnListStart(offset int64, id int) error {
 	//log.Printf("OnListStart(%d, %s)\n", offset, webm.IdToName(id))
 
 	if !c.readEBMLHeader {
-		log.Printf("Unexpected element %s before EBMLHeader\n", webm.IdToName(id))
-		return false
+		return fmt.Errorf("unexpected element %s before EBMLHeader", webm.IdToName(id))
 	}
 
 	if id == webm.IdSegment {
 		c.segmentOffset = offset
 
-		c.writer.WriteListStart(webm.IdSegment)
+		if err := c.writer.WriteListStart(webm.IdSegment); err != nil {
+			return err
+		}
 		c.outputSegmentOffset = c.writer.Offset()
-		c.writer.WriteVoid(SEEK_HEAD_RESERVE_SIZE)
-		return true
+		_, err := c.writer.WriteVoid(SEEK_HEAD_RESERVE_SIZE)
+		return err
 	}
 
 	if id == webm.IdCluster {
@@ -190,99 +197,109 @@ func (c *DemuxerClient) OnListStart(offset int64, id int) bool {
 		if c.outputClusterOffset == -1 {
 			c.outputClusterOffset = c.writer.Offset()
 		}
-		return true
+		return nil
 	}
 
-	log.Printf("OnListStart() : Unexpected element %s\n", webm.IdToName(id))
-	return false
+	return fmt.Errorf("unexpected element %s", webm.IdToName(id))
 }
 
-func (c *DemuxerClient) OnListEnd(offset int64, id int) bool {
+func (c *DemuxerClient) OnListEnd(offset int64, id int) error {
 	//log.Printf("OnListEnd(%d, %s)\n", offset, webm.IdToName(id))
 
 	if id == webm.IdSegment {
 		if c.outputClusterTimecode != -1 {
-			c.writeRemainingBlocks()
-			c.writer.WriteListEnd(webm.IdCluster)
+			if err := c.writeRemainingBlocks(); err != nil {
+				return err
+			}
+			if err := c.writer.WriteListEnd(webm.IdCluster); err != nil {
+				return err
+			}
+		}
+
+		if c.pendingChapters != nil {
+			if err := c.writeChapters(c.pendingChapters); err != nil {
+				return err
+			}
 		}
 
 		if c.writer.CanSeek() {
-			c.writeCues()
+			if err := c.writeCues(); err != nil {
+				return err
+			}
 		}
 
 		// Rewrite seek head.
 		oldOffset := c.writer.Offset()
 		if c.writer.SetOffset(c.outputSegmentOffset) {
-			c.writeSeekHead()
-
-			if c.writer.Offset() < c.outputInfoOffset {
-				c.writer.WriteVoid(int(c.outputInfoOffset - c.writer.Offset()))
-			}
-
+			err := c.writeSeekHead()
 			c.writer.SetOffset(oldOffset)
+			if err != nil {
+				return err
+			}
 		}
 
-		c.writer.WriteListEnd(webm.IdSegment)
-		return true
+		return c.writer.WriteListEnd(webm.IdSegment)
 	}
 
 	if id == webm.IdCluster {
-		return true
+		return nil
 	}
 
-	log.Printf("OnListEnd() : Unexpected element %s\n", webm.IdToName(id))
-	return false
+	return fmt.Errorf("unexpected element %s", webm.IdToName(id))
 }
 
-func (c *DemuxerClient) OnBinary(id int, value []byte) bool {
+func (c *DemuxerClient) OnBinary(id int, value []byte) error {
 	if id == ebml.IdHeader {
 		if c.readEBMLHeader {
-			log.Printf("Already read an EBMLHeader\n")
-			return false
+			return errors.New("already read an EBMLHeader")
 		}
-		if !c.ParseEBMLHeader(value) {
-			return false
+		if err := c.ParseEBMLHeader(value); err != nil {
+			return err
 		}
 		c.readEBMLHeader = true
-		webm.WriteHeader(c.writer)
+		if _, err := webm.WriteHeader(c.writer); err != nil {
+			return err
+		}
 		//c.writer.Write(id, value)
-		return true
+		return nil
 	}
 
 	if !c.readEBMLHeader {
-		log.Printf("Unexpected element %s before EBMLHeader\n", webm.IdToName(id))
-		return false
+		return fmt.Errorf("unexpected element %s before EBMLHeader", webm.IdToName(id))
 	}
 
 	if id == ebml.IdVoid {
-		return true
+		return nil
 	}
 
 	if id == webm.IdSeekHead {
-		return true
+		return nil
 	}
 
 	if id == webm.IdInfo {
-		if !c.ParseInfo(value) {
-			return false
+		if err := c.ParseInfo(value); err != nil {
+			return err
 		}
 		c.outputInfoOffset = c.writer.Offset()
-		c.writer.Write(id, value)
-		return true
+		_, err := c.writer.Write(id, value)
+		return err
 
 	}
 
 	if id == webm.IdTracks {
-		if !c.ParseTracks(value) {
-			return false
+		if err := c.ParseTracks(value); err != nil {
+			return err
 		}
 		c.outputTracksOffset = c.writer.Offset()
 
 		// Filter out deprecated values.
-		filteredValue := webm.Filter(value, []int{webm.IdFrameRate})
+		filteredValue, err := webm.Filter(value, []int{webm.IdFrameRate})
+		if err != nil {
+			return err
+		}
 
-		c.writer.Write(id, filteredValue)
-		return true
+		_, err = c.writer.Write(id, filteredValue)
+		return err
 	}
 
 	if id == webm.IdSimpleBlock {
@@ -295,166 +312,155 @@ func (c *DemuxerClient) OnBinary(id int, value []byte) bool {
 
 	if id == webm.IdTags {
 		c.outputTagsOffset = c.writer.Offset()
-		c.writer.Write(id, value)
-		return true
+		_, err := c.writer.Write(id, value)
+		return err
+	}
+
+	if id == webm.IdChapters {
+		if _, err := webm.ParseChapters(value); err != nil {
+			return err
+		}
+		if c.outputClusterTimecode != -1 {
+			// Chapters can't be written inside the current Cluster so they
+			// are written after the last one. value is only valid during
+			// this call.
+			c.pendingChapters = append([]byte{}, value...)
+			return nil
+		}
+		return c.writeChapters(value)
 	}
 
 	switch id {
 	case webm.IdCues,
 		webm.IdPrevSize,
 		webm.IdPosition:
-		return true
+		return nil
 	}
 
-	log.Printf("OnBinary() : Unexpected element %s\n", webm.IdToName(id))
-	return false
+	return fmt.Errorf("unexpected element %s", webm.IdToName(id))
 }
 
-func (c *DemuxerClient) OnInt(id int, value int64) bool {
-	log.Printf("OnInt() : Unexpected element %s\n", webm.IdToName(id))
-	return false
+func (c *DemuxerClient) OnInt(id int, value int64) error {
+	return fmt.Errorf("unexpected element %s", webm.IdToName(id))
 }
 
-func (c *DemuxerClient) OnUint(id int, value uint64) bool {
+func (c *DemuxerClient) OnUint(id int, value uint64) error {
 	if !c.readEBMLHeader {
-		log.Printf("Unexpected element %s before EBMLHeader\n", webm.IdToName(id))
-		return false
+		return fmt.Errorf("unexpected element %s before EBMLHeader", webm.IdToName(id))
 	}
 
 	if id == webm.IdTimecode {
 		c.clusterTimecode = int64(value)
 		//log.Printf("Input Cluster timecode %d\n", c.clusterTimecode)
-		return true
+		return nil
 	}
 
-	log.Printf("OnUint() : Unexpected element %s\n", webm.IdToName(id))
-	return false
+	return fmt.Errorf("unexpected element %s", webm.IdToName(id))
 }
 
-func (c *DemuxerClient) OnFloat(id int, value float64) bool {
+func (c *DemuxerClient) OnFloat(id int, value float64) error {
 	if !c.readEBMLHeader {
-		log.Printf("Unexpected element %s before EBMLHeader\n", webm.IdToName(id))
-		return false
+		return fmt.Errorf("unexpected element %s before EBMLHeader", webm.IdToName(id))
 	}
 
-	log.Printf("OnFloat() : Unexpected element %s\n", webm.IdToName(id))
-	return false
+	return fmt.Errorf("unexpected element %s", webm.IdToName(id))
 }
 
-func (c *DemuxerClient) OnString(id int, value string) bool {
+func (c *DemuxerClient) OnString(id int, value string) error {
 	if !c.readEBMLHeader {
-		log.Printf("Unexpected element %s before EBMLHeader\n", webm.IdToName(id))
-		return false
+		return fmt.Errorf("unexpected element %s before EBMLHeader", webm.IdToName(id))
 	}
 
-	log.Printf("OnString() : Unexpected element %s\n", webm.IdToName(id))
-	return false
+	return fmt.Errorf("unexpected element %s", webm.IdToName(id))
 }
 
-func (c *DemuxerClient) writeSeekHead() {
-	c.writer.WriteListStart(webm.IdSeekHead)
-	if c.outputInfoOffset > c.outputSegmentOffset {
-		c.writeSeek(webm.IdInfo, c.outputInfoOffset)
-	}
-	if c.outputTracksOffset > c.outputSegmentOffset {
-		c.writeSeek(webm.IdTracks, c.outputTracksOffset)
-	}
-	if c.outputClusterOffset > c.outputSegmentOffset {
-		c.writeSeek(webm.IdCluster, c.outputClusterOffset)
-	}
-	if c.outputCuesOffset > c.outputSegmentOffset {
-		c.writeSeek(webm.IdCues, c.outputCuesOffset)
-	}
-	if c.outputTagsOffset > c.outputSegmentOffset {
-		c.writeSeek(webm.IdTags, c.outputTagsOffset)
+func (c *DemuxerClient) OnDate(id int, value time.Time) error {
+	if !c.readEBMLHeader {
+		return fmt.Errorf("unexpected element %s before EBMLHeader", webm.IdToName(id))
+	}
+
+	return fmt.Errorf("unexpected element %s", webm.IdToName(id))
+}
+
+func (c *DemuxerClient) writeSeekHead() error {
+	seekHead := &webm.SeekHead{}
+	for _, entry := range []struct {
+		id     int
+		offset int64
+	}{
+		{webm.IdInfo, c.outputInfoOffset},
+		{webm.IdTracks, c.outputTracksOffset},
+		{webm.IdCluster, c.outputClusterOffset},
+		{webm.IdCues, c.outputCuesOffset},
+		{webm.IdTags, c.outputTagsOffset},
+		{webm.IdChapters, c.outputChaptersOffset},
+	} {
+		if entry.offset > c.outputSegmentOffset {
+			seekHead.Add(entry.id, uint64(entry.offset-c.outputSegmentOffset))
+		}
 	}
-	c.writer.WriteListEnd(webm.IdSeekHead)
+	return seekHead.Write(c.writer, SEEK_HEAD_RESERVE_SIZE)
 }
 
-func (c *DemuxerClient) writeSeek(id int, offset int64) {
-	c.writer.WriteListStart(webm.IdSeek)
-	c.writer.Write(webm.IdSeekID, uint32(id))
-	c.writer.Write(webm.IdSeekPosition, uint64(offset-c.outputSegmentOffset))
-	c.writer.WriteListEnd(webm.IdSeek)
-}
-
-func (c *DemuxerClient) ParseEBMLHeader(buf []byte) bool {
-	header := ebml.ParseHeader(buf)
-	if header == nil {
-		log.Printf("Failed to parse EBML header\n")
-		return false
+func (c *DemuxerClient) ParseEBMLHeader(buf []byte) error {
+	header, err := ebml.ParseHeader(buf)
+	if err != nil {
+		return err
 	}
 
 	if header.DocType() != "webm" {
-		log.Printf("EBML header has an unsupported DocType '%s'\n", header.DocType())
-		return false
+		return fmt.Errorf("EBML header has an unsupported DocType '%s'", header.DocType())
 	}
 
 	if header.DocTypeReadVersion() != 2 {
-		log.Printf("EBML header has an unsupported DocTypeReadVersion %d\n", header.DocTypeReadVersion())
-		return false
+		return fmt.Errorf("EBML header has an unsupported DocTypeReadVersion %d", header.DocTypeReadVersion())
 	}
 
-	return true
+	return nil
 }
 
-func (c *DemuxerClient) ParseInfo(buf []byte) bool {
-	info := webm.ParseInfoElement(buf)
-	if info == nil {
-		log.Printf("Failed to parse Info element\n")
-		return false
+func (c *DemuxerClient) ParseInfo(buf []byte) error {
+	info, err := webm.ParseInfoElement(buf)
+	if err != nil {
+		return err
 	}
 
 	scale := float64(1000000000 / info.TimecodeScale())
 	c.minClusterDuration = int64(scale * float64(c.minClusterDurationInMS) / 1000.0)
 
-	return true
+	return nil
 }
 
-func (c *DemuxerClient) ParseTracks(buf []byte) bool {
-	c.tracks = webm.ParseTracksElement(buf)
+func (c *DemuxerClient) ParseTracks(buf []byte) error {
+	tracks, err := webm.ParseTracksElement(buf)
+	if err != nil {
+		return err
+	}
+
+	c.tracks = tracks
 	for i := range c.tracks {
 		id := c.tracks[i].ID()
 		c.blocks[id] = []*Block{}
 		c.isVorbis[id] = c.tracks[i].CodecID() == "A_VORBIS"
 	}
 
-	return c.tracks != nil
+	return nil
 }
 
-func (c *DemuxerClient) ParseSimpleBlock(buf []byte) bool {
+func (c *DemuxerClient) ParseSimpleBlock(buf []byte) error {
 	if c.clusterTimecode == -1 {
 		panic("Got a simple block before the cluster timecode.")
 	}
 
-	if len(buf) < 3 {
-		log.Printf("Invalid simple block size %d\n", len(buf))
-		return false
-	}
-
-	mask := byte(0x80)
-	idSize := 1
-	for ; (buf[0]&mask) == 0 && idSize < 8; idSize++ {
-		mask >>= 1
-	}
-
-	if len(buf) < idSize+3 {
-		log.Printf("Invalid simple block size %d\n", len(buf))
-		return false
-	}
-
-	id := uint64(buf[0] & (mask - 1))
-	for i := 1; i < idSize; i++ {
-		id = (id << 8) | uint64(buf[i])
+	blockInfo, frames, err := webm.ParseBlockFrames(buf)
+	if err != nil {
+		return fmt.Errorf("invalid simple block: %w", err)
 	}
 
-	rawTimecode := int64(buf[idSize])<<8 | int64(buf[idSize+1])
-	if (rawTimecode & 0x8000) != 0 {
-		rawTimecode |= (-1 << 16)
-	}
-	timecode := c.clusterTimecode + rawTimecode
-	flags := buf[idSize+2]
-	//log.Printf("in track %d %d 0x%x %d\n", id, timecode, flags, len(buf)-3-idSize)
+	id := blockInfo.Id
+	timecode := c.clusterTimecode + int64(blockInfo.Timecode)
+	flags := blockInfo.Flags
+	//log.Printf("in track %d %d 0x%x %d\n", id, timecode, flags, len(buf)-blockInfo.HeaderSize)
 
 	if c.startTimecode == -1 {
 		c.startTimecode = timecode
@@ -468,17 +474,16 @@ func (c *DemuxerClient) ParseSimpleBlock(buf []byte) bool {
 
 	blockList, ok := c.blocks[id]
 	if !ok {
-		return false
+		return fmt.Errorf("block for unknown track %d", id)
 	}
 
 	isKeyframe := (flags & 0x80) != 0
-	c.blocks[id] = append(blockList, NewBlock(id, true, isKeyframe, timecode, flags, buf[idSize+3:], []byte{}))
+	c.blocks[id] = append(blockList, NewBlock(id, true, isKeyframe, timecode, flags, frames, []byte{}))
 
-	c.tryWritingNextBlock()
-	return true
+	return c.tryWritingNextBlock()
 }
 
-func (c *DemuxerClient) ParseBlockGroup(buf []byte) bool {
+func (c *DemuxerClient) ParseBlockGroup(buf []byte) error {
 	if c.clusterTimecode == -1 {
 		panic("Got a block group before the cluster timecode.")
 	}
@@ -493,14 +498,15 @@ func (c *DemuxerClient) ParseBlockGroup(buf []byte) bool {
 
 	bw := ebml.NewBufferWriter(len(buf))
 	bc := NewBlockGroupClient(bw)
-	p := ebml.NewParser(ebml.GetListIDs(typeInfo), webm.UnknownSizeInfo(),
-		ebml.NewElementParser(bc, typeInfo))
+	schema := webm.Schema().WithTypes(typeInfo)
+	p := ebml.NewParser(schema, ebml.NewElementParser(bc, schema))
 
-	if !p.Append(buf) {
-		log.Printf("Parser error")
-		return false
+	if err := p.Append(buf); err != nil {
+		return err
+	}
+	if err := p.EndOfData(); err != nil {
+		return err
 	}
-	p.EndOfData()
 
 	id := bc.id
 	rawTimecode := bc.rawTimecode
@@ -516,16 +522,15 @@ func (c *DemuxerClient) ParseBlockGroup(buf []byte) bool {
 
 	blockList, ok := c.blocks[id]
 	if !ok {
-		return false
+		return fmt.Errorf("block for unknown track %d", id)
 	}
 
-	c.blocks[id] = append(blockList, NewBlock(id, false, isKeyframe, timecode, flags, bc.blockData, bw.Bytes()))
+	c.blocks[id] = append(blockList, NewBlock(id, false, isKeyframe, timecode, flags, bc.frames, bw.Bytes()))
 
-	c.tryWritingNextBlock()
-	return true
+	return c.tryWritingNextBlock()
 }
 
-func (c *DemuxerClient) tryWritingNextBlock() {
+func (c *DemuxerClient) tryWritingNextBlock() error {
 	audioID := uint64(0)
 	videoID := uint64(0)
 	var audio []*Block = nil
@@ -542,17 +547,15 @@ func (c *DemuxerClient) tryWritingNextBlock() {
 	}
 
 	if video == nil {
-		c.writeNextSingleStreamBlock(audioID)
-		return
+		return c.writeNextSingleStreamBlock(audioID)
 	}
 
 	if audio == nil {
-		c.writeNextSingleStreamBlock(videoID)
-		return
+		return c.writeNextSingleStreamBlock(videoID)
 	}
 
 	if len(video) < 1 || len(audio) < 2 {
-		return
+		return nil
 	}
 	videoBlock := video[0]
 	audioBlock1 := audio[0]
@@ -569,42 +572,47 @@ func (c *DemuxerClient) tryWritingNextBlock() {
 		// 1. audioBlock1 and videoBlock are both keyframes.
 		// 2. audioBlock1 covers videoBlock OR the last audioBlock written covers
 		//    the last videoBlock written as well as the current videoBlock.
-		c.startNewCluster(audioBlock1.id, audioBlock1.timecode)
+		if err := c.startNewCluster(audioBlock1.id, audioBlock1.timecode); err != nil {
+			return err
+		}
 	}
 
 	if audioBlock1.timecode <= videoBlock.timecode {
-		c.writeBlock(audioBlock1)
 		c.lastAudioTimecode = audioBlock1.timecode
 		c.blocks[audioID] = audio[1:]
-		return
+		return c.writeBlock(audioBlock1)
 	}
-	c.writeBlock(videoBlock)
 	c.lastVideoTimecode = videoBlock.timecode
 	c.blocks[videoID] = video[1:]
+	return c.writeBlock(videoBlock)
 }
 
-func (c *DemuxerClient) writeNextSingleStreamBlock(trackID uint64) {
+func (c *DemuxerClient) writeNextSingleStreamBlock(trackID uint64) error {
 	blocks := c.blocks[trackID]
 
 	if len(blocks) < 2 {
-		return
+		return nil
 	}
 
 	block := blocks[0]
 	clusterDuration := block.timecode - c.outputClusterTimecode
 	if block.isKeyframe &&
 		clusterDuration >= c.minClusterDuration {
-		c.startNewCluster(block.id, block.timecode)
+		if err := c.startNewCluster(block.id, block.timecode); err != nil {
+			return err
+		}
 	}
-	c.writeBlock(block)
 	c.blocks[trackID] = blocks[1:]
+	return c.writeBlock(block)
 }
 
-func (c *DemuxerClient) startNewCluster(id uint64, timecode int64) {
+func (c *DemuxerClient) startNewCluster(id uint64, timecode int64) error {
 	//log.Printf("Output Cluster timecode %d\n", timecode)
 
 	if c.outputClusterTimecode != -1 {
-		c.writer.WriteListEnd(webm.IdCluster)
+		if err := c.writer.WriteListEnd(webm.IdCluster); err != nil {
+			return err
+		}
 	}
 
 	c.cues = append(c.cues, Cue{timecode: timecode, offset: c.writer.Offset(), trackID: id})
@@ -613,23 +621,23 @@ func (c *DemuxerClient) startNewCluster(id uint64, timecode int64) {
 		panic(fmt.Sprintf("Negative cluster timecode (%d) not allowed!", timecode))
 	}
 	c.outputClusterTimecode = timecode
-	c.writer.WriteListStart(webm.IdCluster)
-	c.writer.Write(webm.IdTimecode, c.outputClusterTimecode)
-
+	if err := c.writer.WriteListStart(webm.IdCluster); err != nil {
+		return err
+	}
+	_, err := c.writer.Write(webm.IdTimecode, c.outputClusterTimecode)
+	return err
 }
 
-func (c *DemuxerClient) writeBlock(block *Block) {
-	//log.Printf("out track %d %d 0x%x %d\n", block.id, block.timecode, block.flags, len(block.data))
-
-	if block.id > 0x7f {
-		panic("Can't write track numbers yet.")
-	}
+func (c *DemuxerClient) writeBlock(block *Block) error {
+	//log.Printf("out track %d %d 0x%x %d\n", block.id, block.timecode, block.flags, len(block.frames))
 
 	if c.outputClusterTimecode == -1 {
 		if !block.isKeyframe {
 			panic("First block is not a keyframe!")
 		}
-		c.startNewCluster(block.id, block.timecode)
+		if err := c.startNewCluster(block.id, block.timecode); err != nil {
+			return err
+		}
 	}
 
 	rawTimecode := block.timecode - c.outputClusterTimecode
@@ -638,24 +646,35 @@ func (c *DemuxerClient) writeBlock(block *Block) {
 		panic(fmt.Sprintf("rawTimecode is too big %d (%d)\n", rawTimecode, block.timecode))
 	}
 
-	buffer := bytes.NewBuffer([]byte{})
-	buffer.WriteByte(0x80 | byte(block.id))
-	buffer.WriteByte(byte(rawTimecode >> 8))
-	buffer.WriteByte(byte(rawTimecode & 0xff))
-	buffer.WriteByte(block.flags)
-	buffer.Write(block.data)
+	data, err := webm.EncodeBlock(block.id, int(rawTimecode), block.flags, block.frames)
+	if err != nil {
+		return err
+	}
 	if block.isSimple {
-		c.writer.Write(webm.IdSimpleBlock, buffer.Bytes())
-	} else {
-		c.writer.WriteListStart(webm.IdBlockGroup)
-		c.writer.Write(webm.IdBlock, buffer.Bytes())
-		c.writer.WriteToOutput(block.extraBlockGroupData)
-		// TODO
-		c.writer.WriteListEnd(webm.IdBlockGroup)
+		_, err := c.writer.Write(webm.IdSimpleBlock, data)
+		return err
 	}
+
+	if err := c.writer.WriteListStart(webm.IdBlockGroup); err != nil {
+		return err
+	}
+	if _, err := c.writer.Write(webm.IdBlock, data); err != nil {
+		return err
+	}
+	if _, err := c.writer.WriteToOutput(block.extraBlockGroupData); err != nil {
+		return err
+	}
+	// TODO
+	return c.writer.WriteListEnd(webm.IdBlockGroup)
 }
 
-func (c *DemuxerClient) writeRemainingBlocks() {
+func (c *DemuxerClient) writeChapters(value []byte) error {
+	c.outputChaptersOffset = c.writer.Offset()
+	_, err := c.writer.Write(webm.IdChapters, value)
+	return err
+}
+
+func (c *DemuxerClient) writeRemainingBlocks() error {
 	for {
 		var minBlock *Block = nil
 		for _, blockList := range c.blocks {
@@ -670,28 +689,29 @@ func (c *DemuxerClient) writeRemainingBlocks() {
 		}
 
 		if minBlock == nil {
-			break
+			return nil
 		}
 
-		c.writeBlock(minBlock)
 		c.blocks[minBlock.id] = c.blocks[minBlock.id][1:]
+		if err := c.writeBlock(minBlock); err != nil {
+			return err
+		}
 	}
 }
 
-func (c *DemuxerClient) writeCues() {
+func (c *DemuxerClient) writeCues() error {
 	c.outputCuesOffset = c.writer.Offset()
-	c.writer.WriteListStart(webm.IdCues)
-	for i := range c.cues {
-		cue := c.cues[i]
-		c.writer.WriteListStart(webm.IdCuePoint)
-		c.writer.Write(webm.IdCueTime, cue.timecode)
-		c.writer.WriteListStart(webm.IdCueTrackPositions)
-		c.writer.Write(webm.IdCueTrack, cue.trackID)
-		c.writer.Write(webm.IdCueClusterPosition, cue.offset-c.outputSegmentOffset)
-		c.writer.WriteListEnd(webm.IdCueTrackPositions)
-		c.writer.WriteListEnd(webm.IdCuePoint)
-	}
-	c.writer.WriteListEnd(webm.IdCues)
+	index := &webm.Index{CuePoints: []webm.CuePoint{}}
+	for _, cue := range c.cues {
+		index.CuePoints = append(index.CuePoints, webm.CuePoint{
+			CueTime: uint64(cue.timecode),
+			CueTrackPositions: []webm.CueTrackPosition{{
+				CueTrack:           cue.trackID,
+				CueClusterPosition: uint64(cue.offset - c.outputSegmentOffset),
+			}},
+		})
+	}
+	return webm.WriteCues(c.writer, index)
 }
 func NewDemuxerClient(writer *ebml.Writer, minClusterDurationInMS int) *DemuxerClient {
 	return &DemuxerClient{
@@ -716,6 +736,7 @@ func NewDemuxerClient(writer *ebml.Writer, minClusterDurationInMS int) *DemuxerC
 		outputCuesOffset:       -1,
 		outputClusterTimecode:  -1,
 		outputTagsOffset:       -1,
+		outputChaptersOffset:   -1,
 		lastAudioTimecode:      -1,
 		lastVideoTimecode:      -1,
 	}
@@ -730,7 +751,11 @@ func checkError(str string, err error) {
 
 func main() {
 	var minClusterDurationInMS int
+	var writeCRC32 bool
+	var listBufferSize int
 	flag.IntVar(&minClusterDurationInMS, "cm", 250, "Minimum Cluster Duration (ms)")
+	flag.BoolVar(&writeCRC32, "crc", false, "Write CRC-32 elements in Info, Tracks, Cluster, Cues and Tags")
+	flag.IntVar(&listBufferSize, "list-buffer", -1, "Buffer up to this many bytes so lists written to non-seekable outputs get known sizes. Remuxing fails if a list needs more (-1 disables, 0 is unlimited)")
 	flag.Parse()
 
 	if minClusterDurationInMS < 0 || minClusterDurationInMS > 30000 {
@@ -739,7 +764,7 @@ func main() {
 	}
 
 	if len(flag.Args()) < 2 {
-		log.Printf("Usage: %s [-cm <duration>] <infile> <outfile>\n", os.Args[0])
+		log.Printf("Usage: %s [-cm <duration>] [-crc] [-list-buffer <bytes>] <infile> <outfile>\n", os.Args[0])
 		return
 	}
 
@@ -783,6 +808,14 @@ func main() {
 		}
 	}
 
+	if listBufferSize >= 0 && !out.CanSeek() {
+		out.SetBufferedLists(listBufferSize, []int{webm.IdSegment})
+	}
+
+	if writeCRC32 {
+		out.SetCRC32Elements([]int{webm.IdInfo, webm.IdTracks, webm.IdCluster, webm.IdCues, webm.IdTags})
+	}
+
 	buf := [1024]byte{}
 	c := NewDemuxerClient(out, minClusterDurationInMS)
 
@@ -796,21 +829,18 @@ func main() {
 		webm.IdSimpleBlock: ebml.TypeBinary,
 	}
 
-	parser := ebml.NewParser(ebml.GetListIDs(typeInfo), webm.UnknownSizeInfo(),
-		ebml.NewElementParser(c, typeInfo))
+	schema := webm.Schema().WithTypes(typeInfo)
+	parser := ebml.NewParser(schema, ebml.NewElementParser(c, schema))
 
 	for done := false; !done; {
 		bytesRead, err := in.Read(buf[:])
 		if err == io.EOF || err == io.ErrClosedPipe {
-			parser.EndOfData()
+			checkError("Parse failed", parser.EndOfData())
 			done = true
 			continue
 		}
 
-		if !parser.Append(buf[0:bytesRead]) {
-			log.Printf("Parser error")
-			done = true
-			continue
-		}
+		checkError("Read failed", err)
+		checkError("Parse failed", parser.Append(buf[0:bytesRead]))
 	}
 }
@@ -14,6 +14,11 @@
 
 package webm
 
+import (
+	"errors"
+	"fmt"
+)
+
 type BlockInfo struct {
 	Id         uint64
 	Timecode   int
@@ -56,3 +61,187 @@ func ParseSimpleBlock(buf []byte) *BlockInfo {
 
 	return &BlockInfo{Id: id, Timecode: timecode, Flags: flags, HeaderSize: headerSize}
 }
+
+const (
+	LacingNone  = 0x00
+	LacingXiph  = 0x02
+	LacingFixed = 0x04
+	LacingEBML  = 0x06
+
+	lacingMask = 0x06
+)
+
+var ErrInvalidBlock = errors.New("webm: invalid block")
+var ErrInvalidLacing = errors.New("webm: invalid lacing")
+
+// Lacing returns the lacing mode from the block flags.
+func (b *BlockInfo) Lacing() uint8 {
+	return b.Flags & lacingMask
+}
+
+// ParseBlockFrames parses the header of a SimpleBlock or Block and splits
+// its data into frames. The frames point into buf.
+func ParseBlockFrames(buf []byte) (*BlockInfo, [][]byte, error) {
+	blockInfo := ParseSimpleBlock(buf)
+	if blockInfo == nil {
+		return nil, nil, ErrInvalidBlock
+	}
+
+	data := buf[blockInfo.HeaderSize:]
+	if blockInfo.Lacing() == LacingNone {
+		return blockInfo, [][]byte{data}, nil
+	}
+
+	if len(data) < 1 {
+		return nil, nil, ErrInvalidLacing
+	}
+	frameCount := int(data[0]) + 1
+	data = data[1:]
+
+	sizes := make([]int64, frameCount)
+	switch blockInfo.Lacing() {
+	case LacingXiph:
+		for i := 0; i < frameCount-1; i++ {
+			for {
+				if len(data) < 1 {
+					return nil, nil, ErrInvalidLacing
+				}
+				b := data[0]
+				data = data[1:]
+				sizes[i] += int64(b)
+				if b != 0xff {
+					break
+				}
+			}
+		}
+	case LacingEBML:
+		for i := 0; i < frameCount-1; i++ {
+			value, length := readLaceSize(data)
+			if length == 0 {
+				return nil, nil, ErrInvalidLacing
+			}
+			data = data[length:]
+
+			if i == 0 {
+				sizes[i] = value
+				continue
+			}
+			// Later sizes are signed differences from the previous size.
+			sizes[i] = sizes[i-1] + value - (int64(1)<<uint(7*length-1) - 1)
+		}
+	case LacingFixed:
+		if len(data)%frameCount != 0 {
+			return nil, nil, ErrInvalidLacing
+		}
+		for i := 0; i < frameCount-1; i++ {
+			sizes[i] = int64(len(data) / frameCount)
+		}
+	}
+
+	remaining := int64(len(data))
+	for i := 0; i < frameCount-1; i++ {
+		if sizes[i] < 0 || sizes[i] > remaining {
+			return nil, nil, ErrInvalidLacing
+		}
+		remaining -= sizes[i]
+	}
+	sizes[frameCount-1] = remaining
+
+	frames := make([][]byte, frameCount)
+	for i, size := range sizes {
+		frames[i] = data[:size]
+		data = data[size:]
+	}
+	return blockInfo, frames, nil
+}
+
+// readLaceSize reads an EBML lacing size. A length of 0 is returned for
+// invalid sizes.
+func readLaceSize(buf []byte) (int64, int) {
+	if len(buf) < 1 || buf[0] == 0 {
+		return 0, 0
+	}
+
+	mask := byte(0x80)
+	length := 1
+	for ; (buf[0] & mask) == 0; length++ {
+		mask >>= 1
+	}
+	if len(buf) < length {
+		return 0, 0
+	}
+
+	value := int64(buf[0] & (mask - 1))
+	for i := 1; i < length; i++ {
+		value = (value << 8) | int64(buf[i])
+	}
+	return value, length
+}
+
+func appendVint(buf []byte, value uint64, length int) []byte {
+	value |= uint64(1) << uint(7*length)
+	for i := length - 1; i >= 0; i-- {
+		buf = append(buf, byte(value>>uint(8*i)))
+	}
+	return buf
+}
+
+// vintLength returns the number of bytes needed to store value without
+// using the reserved all ones encoding.
+func vintLength(value uint64) int {
+	length := 1
+	for ; length < 8 && value >= uint64(1)<<uint(7*length)-1; length++ {
+	}
+	return length
+}
+
+// EncodeBlock returns the body of a SimpleBlock or Block that holds frames,
+// laced with the lacing mode in flags.
+func EncodeBlock(trackNumber uint64, timecode int, flags uint8, frames [][]byte) ([]byte, error) {
+	if trackNumber >= uint64(1)<<56-1 {
+		return nil, fmt.Errorf("webm: invalid track number %d", trackNumber)
+	}
+	if timecode < -0x8000 || timecode > 0x7fff {
+		return nil, fmt.Errorf("webm: block timecode %d out of range", timecode)
+	}
+	lacing := flags & lacingMask
+	if len(frames) == 0 || len(frames) > 256 || (lacing == LacingNone && len(frames) != 1) {
+		return nil, fmt.Errorf("webm: can't store %d frames in a block with lacing %d", len(frames), lacing)
+	}
+
+	buf := appendVint([]byte{}, trackNumber, vintLength(trackNumber))
+	buf = append(buf, byte(timecode>>8), byte(timecode), flags)
+
+	if lacing != LacingNone {
+		buf = append(buf, byte(len(frames)-1))
+	}
+	for i, frame := range frames[:len(frames)-1] {
+		size := int64(len(frame))
+		switch lacing {
+		case LacingXiph:
+			for ; size >= 0xff; size -= 0xff {
+				buf = append(buf, 0xff)
+			}
+			buf = append(buf, byte(size))
+		case LacingEBML:
+			if i == 0 {
+				buf = appendVint(buf, uint64(size), vintLength(uint64(size)))
+				continue
+			}
+			diff := size - int64(len(frames[i-1]))
+			length := 1
+			for ; length < 8 && (diff < -(int64(1)<<uint(7*length-1)-1) || diff > int64(1)<<uint(7*length-1)-1); length++ {
+			}
+			buf = appendVint(buf, uint64(diff+int64(1)<<uint(7*length-1)-1), length)
+		case LacingFixed:
+			if len(frame) != len(frames[len(frames)-1]) {
+				return nil, errors.New("webm: fixed-size lacing requires frames of equal size")
+			}
+		}
+	}
+
+	for _, frame := range frames {
+		buf = append(buf, frame...)
+	}
+	return buf, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func fromHex(t *testing.T, s string) []byte {
+	data, err := hex.DecodeString(strings.Replace(s, " ", "", -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func makeFrames(sizes ...int) [][]byte {
+	frames := [][]byte{}
+	for i, size := range sizes {
+		frames = append(frames, bytes.Repeat([]byte{byte(i + 1)}, size))
+	}
+	return frames
+}
+
+func TestParseBlockFrames(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		id     uint64
+		frames [][]byte
+	}{
+		{"no lacing", "81 0001 80 AABB", 1, [][]byte{{0xAA, 0xBB}}},
+		{"xiph", "82 0000 02 01 02 AABB CC", 2, [][]byte{{0xAA, 0xBB}, {0xCC}}},
+		{"ebml", "81 0000 06 02 82 BE AABB CC DDEEFF", 1, [][]byte{{0xAA, 0xBB}, {0xCC}, {0xDD, 0xEE, 0xFF}}},
+		{"fixed", "4001 0000 04 01 AABB CCDD", 1, [][]byte{{0xAA, 0xBB}, {0xCC, 0xDD}}},
+	}
+	for _, test := range tests {
+		info, frames, err := ParseBlockFrames(fromHex(t, test.data))
+		if err != nil {
+			t.Errorf("%s: ParseBlockFrames() failed: %v", test.name, err)
+			continue
+		}
+		if info.Id != test.id || !reflect.DeepEqual(frames, test.frames) {
+			t.Errorf("%s: got track %d frames %X, want track %d frames %X", test.name, info.Id, frames, test.id, test.frames)
+		}
+	}
+}
+
+func TestParseBlockFramesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"short header", "81 00"},
+		{"missing frame count", "81 0000 02"},
+		{"truncated xiph size", "81 0000 02 01 FF"},
+		{"xiph size too big", "81 0000 02 01 05 AABB"},
+		{"invalid ebml size", "81 0000 06 01 00 AABB"},
+		{"negative ebml size", "81 0000 06 02 81 80 AABB"},
+		{"uneven fixed", "81 0000 04 01 AABBCC"},
+	}
+	for _, test := range tests {
+		if _, _, err := ParseBlockFrames(fromHex(t, test.data)); err == nil {
+			t.Errorf("%s: ParseBlockFrames() accepted invalid data", test.name)
+		}
+	}
+}
+
+func TestEncodeBlockRoundTrip(t *testing.T) {
+	tests := []struct {
+		lacing uint8
+		frames [][]byte
+	}{
+		{LacingNone, makeFrames(10)},
+		{LacingXiph, makeFrames(0, 254, 255, 256, 600, 3)},
+		{LacingEBML, makeFrames(126, 127, 0, 20000, 5, 1)},
+		{LacingEBML, makeFrames(0, 0)},
+		{LacingFixed, makeFrames(7, 7, 7)},
+	}
+	for _, test := range tests {
+		flags := 0x80 | test.lacing
+		data, err := EncodeBlock(300, -5, flags, test.frames)
+		if err != nil {
+			t.Errorf("lacing %d: EncodeBlock() failed: %v", test.lacing, err)
+			continue
+		}
+		info, frames, err := ParseBlockFrames(data)
+		if err != nil {
+			t.Errorf("lacing %d: ParseBlockFrames() failed: %v", test.lacing, err)
+			continue
+		}
+		if info.Id != 300 || info.Timecode != -5 || info.Flags != flags {
+			t.Errorf("lacing %d: got header %+v", test.lacing, info)
+		}
+		if !reflect.DeepEqual(frames, test.frames) {
+			t.Errorf("lacing %d: got frame sizes %v", test.lacing, frameSizes(frames))
+		}
+	}
+}
+
+func frameSizes(frames [][]byte) []int {
+	sizes := []int{}
+	for _, frame := range frames {
+		sizes = append(sizes, len(frame))
+	}
+	return sizes
+}
+
+func TestEncodeBlockErrors(t *testing.T) {
+	if _, err := EncodeBlock(1, 0, LacingNone, makeFrames(1, 1)); err == nil {
+		t.Errorf("EncodeBlock() accepted 2 frames without lacing")
+	}
+	if _, err := EncodeBlock(1, 0, LacingFixed, makeFrames(1, 2)); err == nil {
+		t.Errorf("EncodeBlock() accepted fixed lacing with different frame sizes")
+	}
+	if _, err := EncodeBlock(1, 0x8000, LacingNone, makeFrames(1)); err == nil {
+		t.Errorf("EncodeBlock() accepted an out of range timecode")
+	}
+	if _, err := EncodeBlock(1, 0, LacingXiph, make([][]byte, 257)); err == nil {
+		t.Errorf("EncodeBlock() accepted 257 frames")
+	}
+}
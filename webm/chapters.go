@@ -0,0 +1,83 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import "github.com/acolwell/mse-tools/ebml"
+
+type ChapterDisplay struct {
+	ChapString        string `ebml:"0x85"`
+	ChapLanguage      string `ebml:"0x437C,default=eng"`
+	ChapLanguageBCP47 string `ebml:"0x437D,omitempty"`
+	ChapCountry       string `ebml:"0x437E,omitempty"`
+}
+
+// ChapterAtom is a chapter and its nested chapters. ChapterTimeStart and
+// ChapterTimeEnd are in nanoseconds and aren't scaled by TimecodeScale.
+// ChapterTimeEnd is nil if the chapter doesn't have an end time.
+type ChapterAtom struct {
+	ChapterUID         uint64           `ebml:"0x73C4"`
+	ChapterStringUID   string           `ebml:"0x5654,omitempty"`
+	ChapterTimeStart   uint64           `ebml:"0x91"`
+	ChapterTimeEnd     *uint64          `ebml:"0x92"`
+	ChapterFlagHidden  bool             `ebml:"0x98,omitempty"`
+	ChapterFlagEnabled bool             `ebml:"0x4598,default=1"`
+	ChapterDisplay     []ChapterDisplay `ebml:"0x80"`
+	ChapterAtoms       []ChapterAtom    `ebml:"0xB6"`
+}
+
+type EditionEntry struct {
+	EditionUID         uint64        `ebml:"0x45BC,omitempty"`
+	EditionFlagHidden  bool          `ebml:"0x45BD,omitempty"`
+	EditionFlagDefault bool          `ebml:"0x45DB,omitempty"`
+	EditionFlagOrdered bool          `ebml:"0x45DD,omitempty"`
+	ChapterAtoms       []ChapterAtom `ebml:"0xB6"`
+}
+
+type Chapters struct {
+	EditionEntries []EditionEntry `ebml:"0x45B9"`
+}
+
+// ParseChapters parses the body of a Chapters element.
+func ParseChapters(buf []byte) (*Chapters, error) {
+	chapters := &Chapters{EditionEntries: []EditionEntry{}}
+	if err := ebml.Unmarshal(buf, chapters); err != nil {
+		return nil, err
+	}
+	return chapters, nil
+}
+
+// WriteChapters writes a Chapters element containing the editions in
+// chapters. The editions are marshaled before anything is written so an
+// invalid edition doesn't leave the Chapters element open.
+func WriteChapters(w *ebml.Writer, chapters *Chapters) error {
+	bodies := make([][]byte, len(chapters.EditionEntries))
+	for i := range chapters.EditionEntries {
+		body, err := ebml.Marshal(&chapters.EditionEntries[i])
+		if err != nil {
+			return err
+		}
+		bodies[i] = body
+	}
+
+	if err := w.WriteListStart(IdChapters); err != nil {
+		return err
+	}
+	for _, body := range bodies {
+		if _, err := w.Write(IdEditionEntry, body); err != nil {
+			return err
+		}
+	}
+	return w.WriteListEnd(IdChapters)
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"github.com/acolwell/mse-tools/ebml"
+	"reflect"
+	"testing"
+)
+
+func TestChaptersRoundTrip(t *testing.T) {
+	zero := uint64(0)
+	end := uint64(5e9)
+	chapters := &Chapters{EditionEntries: []EditionEntry{
+		{
+			EditionUID:         10,
+			EditionFlagDefault: true,
+			ChapterAtoms: []ChapterAtom{
+				{
+					ChapterUID:         1,
+					ChapterTimeStart:   0,
+					ChapterTimeEnd:     &end,
+					ChapterFlagEnabled: true,
+					ChapterDisplay: []ChapterDisplay{
+						{ChapString: "Intro", ChapLanguage: "eng"},
+						{ChapString: "Einleitung", ChapLanguage: "ger", ChapLanguageBCP47: "de", ChapCountry: "de"},
+					},
+					ChapterAtoms: []ChapterAtom{
+						{
+							ChapterUID:         2,
+							ChapterStringUID:   "intro-a",
+							ChapterTimeStart:   1e9,
+							ChapterTimeEnd:     &zero,
+							ChapterFlagEnabled: true,
+							ChapterAtoms: []ChapterAtom{
+								{ChapterUID: 3, ChapterTimeStart: 2e9, ChapterFlagHidden: true, ChapterFlagEnabled: true},
+							},
+						},
+					},
+				},
+				{ChapterUID: 4, ChapterTimeStart: 5e9},
+			},
+		},
+		{
+			EditionFlagHidden:  true,
+			EditionFlagOrdered: true,
+			ChapterAtoms:       []ChapterAtom{{ChapterUID: 5, ChapterTimeStart: 7e9, ChapterFlagEnabled: true}},
+		},
+	}}
+
+	body := encodeBody(t, IdChapters, func(w *ebml.Writer) error { return WriteChapters(w, chapters) })
+	parsed, err := ParseChapters(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, chapters) {
+		t.Errorf("got %+v, want %+v", parsed, chapters)
+	}
+
+	atoms := parsed.EditionEntries[0].ChapterAtoms
+	if atoms[1].ChapterTimeEnd != nil {
+		t.Errorf("got end time %d for a chapter without one", *atoms[1].ChapterTimeEnd)
+	}
+	if end := atoms[0].ChapterAtoms[0].ChapterTimeEnd; end == nil || *end != 0 {
+		t.Errorf("an end time of 0 was lost")
+	}
+}
+
+func TestParseChaptersDefaults(t *testing.T) {
+	// An EditionEntry with a ChapterAtom that only has a ChapterUID, a
+	// ChapterTimeStart and a ChapterDisplay with a ChapString.
+	body := fromHex(t, "45B9 92 B6 90 73C4 81 01 91 81 00 80 87 85 85 5469746C65")
+	chapters, err := ParseChapters(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	atom := chapters.EditionEntries[0].ChapterAtoms[0]
+	if !atom.ChapterFlagEnabled || atom.ChapterTimeEnd != nil {
+		t.Errorf("got %+v", atom)
+	}
+	if len(atom.ChapterDisplay) != 1 || atom.ChapterDisplay[0].ChapLanguage != "eng" || atom.ChapterDisplay[0].ChapString != "Title" {
+		t.Errorf("got displays %+v", atom.ChapterDisplay)
+	}
+}
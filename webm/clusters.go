@@ -0,0 +1,150 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"github.com/acolwell/mse-tools/ebml"
+	"io"
+	"sync"
+)
+
+// ClusterRange is the location of a Cluster element, including its header.
+type ClusterRange struct {
+	Offset int64
+	Size   int64
+}
+
+// ClusterRanges returns the location of every Cluster in the file.
+func (f *File) ClusterRanges() ([]ClusterRange, error) {
+	ranges := []ClusterRange{}
+	for i := 0; ; i++ {
+		if err := f.findCluster(i); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		end, err := f.clusterEnd(f.clusterOffsets[i])
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, ClusterRange{Offset: f.clusterOffsets[i], Size: end - f.clusterOffsets[i]})
+	}
+	return ranges, nil
+}
+
+func parseCluster(r io.ReaderAt, cluster ClusterRange, client ebml.ElementParserClient) error {
+	parser := ebml.NewParser(schema, ebml.NewElementParser(client, schema))
+	if err := parser.ResumeAt(cluster.Offset, nil); err != nil {
+		return err
+	}
+
+	in := io.NewSectionReader(r, cluster.Offset, cluster.Size)
+	buf := make([]byte, 65536)
+	bytesRead := int64(0)
+	for {
+		n, err := in.Read(buf)
+		if n > 0 {
+			bytesRead += int64(n)
+			if err := parser.Append(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			// EndOfData() doesn't complain about a Cluster that was cut
+			// short by the end of r.
+			if bytesRead < cluster.Size {
+				return io.ErrUnexpectedEOF
+			}
+			return parser.EndOfData()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ParseClusters parses clusters on up to workers goroutines. Each Cluster
+// gets its own client from newClient(), which is called from the worker
+// goroutines, and offsets passed to the client are
+// relative to the start of r. merge() is called with each client, in
+// cluster order, once its Cluster has been parsed. At most workers clusters
+// are parsed ahead of merge() so only that many clients are held in memory
+// at a time. Parsing stops at the first error from a Cluster or from
+// merge().
+func ParseClusters(r io.ReaderAt, clusters []ClusterRange, workers int, newClient func(i int) ebml.ElementParserClient, merge func(i int, client ebml.ElementParserClient) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	type result struct {
+		client ebml.ElementParserClient
+		err    error
+	}
+
+	// Cluster i is only started once cluster i-workers has been merged so
+	// result slot i%workers is always free when a worker writes to it.
+	window := make(chan struct{}, workers)
+	results := make([]chan result, workers)
+	for i := range results {
+		results[i] = make(chan result, 1)
+	}
+
+	indices := make(chan int)
+	stop := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				client := newClient(i)
+				results[i%workers] <- result{client: client, err: parseCluster(r, clusters[i], client)}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(indices)
+		for i := range clusters {
+			select {
+			case window <- struct{}{}:
+			case <-stop:
+				return
+			}
+			select {
+			case indices <- i:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var err error
+	for i := range clusters {
+		res := <-results[i%workers]
+		if err = res.err; err == nil {
+			err = merge(i, res.client)
+		}
+		if err != nil {
+			break
+		}
+		<-window
+	}
+
+	close(stop)
+	wg.Wait()
+	return err
+}
@@ -0,0 +1,214 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/acolwell/mse-tools/ebml"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// timecodeClient records the Timecode and number of SimpleBlocks in a
+// Cluster. OnUint() fails with err if it is set.
+type timecodeClient struct {
+	timecode uint64
+	blocks   int
+	err      error
+}
+
+func (c *timecodeClient) OnListStart(offset int64, id int) error { return nil }
+func (c *timecodeClient) OnListEnd(offset int64, id int) error   { return nil }
+func (c *timecodeClient) OnInt(id int, value int64) error        { return nil }
+func (c *timecodeClient) OnFloat(id int, value float64) error    { return nil }
+func (c *timecodeClient) OnString(id int, value string) error    { return nil }
+func (c *timecodeClient) OnDate(id int, value time.Time) error   { return nil }
+
+func (c *timecodeClient) OnBinary(id int, value []byte) error {
+	if id == IdSimpleBlock {
+		c.blocks++
+	}
+	return nil
+}
+
+func (c *timecodeClient) OnUint(id int, value uint64) error {
+	if id == IdTimecode {
+		c.timecode = value
+	}
+	return c.err
+}
+
+// writeTestClusters writes count Clusters with Timecodes 0, 1000, 2000...
+// that each hold two SimpleBlocks and returns their locations.
+func writeTestClusters(t *testing.T, w *ebml.Writer, count int) []ClusterRange {
+	ranges := []ClusterRange{}
+	for i := 0; i < count; i++ {
+		block, err := EncodeBlock(1, 0, 0x80, [][]byte{{byte(i)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		offset := w.Offset()
+		if err := w.WriteListStart(IdCluster); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(IdTimecode, uint64(i*1000)); err != nil {
+			t.Fatal(err)
+		}
+		for j := 0; j < 2; j++ {
+			if _, err := w.Write(IdSimpleBlock, block); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.WriteListEnd(IdCluster); err != nil {
+			t.Fatal(err)
+		}
+		ranges = append(ranges, ClusterRange{Offset: offset, Size: w.Offset() - offset})
+	}
+	return ranges
+}
+
+func TestParseClusters(t *testing.T) {
+	bw := ebml.NewBufferWriter(1024)
+	clusters := writeTestClusters(t, ebml.NewWriter(bw), 20)
+	r := bytes.NewReader(bw.Bytes())
+
+	for _, workers := range []int{0, 1, 3, 8, 32} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			mutex := sync.Mutex{}
+			pending := 0
+			maxPending := 0
+			newClient := func(i int) ebml.ElementParserClient {
+				mutex.Lock()
+				pending++
+				if pending > maxPending {
+					maxPending = pending
+				}
+				mutex.Unlock()
+
+				// Keep the other workers running ahead of the first Cluster.
+				if i == 0 {
+					time.Sleep(10 * time.Millisecond)
+				}
+				return &timecodeClient{}
+			}
+
+			merged := []uint64{}
+			merge := func(i int, client ebml.ElementParserClient) error {
+				mutex.Lock()
+				pending--
+				mutex.Unlock()
+
+				c := client.(*timecodeClient)
+				if c.blocks != 2 {
+					t.Errorf("cluster %d has %d blocks, want 2", i, c.blocks)
+				}
+				merged = append(merged, c.timecode)
+				return nil
+			}
+
+			if err := ParseClusters(r, clusters, workers, newClient, merge); err != nil {
+				t.Fatal(err)
+			}
+			if len(merged) != len(clusters) {
+				t.Fatalf("merged %d clusters, want %d", len(merged), len(clusters))
+			}
+			for i, timecode := range merged {
+				if timecode != uint64(i*1000) {
+					t.Errorf("merge %d got Timecode %d, want %d", i, timecode, i*1000)
+				}
+			}
+
+			limit := workers
+			if limit < 1 {
+				limit = 1
+			}
+			if maxPending > limit {
+				t.Errorf("%d clients were waiting to be merged, limit is %d", maxPending, limit)
+			}
+		})
+	}
+}
+
+func TestParseClustersErrors(t *testing.T) {
+	bw := ebml.NewBufferWriter(1024)
+	clusters := writeTestClusters(t, ebml.NewWriter(bw), 20)
+	r := bytes.NewReader(bw.Bytes())
+	errClient := errors.New("client error")
+	errMerge := errors.New("merge error")
+
+	tests := []struct {
+		name      string
+		failAt    int
+		mergeFail bool
+		want      error
+	}{
+		{"client error", 5, false, errClient},
+		{"merge error", 7, true, errMerge},
+		{"first cluster", 0, false, errClient},
+		{"last cluster", 19, true, errMerge},
+	}
+
+	for _, test := range tests {
+		for _, workers := range []int{1, 4} {
+			t.Run(fmt.Sprintf("%s/%d workers", test.name, workers), func(t *testing.T) {
+				newClient := func(i int) ebml.ElementParserClient {
+					c := &timecodeClient{}
+					if i == test.failAt && !test.mergeFail {
+						c.err = errClient
+					}
+					return c
+				}
+
+				merged := 0
+				merge := func(i int, client ebml.ElementParserClient) error {
+					if i != merged {
+						t.Errorf("got merge %d, want %d", i, merged)
+					}
+					merged++
+					if i == test.failAt && test.mergeFail {
+						return errMerge
+					}
+					return nil
+				}
+
+				err := ParseClusters(r, clusters, workers, newClient, merge)
+				if !errors.Is(err, test.want) {
+					t.Errorf("got %v, want %v", err, test.want)
+				}
+
+				want := test.failAt
+				if test.mergeFail {
+					want++
+				}
+				if merged != want {
+					t.Errorf("merged %d clusters, want %d", merged, want)
+				}
+			})
+		}
+	}
+
+	// A reader that ends inside a Cluster fails.
+	short := bytes.NewReader(bw.Bytes()[:clusters[3].Offset+clusters[3].Size-2])
+	newClient := func(i int) ebml.ElementParserClient { return &timecodeClient{} }
+	merge := func(i int, client ebml.ElementParserClient) error { return nil }
+	if err := ParseClusters(short, clusters, 2, newClient, merge); err != io.ErrUnexpectedEOF {
+		t.Errorf("got %v for a truncated Cluster, want io.ErrUnexpectedEOF", err)
+	}
+}
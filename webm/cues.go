@@ -0,0 +1,132 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"github.com/acolwell/mse-tools/ebml"
+	"sort"
+)
+
+// CueTrackPosition locates a track's block. CueClusterPosition is relative
+// to the start of the Segment data and CueRelativePosition to the start of
+// the Cluster data.
+type CueTrackPosition struct {
+	CueTrack            uint64 `ebml:"0xF7"`
+	CueClusterPosition  uint64 `ebml:"0xF1"`
+	CueRelativePosition uint64 `ebml:"0xF0,omitempty"`
+	CueDuration         uint64 `ebml:"0xB2,omitempty"`
+	CueBlockNumber      uint64 `ebml:"0x5378,omitempty"`
+}
+
+type CuePoint struct {
+	CueTime           uint64             `ebml:"0xB3"`
+	CueTrackPositions []CueTrackPosition `ebml:"0xB7"`
+}
+
+// Index holds the CuePoints of a Cues element sorted by CueTime.
+type Index struct {
+	CuePoints []CuePoint `ebml:"0xBB"`
+}
+
+// ParseCues parses the body of a Cues element.
+func ParseCues(buf []byte) (*Index, error) {
+	index := &Index{CuePoints: []CuePoint{}}
+	if err := ebml.Unmarshal(buf, index); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(index.CuePoints, func(i, j int) bool {
+		return index.CuePoints[i].CueTime < index.CuePoints[j].CueTime
+	})
+	return index, nil
+}
+
+// Lookup returns the CueClusterPosition of the last CuePoint at or before
+// timecode. The first CuePoint is used for timecodes before it. false is
+// returned if there are no positions in the index.
+func (x *Index) Lookup(timecode uint64) (uint64, bool) {
+	position, ok := x.lookup(timecode, func(p *CueTrackPosition) bool { return true })
+	return position.CueClusterPosition, ok
+}
+
+// LookupTrack is like Lookup() but only uses the positions for track.
+func (x *Index) LookupTrack(timecode uint64, track uint64) (CueTrackPosition, bool) {
+	return x.lookup(timecode, func(p *CueTrackPosition) bool { return p.CueTrack == track })
+}
+
+func (x *Index) lookup(timecode uint64, match func(p *CueTrackPosition) bool) (CueTrackPosition, bool) {
+	var found *CueTrackPosition
+	for i := range x.CuePoints {
+		point := &x.CuePoints[i]
+		if point.CueTime > timecode && found != nil {
+			break
+		}
+
+		for j := range point.CueTrackPositions {
+			if match(&point.CueTrackPositions[j]) {
+				found = &point.CueTrackPositions[j]
+				break
+			}
+		}
+	}
+
+	if found == nil {
+		return CueTrackPosition{}, false
+	}
+	return *found, true
+}
+
+// WriteCues writes a Cues element containing the points in index. The
+// CueTrackPositions are marshaled before anything is written so an invalid
+// index doesn't leave lists open. Errors from w itself leave the Cues element
+// unfinished and w shouldn't be used after them.
+func WriteCues(w *ebml.Writer, index *Index) error {
+	bodies := make([][][]byte, len(index.CuePoints))
+	for i, point := range index.CuePoints {
+		for j := range point.CueTrackPositions {
+			body, err := ebml.Marshal(&point.CueTrackPositions[j])
+			if err != nil {
+				return err
+			}
+			bodies[i] = append(bodies[i], body)
+		}
+	}
+
+	if err := w.WriteListStart(IdCues); err != nil {
+		return err
+	}
+	for i, point := range index.CuePoints {
+		if err := w.WriteListStart(IdCuePoint); err != nil {
+			return err
+		}
+		if _, err := w.Write(IdCueTime, point.CueTime); err != nil {
+			return err
+		}
+		for _, body := range bodies[i] {
+			if err := w.WriteListStart(IdCueTrackPositions); err != nil {
+				return err
+			}
+			if _, err := w.WriteToOutput(body); err != nil {
+				return err
+			}
+			if err := w.WriteListEnd(IdCueTrackPositions); err != nil {
+				return err
+			}
+		}
+		if err := w.WriteListEnd(IdCuePoint); err != nil {
+			return err
+		}
+	}
+	return w.WriteListEnd(IdCues)
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"bytes"
+	"github.com/acolwell/mse-tools/ebml"
+	"reflect"
+	"testing"
+)
+
+// encodeBody writes an element with write and returns its body.
+func encodeBody(t *testing.T, id int, write func(w *ebml.Writer) error) []byte {
+	bw := ebml.NewBufferWriter(1024)
+	if err := write(ebml.NewWriter(bw)); err != nil {
+		t.Fatal(err)
+	}
+	data := bw.Bytes()
+	elementId, headerSize, size, err := ebml.ReadElementHeader(bytes.NewReader(data), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elementId != id || int64(headerSize)+size != int64(len(data)) {
+		t.Fatalf("got %s with size %d in %d bytes", IdToName(elementId), size, len(data))
+	}
+	return data[headerSize:]
+}
+
+func TestCuesRoundTrip(t *testing.T) {
+	index := &Index{CuePoints: []CuePoint{
+		{CueTime: 0, CueTrackPositions: []CueTrackPosition{{CueTrack: 1, CueClusterPosition: 100}}},
+		{CueTime: 2000, CueTrackPositions: []CueTrackPosition{
+			{CueTrack: 1, CueClusterPosition: 5000, CueRelativePosition: 12},
+			{CueTrack: 2, CueClusterPosition: 5000, CueRelativePosition: 300, CueDuration: 40, CueBlockNumber: 3},
+		}},
+		{CueTime: 4000, CueTrackPositions: []CueTrackPosition{{CueTrack: 2, CueClusterPosition: 90000}}},
+	}}
+
+	body := encodeBody(t, IdCues, func(w *ebml.Writer) error { return WriteCues(w, index) })
+	parsed, err := ParseCues(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, index) {
+		t.Errorf("got %+v, want %+v", parsed, index)
+	}
+}
+
+func TestParseCuesSorts(t *testing.T) {
+	index := &Index{CuePoints: []CuePoint{
+		{CueTime: 3000, CueTrackPositions: []CueTrackPosition{{CueTrack: 1, CueClusterPosition: 300}}},
+		{CueTime: 1000, CueTrackPositions: []CueTrackPosition{{CueTrack: 1, CueClusterPosition: 100}}},
+	}}
+	body := encodeBody(t, IdCues, func(w *ebml.Writer) error { return WriteCues(w, index) })
+	parsed, err := ParseCues(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.CuePoints[0].CueTime != 1000 || parsed.CuePoints[1].CueTime != 3000 {
+		t.Errorf("CuePoints not sorted: %+v", parsed.CuePoints)
+	}
+}
+
+func TestIndexLookup(t *testing.T) {
+	index := &Index{CuePoints: []CuePoint{
+		{CueTime: 1000, CueTrackPositions: []CueTrackPosition{{CueTrack: 1, CueClusterPosition: 100}}},
+		{CueTime: 2000, CueTrackPositions: []CueTrackPosition{{CueTrack: 2, CueClusterPosition: 200}}},
+		{CueTime: 3000, CueTrackPositions: []CueTrackPosition{{CueTrack: 1, CueClusterPosition: 300}}},
+	}}
+
+	tests := []struct {
+		timecode uint64
+		want     uint64
+	}{
+		{0, 100},
+		{1000, 100},
+		{1999, 100},
+		{2000, 200},
+		{2500, 200},
+		{3000, 300},
+		{100000, 300},
+	}
+	for _, test := range tests {
+		if got, ok := index.Lookup(test.timecode); !ok || got != test.want {
+			t.Errorf("Lookup(%d) got %d %v, want %d", test.timecode, got, ok, test.want)
+		}
+	}
+
+	if got, ok := index.LookupTrack(2500, 1); !ok || got.CueClusterPosition != 100 {
+		t.Errorf("LookupTrack(2500, 1) got %+v %v, want position 100", got, ok)
+	}
+	if got, ok := index.LookupTrack(500, 2); !ok || got.CueClusterPosition != 200 {
+		t.Errorf("LookupTrack(500, 2) got %+v %v, want position 200", got, ok)
+	}
+	if _, ok := index.LookupTrack(1000, 3); ok {
+		t.Errorf("LookupTrack() found a position for a track that isn't in the index")
+	}
+	if _, ok := (&Index{}).Lookup(0); ok {
+		t.Errorf("Lookup() found a position in an empty index")
+	}
+}
@@ -0,0 +1,383 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"errors"
+	"fmt"
+	"github.com/acolwell/mse-tools/ebml"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// File provides random access to a WebM file. Open() only reads the EBML
+// header and the Segment metadata. Cluster data is read on demand. Cues and
+// Chapters are nil if the file doesn't have them.
+type File struct {
+	Header   ebml.Header
+	Info     InfoElement
+	Tracks   []Track
+	Cues     *Index
+	Chapters *Chapters
+
+	reader         io.ReaderAt
+	segmentOffset  int64
+	segmentEnd     int64
+	readerSize     int64
+	clusterOffsets []int64
+	clustersDone   bool
+}
+
+func Open(r io.ReaderAt) (*File, error) {
+	f := &File{reader: r, segmentEnd: -1, readerSize: readerSize(r), clusterOffsets: []int64{}}
+
+	id, headerSize, size, err := ebml.ReadElementHeader(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if id != ebml.IdHeader {
+		return nil, fmt.Errorf("webm: expected EBML header, found %s", IdToName(id))
+	}
+	_, body, err := f.readElement(0)
+	if err != nil {
+		return nil, err
+	}
+	if f.Header, err = ebml.ParseHeader(body); err != nil {
+		return nil, err
+	}
+
+	segmentOffset := int64(headerSize) + size
+	id, headerSize, size, err = ebml.ReadElementHeader(r, segmentOffset)
+	if err != nil {
+		return nil, err
+	}
+	if id != IdSegment {
+		return nil, fmt.Errorf("webm: expected Segment, found %s", IdToName(id))
+	}
+	segmentOffset += int64(headerSize)
+	f.segmentOffset = segmentOffset
+	if size != -1 {
+		f.segmentEnd = segmentOffset + size
+	}
+
+	if err := f.readMetadata(); err != nil {
+		return nil, err
+	}
+
+	if f.Info == nil {
+		return nil, errors.New("webm: missing Info element")
+	}
+	if f.Tracks == nil {
+		return nil, errors.New("webm: missing Tracks element")
+	}
+	return f, nil
+}
+
+// readMetadata reads the Level 1 elements that precede the first Cluster
+// and then anything the SeekHead points to that hasn't been read yet.
+func (f *File) readMetadata() error {
+	seekPositions := map[int]int64{}
+
+	offset := f.segmentOffset
+	for {
+		id, headerSize, size, err := f.readHeader(offset)
+		if err == io.EOF {
+			f.clustersDone = true
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if id == IdCluster {
+			f.clusterOffsets = append(f.clusterOffsets, offset)
+			break
+		}
+
+		if size == -1 {
+			return fmt.Errorf("webm: unexpected unknown size for %s", IdToName(id))
+		}
+
+		switch id {
+		case IdSeekHead, IdInfo, IdTracks, IdCues, IdChapters:
+			if err := f.readLevel1Element(offset, seekPositions); err != nil {
+				return err
+			}
+		}
+		offset += int64(headerSize) + size
+	}
+
+	for _, id := range []int{IdInfo, IdTracks, IdCues, IdChapters} {
+		position, ok := seekPositions[id]
+		if !ok || (id == IdInfo && f.Info != nil) || (id == IdTracks && f.Tracks != nil) || (id == IdCues && f.Cues != nil) || (id == IdChapters && f.Chapters != nil) {
+			continue
+		}
+		if err := f.readLevel1Element(f.segmentOffset+position, seekPositions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *File) readLevel1Element(offset int64, seekPositions map[int]int64) error {
+	id, body, err := f.readElement(offset)
+	if err != nil {
+		return err
+	}
+
+	switch id {
+	case IdSeekHead:
+		seekHead, err := ParseSeekHead(body)
+		if err != nil {
+			return err
+		}
+		for i := range seekHead.Seeks {
+			seekId := seekHead.Seeks[i].ID()
+			if _, present := seekPositions[seekId]; !present {
+				seekPositions[seekId] = int64(seekHead.Seeks[i].SeekPosition)
+			}
+		}
+	case IdInfo:
+		if f.Info, err = ParseInfoElement(body); err != nil {
+			return err
+		}
+	case IdTracks:
+		if f.Tracks, err = ParseTracksElement(body); err != nil {
+			return err
+		}
+	case IdCues:
+		if f.Cues, err = ParseCues(body); err != nil {
+			return err
+		}
+	case IdChapters:
+		if f.Chapters, err = ParseChapters(body); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("webm: expected a Level 1 element at offset %d, found %s", offset, IdToName(id))
+	}
+	return nil
+}
+
+// Cluster returns the i-th Cluster in the file. io.EOF is returned if the
+// file has fewer clusters.
+func (f *File) Cluster(i int) (*ebml.Node, error) {
+	if err := f.findCluster(i); err != nil {
+		return nil, err
+	}
+	return ebml.ReadNode(f.reader, f.clusterOffsets[i], schema)
+}
+
+// SeekToTime returns the index of the Cluster that contains t. The Cues
+// are used when present, otherwise the Cluster timecodes are scanned.
+func (f *File) SeekToTime(t time.Duration) (int, error) {
+	timecode := uint64(0)
+	if t > 0 {
+		timecode = uint64(t.Nanoseconds()) / f.Info.TimecodeScale()
+	}
+
+	if f.Cues != nil {
+		if position, ok := f.Cues.Lookup(timecode); ok {
+			return f.clusterIndex(f.segmentOffset + int64(position))
+		}
+	}
+
+	index := 0
+	for i := 0; ; i++ {
+		clusterTimecode, err := f.clusterTimecode(i)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return -1, err
+		}
+		if clusterTimecode > timecode {
+			break
+		}
+		index = i
+	}
+
+	if err := f.findCluster(index); err != nil {
+		return -1, err
+	}
+	return index, nil
+}
+
+func (f *File) clusterIndex(offset int64) (int, error) {
+	for i := 0; ; i++ {
+		if err := f.findCluster(i); err != nil {
+			if err == io.EOF {
+				return -1, fmt.Errorf("webm: no Cluster at offset %d", offset)
+			}
+			return -1, err
+		}
+
+		if f.clusterOffsets[i] == offset {
+			return i, nil
+		}
+		if f.clusterOffsets[i] > offset {
+			return -1, fmt.Errorf("webm: no Cluster at offset %d", offset)
+		}
+	}
+}
+
+// clusterTimecode reads the Timecode of the i-th Cluster without reading
+// the blocks in it.
+func (f *File) clusterTimecode(i int) (uint64, error) {
+	if err := f.findCluster(i); err != nil {
+		return 0, err
+	}
+
+	_, headerSize, size, err := f.readHeader(f.clusterOffsets[i])
+	if err != nil {
+		return 0, err
+	}
+
+	offset := f.clusterOffsets[i] + int64(headerSize)
+	end := offset + size
+	for size == -1 || offset < end {
+		id, childHeaderSize, childSize, err := f.readHeader(offset)
+		if err == io.EOF || Level(id) == 1 {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if id == IdTimecode {
+			n, err := ebml.ReadNode(f.reader, offset, schema)
+			if err != nil {
+				return 0, err
+			}
+			return n.Value.(uint64), nil
+		}
+		if childSize == -1 {
+			break
+		}
+		offset += int64(childHeaderSize) + childSize
+	}
+	return 0, fmt.Errorf("webm: Cluster at offset %d has no Timecode", f.clusterOffsets[i])
+}
+
+// findCluster makes sure the offsets of the first i+1 clusters are known,
+// skipping over cluster bodies where possible.
+func (f *File) findCluster(i int) error {
+	for i >= len(f.clusterOffsets) {
+		if f.clustersDone || len(f.clusterOffsets) == 0 {
+			return io.EOF
+		}
+
+		offset, err := f.clusterEnd(f.clusterOffsets[len(f.clusterOffsets)-1])
+		if err != nil {
+			return err
+		}
+
+		for {
+			id, headerSize, size, err := f.readHeader(offset)
+			if err == io.EOF {
+				f.clustersDone = true
+				break
+			}
+			if err != nil {
+				return err
+			}
+
+			if id == IdCluster {
+				f.clusterOffsets = append(f.clusterOffsets, offset)
+				break
+			}
+			if size == -1 {
+				return fmt.Errorf("webm: unexpected unknown size for %s", IdToName(id))
+			}
+			offset += int64(headerSize) + size
+		}
+	}
+	return nil
+}
+
+// clusterEnd returns the offset just past the Cluster at offset. Clusters
+// with an unknown size have to be parsed to find their end.
+func (f *File) clusterEnd(offset int64) (int64, error) {
+	_, headerSize, size, err := f.readHeader(offset)
+	if err != nil {
+		return 0, err
+	}
+
+	if size == -1 {
+		n, err := ebml.ReadNode(f.reader, offset, schema)
+		if err != nil {
+			return 0, err
+		}
+		return offset + int64(n.HeaderSize) + n.Size, nil
+	}
+	return offset + int64(headerSize) + size, nil
+}
+
+// readHeader reads an element header inside the Segment. io.EOF is returned
+// at the end of the Segment.
+func (f *File) readHeader(offset int64) (int, int, int64, error) {
+	if f.segmentEnd != -1 && offset >= f.segmentEnd {
+		return 0, 0, 0, io.EOF
+	}
+
+	id, headerSize, size, err := ebml.ReadElementHeader(f.reader, offset)
+	if err == io.ErrUnexpectedEOF && f.segmentEnd == -1 {
+		err = io.EOF
+	}
+	return id, headerSize, size, err
+}
+
+// readerSize returns the size of r or -1 if it can't be determined.
+func readerSize(r io.ReaderAt) int64 {
+	switch v := r.(type) {
+	case interface{ Size() int64 }:
+		return v.Size()
+	case *os.File:
+		if info, err := v.Stat(); err == nil && info.Mode().IsRegular() {
+			return info.Size()
+		}
+	}
+	return -1
+}
+
+// readElement returns the ID and body of the element at offset. The
+// declared size is checked against the end of the Segment and the reader
+// so a corrupt size can't cause a huge allocation.
+func (f *File) readElement(offset int64) (int, []byte, error) {
+	id, headerSize, size, err := ebml.ReadElementHeader(f.reader, offset)
+	if err != nil {
+		return 0, nil, err
+	}
+	if size == -1 {
+		return 0, nil, fmt.Errorf("webm: unexpected unknown size for %s", IdToName(id))
+	}
+
+	bodyOffset := offset + int64(headerSize)
+	if (f.segmentEnd != -1 && size > f.segmentEnd-bodyOffset) || (f.readerSize != -1 && size > f.readerSize-bodyOffset) {
+		return 0, nil, fmt.Errorf("webm: %w: %s at offset %d has size %d", ebml.ErrSizeOverflow, IdToName(id), offset, size)
+	}
+
+	// The body is read in pieces in case neither bound is known.
+	body, err := ioutil.ReadAll(io.NewSectionReader(f.reader, bodyOffset, size))
+	if err != nil {
+		return 0, nil, err
+	}
+	if int64(len(body)) < size {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	return id, body, nil
+}
@@ -0,0 +1,325 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"bytes"
+	"errors"
+	"github.com/acolwell/mse-tools/ebml"
+	"io"
+	"testing"
+	"time"
+)
+
+// testFile describes the layout of a file written by writeTestFile. The
+// file has a SeekHead, an Info and three Clusters with Timecodes 0, 1000 and
+// 2000 at the default TimecodeScale.
+type testFile struct {
+	unknownSegmentSize  bool
+	unknownClusterSizes bool
+	metadataAfterCues   bool // Tracks after the Clusters and Cues
+	cues                bool
+	noInfo              bool
+	noTracks            bool
+}
+
+// seekHeadSize is the space reserved for the SeekHead at the start of the
+// Segment data.
+const seekHeadSize = 100
+
+// writeTestFile returns the file described by f and the offsets of its
+// Clusters.
+func writeTestFile(t *testing.T, f testFile) ([]byte, []int64) {
+	bw := ebml.NewBufferWriter(4096)
+	w := ebml.NewWriter(bw)
+	seekHead := &SeekHead{}
+
+	// The body is written without the SeekHead so offsets in bw are
+	// seekHeadSize bytes before their Segment positions.
+	position := func() uint64 { return uint64(seekHeadSize + w.Offset()) }
+
+	if !f.noInfo {
+		seekHead.Add(IdInfo, position())
+		check(t, w.WriteListStart(IdInfo))
+		writeElement(t, w, IdTimecodeScale, uint64(1000000))
+		writeElement(t, w, IdDuration, 3000.0)
+		check(t, w.WriteListEnd(IdInfo))
+	}
+
+	writeTracks := func() {
+		if f.noTracks {
+			return
+		}
+		seekHead.Add(IdTracks, position())
+		check(t, WriteTracksElement(w, []*TrackEntry{{TrackNumber: 1, TrackType: uint64(VIDEO_TRACK), CodecID: "V_VP8"}}))
+	}
+	if !f.metadataAfterCues {
+		writeTracks()
+	}
+
+	clusters := []int64{}
+	for i := 0; i < 3; i++ {
+		clusters = append(clusters, seekHeadSize+w.Offset())
+		block, err := EncodeBlock(1, 0, 0x80, [][]byte{{byte(i)}})
+		check(t, err)
+		if f.unknownClusterSizes {
+			_, err = w.WriteUnknownSizeHeader(IdCluster)
+			check(t, err)
+		} else {
+			check(t, w.WriteListStart(IdCluster))
+		}
+		writeElement(t, w, IdTimecode, uint64(i*1000))
+		writeElement(t, w, IdSimpleBlock, block)
+		if !f.unknownClusterSizes {
+			check(t, w.WriteListEnd(IdCluster))
+		}
+	}
+
+	if f.cues {
+		index := &Index{}
+		for i, offset := range clusters {
+			index.CuePoints = append(index.CuePoints, CuePoint{CueTime: uint64(i * 1000),
+				CueTrackPositions: []CueTrackPosition{{CueTrack: 1, CueClusterPosition: uint64(offset)}}})
+		}
+		seekHead.Add(IdCues, position())
+		check(t, WriteCues(w, index))
+	}
+	if f.metadataAfterCues {
+		writeTracks()
+	}
+
+	sw := ebml.NewBufferWriter(seekHeadSize)
+	check(t, seekHead.Write(ebml.NewWriter(sw), seekHeadSize))
+	body := append(sw.Bytes(), bw.Bytes()...)
+
+	fw := ebml.NewBufferWriter(4096)
+	w = ebml.NewWriter(fw)
+	_, err := WriteHeader(w)
+	check(t, err)
+	if f.unknownSegmentSize {
+		_, err = w.WriteUnknownSizeHeader(IdSegment)
+	} else {
+		_, err = w.WriteElementHeader(IdSegment, int64(len(body)), 8)
+	}
+	check(t, err)
+	segmentOffset := w.Offset()
+	_, err = w.WriteToOutput(body)
+	check(t, err)
+
+	for i := range clusters {
+		clusters[i] += segmentOffset
+	}
+	return fw.Bytes(), clusters
+}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeElement(t *testing.T, w *ebml.Writer, id int, value interface{}) {
+	t.Helper()
+	if _, err := w.Write(id, value); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readerAt hides the Size() method of the reader it wraps.
+type readerAt struct {
+	r io.ReaderAt
+}
+
+func (r readerAt) ReadAt(p []byte, offset int64) (int, error) {
+	return r.r.ReadAt(p, offset)
+}
+
+var testFileLayouts = map[string]testFile{
+	"known sizes":           {cues: true},
+	"unknown Segment size":  {unknownSegmentSize: true, cues: true},
+	"unknown Cluster sizes": {unknownSegmentSize: true, unknownClusterSizes: true},
+	"metadata after Cues":   {metadataAfterCues: true, cues: true},
+	"no Cues":               {},
+}
+
+func TestOpen(t *testing.T) {
+	for name, layout := range testFileLayouts {
+		data, _ := writeTestFile(t, layout)
+		f, err := Open(bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		if f.Header.DocType() != "webm" {
+			t.Errorf("%s: got DocType %q", name, f.Header.DocType())
+		}
+		if f.Info.TimecodeScale() != 1000000 || f.Info.Duration() != 3000 {
+			t.Errorf("%s: got TimecodeScale %d and Duration %f", name, f.Info.TimecodeScale(), f.Info.Duration())
+		}
+		if len(f.Tracks) != 1 || f.Tracks[0].ID() != 1 || f.Tracks[0].CodecID() != "V_VP8" {
+			t.Errorf("%s: got Tracks %+v", name, f.Tracks)
+		}
+		if (f.Cues != nil) != layout.cues {
+			t.Errorf("%s: got Cues %+v", name, f.Cues)
+		} else if f.Cues != nil && len(f.Cues.CuePoints) != 3 {
+			t.Errorf("%s: got %d CuePoints, want 3", name, len(f.Cues.CuePoints))
+		}
+		if f.Chapters != nil {
+			t.Errorf("%s: got Chapters %+v", name, f.Chapters)
+		}
+	}
+}
+
+func TestFileCluster(t *testing.T) {
+	for name, layout := range testFileLayouts {
+		data, offsets := writeTestFile(t, layout)
+		for _, r := range []io.ReaderAt{bytes.NewReader(data), readerAt{bytes.NewReader(data)}} {
+			f, err := Open(r)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			// Read the last Cluster first so the others are found by
+			// skipping over Cluster bodies.
+			for _, i := range []int{2, 0, 1} {
+				cluster, err := f.Cluster(i)
+				if err != nil {
+					t.Errorf("%s: Cluster(%d) failed: %v", name, i, err)
+					continue
+				}
+				if cluster.ID != IdCluster || cluster.Offset != offsets[i] {
+					t.Errorf("%s: Cluster(%d) got %s at %d, want a Cluster at %d", name, i, IdToName(cluster.ID), cluster.Offset, offsets[i])
+				}
+				if n := cluster.Lookup(IdTimecode); n == nil || n.Value != uint64(i*1000) {
+					t.Errorf("%s: Cluster(%d) got Timecode %+v", name, i, n)
+				}
+				if blocks := cluster.ChildrenWithID(IdSimpleBlock); len(blocks) != 1 {
+					t.Errorf("%s: Cluster(%d) got %d SimpleBlocks, want 1", name, i, len(blocks))
+				}
+			}
+
+			if _, err := f.Cluster(3); err != io.EOF {
+				t.Errorf("%s: Cluster(3) got %v, want io.EOF", name, err)
+			}
+		}
+	}
+}
+
+func TestFileSeekToTime(t *testing.T) {
+	tests := []struct {
+		t    time.Duration
+		want int
+	}{
+		{-time.Second, 0},
+		{0, 0},
+		{999 * time.Millisecond, 0},
+		{time.Second, 1},
+		{2500 * time.Millisecond, 2},
+		{time.Hour, 2},
+	}
+
+	for name, layout := range testFileLayouts {
+		data, _ := writeTestFile(t, layout)
+		f, err := Open(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for _, test := range tests {
+			if i, err := f.SeekToTime(test.t); err != nil || i != test.want {
+				t.Errorf("%s: SeekToTime(%v) got %d, %v, want %d", name, test.t, i, err, test.want)
+			}
+		}
+	}
+
+	// A CuePoint that doesn't point at a Cluster is reported.
+	data, offsets := writeTestFile(t, testFile{cues: true})
+	f, err := Open(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Cues.CuePoints[1].CueTrackPositions[0].CueClusterPosition++
+	if _, err := f.SeekToTime(time.Second); err == nil {
+		t.Errorf("SeekToTime() succeeded with a CuePoint inside the Cluster at %d", offsets[1])
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	valid, offsets := writeTestFile(t, testFile{})
+	tracksOffset := bytes.Index(valid, []byte{0x16, 0x54, 0xAE, 0x6B})
+	infoOffset := bytes.Index(valid, []byte{0x15, 0x49, 0xA9, 0x66})
+
+	// withSize returns valid with the 1 byte size of the element at offset
+	// replaced by size.
+	withSize := func(offset int, size byte) []byte {
+		data := append([]byte{}, valid...)
+		data[offset+4] = 0x80 | size
+		return data
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		hideLen bool
+		want    error
+	}{
+		{"empty", []byte{}, false, io.EOF},
+		{"no EBML header", valid[bytes.Index(valid, []byte{0x18, 0x53, 0x80, 0x67}):], false, nil},
+		{"truncated EBML header", valid[:10], false, ebml.ErrSizeOverflow},
+		{"truncated EBML header without a size", valid[:10], true, io.ErrUnexpectedEOF},
+		{"truncated Info", valid[:infoOffset+8], false, ebml.ErrSizeOverflow},
+		{"truncated Info without a size", valid[:infoOffset+8], true, io.ErrUnexpectedEOF},
+		{"Tracks larger than the Segment", withSize(tracksOffset, 0x7E), false, ebml.ErrSizeOverflow},
+		{"Tracks larger than the Segment without a size", withSize(tracksOffset, 0x7E), true, ebml.ErrSizeOverflow},
+		{"no Info", mustWriteTestFile(t, testFile{noInfo: true}), false, nil},
+		{"no Tracks", mustWriteTestFile(t, testFile{noTracks: true}), false, nil},
+	}
+
+	for _, test := range tests {
+		var r io.ReaderAt = bytes.NewReader(test.data)
+		if test.hideLen {
+			r = readerAt{r}
+		}
+		f, err := Open(r)
+		if err == nil {
+			t.Errorf("%s: Open() succeeded", test.name)
+			continue
+		}
+		if f != nil {
+			t.Errorf("%s: Open() returned a File and %v", test.name, err)
+		}
+		if test.want != nil && !errors.Is(err, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.want)
+		}
+	}
+
+	// A file that ends inside a Cluster opens but the Cluster can't be read.
+	f, err := Open(bytes.NewReader(valid[:offsets[2]+8]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Cluster(1); err != nil {
+		t.Errorf("Cluster(1) failed: %v", err)
+	}
+	if _, err := f.Cluster(2); err == nil {
+		t.Error("Cluster(2) succeeded for a truncated Cluster")
+	}
+}
+
+func mustWriteTestFile(t *testing.T, f testFile) []byte {
+	data, _ := writeTestFile(t, f)
+	return data
+}
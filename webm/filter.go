@@ -16,6 +16,10 @@ package webm
 
 import "github.com/acolwell/mse-tools/ebml"
 
-func Filter(input []byte, ids []int) []byte {
-	return ebml.Filter(input, ids, IdTypes(), UnknownSizeInfo())
+func Filter(input []byte, ids []int) ([]byte, error) {
+	return ebml.Filter(input, ids, schema)
+}
+
+func Transform(input []byte, funcs map[int]ebml.TransformFunc) ([]byte, error) {
+	return ebml.Transform(input, funcs, schema)
 }
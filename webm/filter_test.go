@@ -0,0 +1,67 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"bytes"
+	"github.com/acolwell/mse-tools/ebml"
+	"reflect"
+	"testing"
+)
+
+// TestFilterFrameRate checks the filtering mse_webm_remuxer does to the
+// body of the Tracks element.
+func TestFilterFrameRate(t *testing.T) {
+	entries := []*TrackEntry{
+		{TrackNumber: 1, TrackUID: 11, TrackType: uint64(VIDEO_TRACK), CodecID: "V_VP9",
+			Video: &VideoSettings{PixelWidth: 640, PixelHeight: 360, FrameRate: 29.97,
+				Projection: &Projection{ProjectionType: 1, ProjectionPoseYaw: 90}}},
+		{TrackNumber: 2, TrackUID: 12, TrackType: uint64(AUDIO_TRACK), CodecID: "A_OPUS", CodecDelay: 6500000,
+			Audio: &AudioSettings{SamplingFrequency: 48000, Channels: 2}},
+	}
+	body := encodeBody(t, IdTracks, func(w *ebml.Writer) error { return WriteTracksElement(w, entries) })
+	frameRate := []byte{0x23, 0x83, 0xE3}
+	if !bytes.Contains(body, frameRate) {
+		t.Fatal("the test Tracks don't have a FrameRate")
+	}
+
+	filtered, err := Filter(body, []int{IdFrameRate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(filtered, frameRate) {
+		t.Errorf("FrameRate wasn't removed from %X", filtered)
+	}
+
+	tracks, err := ParseTracksElement(filtered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != len(entries) {
+		t.Fatalf("got %d tracks, want %d", len(tracks), len(entries))
+	}
+	entries[0].Video.FrameRate = 0
+	for i, track := range tracks {
+		want, err := ParseTracksElement(encodeBody(t, IdTracks, func(w *ebml.Writer) error {
+			return WriteTracksElement(w, entries[i:i+1])
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(track.Entry(), want[0].Entry()) {
+			t.Errorf("got %+v, want %+v", track.Entry(), want[0].Entry())
+		}
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build ignore
+// +build ignore
+
+// gen_ids generates ids.go from the Matroska EBML schema.
+//
+// Usage: go run gen_ids.go [-schema ebml_matroska.xml] [-output ids.go]
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type schemaElement struct {
+	Name               string `xml:"name,attr"`
+	Path               string `xml:"path,attr"`
+	ID                 string `xml:"id,attr"`
+	Type               string `xml:"type,attr"`
+	UnknownSizeAllowed bool   `xml:"unknownsizeallowed,attr"`
+	Recursive          bool   `xml:"recursive,attr"`
+}
+
+type schema struct {
+	DocType  string          `xml:"docType,attr"`
+	Elements []schemaElement `xml:"element"`
+}
+
+type element struct {
+	name               string
+	id                 int64
+	elementType        string
+	parent             string
+	recursive          bool
+	global             bool
+	unknownSizeAllowed bool
+}
+
+// legacyNames maps element names from the specification to the names this
+// package used before the elements were renamed upstream.
+var legacyNames = map[string]string{
+	"SegmentUUID":         "SegmentUID",
+	"PrevUUID":            "PrevUID",
+	"NextUUID":            "NextUID",
+	"TimestampScale":      "TimecodeScale",
+	"Timestamp":           "Timecode",
+	"TrackTimestampScale": "TrackTimecodeScale",
+	"MaxBlockAdditionID":  "MaxBlockAdditionId",
+	"UncompressedFourCC":  "ColorSpace",
+	"TrackJoinBlocks":     "JoinBlocks",
+	"FileMediaType":       "FileMimeType",
+	"ChapterSegmentUUID":  "ChapterSegmentUID",
+	"ChapterTrackUID":     "ChapterTrackNumber",
+}
+
+// globalOccurrence matches the occurrence range inside a global
+// placeholder.
+var globalOccurrence = regexp.MustCompile(`^[0-9]*-[0-9]*$`)
+
+var elementTypes = map[string]string{
+	"master":   "ebml.TypeList",
+	"uinteger": "ebml.TypeUint",
+	"integer":  "ebml.TypeInt",
+	"float":    "ebml.TypeFloat",
+	"string":   "ebml.TypeString",
+	"utf-8":    "ebml.TypeUTF8",
+	"binary":   "ebml.TypeBinary",
+	"date":     "ebml.TypeDate",
+}
+
+// parsePath splits an RFC 8794 element path into the names of the element
+// and its ancestors. A + before the last name marks a recursive element and
+// a global placeholder like (1-\) marks an element that can occur at any
+// depth below the ancestors that precede it.
+func parsePath(path string) (parts []string, recursive bool, global bool, err error) {
+	invalid := fmt.Errorf("unsupported path %s", path)
+	if !strings.HasPrefix(path, "\\") {
+		return nil, false, false, invalid
+	}
+
+	globalIndex := -1
+	rest := path[1:]
+	for rest != "" {
+		if strings.HasPrefix(rest, "(") {
+			end := strings.Index(rest, "\\)")
+			if end == -1 || globalIndex != -1 || !globalOccurrence.MatchString(rest[1:end]) {
+				return nil, false, false, invalid
+			}
+			globalIndex = len(parts)
+			rest = rest[end+2:]
+			continue
+		}
+
+		part := rest
+		if i := strings.Index(rest, "\\"); i != -1 {
+			part, rest = rest[:i], rest[i+1:]
+			if rest == "" {
+				return nil, false, false, invalid
+			}
+		} else {
+			rest = ""
+		}
+
+		// Only the last part is the element itself, the others are its
+		// ancestors.
+		recursive = strings.HasPrefix(part, "+")
+		part = strings.TrimPrefix(part, "+")
+		if part == "" || strings.ContainsAny(part, "()+") {
+			return nil, false, false, invalid
+		}
+		parts = append(parts, part)
+	}
+
+	// The placeholder has to be directly in front of the element.
+	if len(parts) == 0 || (globalIndex != -1 && globalIndex != len(parts)-1) {
+		return nil, false, false, invalid
+	}
+	return parts, recursive, globalIndex != -1, nil
+}
+
+func loadElements(filename string) ([]*element, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	s := schema{}
+	if err := xml.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	elements := []*element{}
+	for _, se := range s.Elements {
+		id, err := strconv.ParseInt(se.ID, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %s for %s", se.ID, se.Name)
+		}
+
+		elementType, ok := elementTypes[se.Type]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %s for %s", se.Type, se.Name)
+		}
+
+		parts, recursive, global, err := parsePath(se.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		// The EBML header elements are restated to constrain their values
+		// but are defined by the ebml package.
+		if parts[0] == "EBML" {
+			continue
+		}
+
+		name := se.Name
+		if legacyName, ok := legacyNames[name]; ok {
+			name = legacyName
+		}
+
+		parent := ""
+		if len(parts) > 1 {
+			parent = parts[len(parts)-2]
+			if legacyName, ok := legacyNames[parent]; ok {
+				parent = legacyName
+			}
+		}
+
+		elements = append(elements, &element{
+			name:               name,
+			id:                 id,
+			elementType:        elementType,
+			parent:             parent,
+			recursive:          recursive || se.Recursive,
+			global:             global,
+			unknownSizeAllowed: se.UnknownSizeAllowed,
+		})
+	}
+	return elements, nil
+}
+
+// constName returns the name of the ID constant for an element. Characters
+// that aren't allowed in Go identifiers, like the - in CRC-32, are dropped.
+func constName(name string) string {
+	if name == "" {
+		return "-1"
+	}
+	return "Id" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return -1
+	}, name)
+}
+
+func generate(elements []*element, schemaFile string) ([]byte, error) {
+	byName := map[string]*element{}
+	for _, e := range elements {
+		byName[e.name] = e
+	}
+	for _, e := range elements {
+		if e.parent != "" && byName[e.parent] == nil {
+			return nil, fmt.Errorf("unknown parent %s for %s", e.parent, e.name)
+		}
+	}
+
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, `// Copyright 2012 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gen_ids.go from %s. DO NOT EDIT.
+
+//go:generate go run gen_ids.go
+
+package webm
+
+import "github.com/acolwell/mse-tools/ebml"
+
+`, schemaFile)
+
+	fmt.Fprintf(b, "const (\n")
+	for _, e := range elements {
+		fmt.Fprintf(b, "%s = 0x%X\n", constName(e.name), e.id)
+	}
+	fmt.Fprintf(b, ")\n\n")
+
+	fmt.Fprintf(b, "var elementDefs = []ebml.ElementDef{\n")
+	for _, e := range elements {
+		fmt.Fprintf(b, "{ID: %s, Name: %q, Type: %s, Parent: %s", constName(e.name), e.name, e.elementType, constName(e.parent))
+		if e.unknownSizeAllowed {
+			fmt.Fprintf(b, ", UnknownSizeAllowed: true")
+		}
+		if e.recursive {
+			fmt.Fprintf(b, ", Recursive: true")
+		}
+		if e.global {
+			fmt.Fprintf(b, ", Global: true")
+		}
+		fmt.Fprintf(b, "},\n")
+	}
+	fmt.Fprintf(b, "}\n")
+
+	return format.Source(b.Bytes())
+}
+
+func main() {
+	schemaFile := flag.String("schema", "ebml_matroska.xml", "Matroska EBML schema")
+	output := flag.String("output", "ids.go", "Output file")
+	flag.Parse()
+
+	elements, err := loadElements(*schemaFile)
+	if err != nil {
+		log.Fatalf("Failed to load %s: %s", *schemaFile, err.Error())
+	}
+
+	src, err := generate(elements, *schemaFile)
+	if err != nil {
+		log.Fatalf("Failed to generate %s: %s", *output, err.Error())
+	}
+
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		log.Fatalf("Failed to write %s: %s", *output, err.Error())
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// runGenIDs runs gen_ids.go on schemaFile and returns the generated source
+// or the output of the failed run.
+func runGenIDs(t *testing.T, schemaFile string) ([]byte, string, error) {
+	if testing.Short() {
+		t.Skip("skipping go run in short mode")
+	}
+	goTool := filepath.Join(runtime.GOROOT(), "bin", "go")
+	if _, err := os.Stat(goTool); err != nil {
+		t.Skipf("go tool not found: %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "gen_ids")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	output := filepath.Join(dir, "ids.go")
+	cmd := exec.Command(goTool, "run", "gen_ids.go", "-schema", schemaFile, "-output", output)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, string(out), err
+	}
+	src, err := ioutil.ReadFile(output)
+	return src, "", err
+}
+
+func TestGenIDsUpToDate(t *testing.T) {
+	src, out, err := runGenIDs(t, "ebml_matroska.xml")
+	if err != nil {
+		t.Fatalf("gen_ids.go failed: %v\n%s", err, out)
+	}
+	current, err := ioutil.ReadFile("ids.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, current) {
+		t.Error("ids.go is out of date, run go generate")
+	}
+}
+
+func TestGenIDsSchemaFormat(t *testing.T) {
+	src, out, err := runGenIDs(t, filepath.Join("testdata", "gen_ids_schema.xml"))
+	if err != nil {
+		t.Fatalf("gen_ids.go failed: %v\n%s", err, out)
+	}
+
+	for _, want := range []string{
+		`{ID: IdVoid, Name: "Void", Type: ebml.TypeBinary, Parent: -1, Global: true},`,
+		`{ID: IdCRC32, Name: "CRC-32", Type: ebml.TypeBinary, Parent: -1, Global: true},`,
+		`{ID: IdSegment, Name: "Segment", Type: ebml.TypeList, Parent: -1, UnknownSizeAllowed: true},`,
+		`{ID: IdTimecodeScale, Name: "TimecodeScale", Type: ebml.TypeUint, Parent: IdInfo},`,
+		`{ID: IdTrackType, Name: "TrackType", Type: ebml.TypeUint, Parent: IdTrackEntry},`,
+		`{ID: IdChapterAtom, Name: "ChapterAtom", Type: ebml.TypeList, Parent: IdEditionEntry, Recursive: true},`,
+		`{ID: IdChapterDisplay, Name: "ChapterDisplay", Type: ebml.TypeList, Parent: IdChapterAtom},`,
+		`{ID: IdChapString, Name: "ChapString", Type: ebml.TypeUTF8, Parent: IdChapterDisplay},`,
+		`{ID: IdChapterTimeEnd, Name: "ChapterTimeEnd", Type: ebml.TypeUint, Parent: IdChapterAtom},`,
+		`{ID: IdDateUTC, Name: "DateUTC", Type: ebml.TypeDate, Parent: IdInfo},`,
+		`{ID: IdDuration, Name: "Duration", Type: ebml.TypeFloat, Parent: IdInfo},`,
+	} {
+		if !bytes.Contains(src, []byte(want)) {
+			t.Errorf("output doesn't contain %s", want)
+		}
+	}
+
+	// The EBML header constraints are left to the ebml package.
+	if bytes.Contains(src, []byte("EBMLMaxIDLength")) {
+		t.Error("output contains the EBML header constraints")
+	}
+}
+
+func TestGenIDsInvalidPaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gen_ids_schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, path := range []string{
+		`Segment`,
+		`\`,
+		`\Segment\`,
+		`\Segment\\Info`,
+		`\Segment\(x\)Void`,
+		`\Segment\(1-Void`,
+		`\(-\)(-\)Void`,
+		`\(-\)Segment\Void`,
+		`\Segment\Inf+o`,
+	} {
+		schemaFile := filepath.Join(dir, "schema.xml")
+		schema := `<EBMLSchema docType="matroska"><element name="Void" path="` + path + `" id="0xEC" type="binary"/></EBMLSchema>`
+		if err := ioutil.WriteFile(schemaFile, []byte(schema), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, out, err := runGenIDs(t, schemaFile)
+		if err == nil {
+			t.Errorf("gen_ids.go accepted path %s", path)
+		} else if !strings.Contains(out, "unsupported path") {
+			t.Errorf("got %q for path %s", out, path)
+		}
+	}
+}
@@ -12,502 +12,524 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// Code generated by gen_ids.go from ebml_matroska.xml. DO NOT EDIT.
+
+//go:generate go run gen_ids.go
+
 package webm
 
 import "github.com/acolwell/mse-tools/ebml"
 
 const (
-	IdSegment                    = 0x18538067
-	IdSeekHead                   = 0x114D9B74
-	IdSeek                       = 0x4DBB
-	IdSeekID                     = 0x53AB
-	IdSeekPosition               = 0x53AC
-	IdInfo                       = 0x1549A966
-	IdSegmentUID                 = 0x73A4
-	IdSegmentFilename            = 0x7384
-	IdPrevUID                    = 0x3CB923
-	IdPrevFilename               = 0x3C83AB
-	IdNextUID                    = 0x3EB923
-	IdNextFilename               = 0x3E83BB
-	IdSegmentFamily              = 0x4444
-	IdChapterTranslate           = 0x6924
-	IdChapterTranslateEditionUID = 0x69FC
-	IdChapterTranslateCodec      = 0x69BF
-	IdChapterTranslateID         = 0x69A5
-	IdTimecodeScale              = 0x2AD7B1
-	IdDuration                   = 0x4489
-	IdDateUTC                    = 0x4461
-	IdTitle                      = 0x7BA9
-	IdMuxingApp                  = 0x4D80
-	IdWritingApp                 = 0x5741
-	IdCluster                    = 0x1F43B675
-	IdTimecode                   = 0xE7
-	IdSilentTracks               = 0x5854
-	IdSilentTrackNumber          = 0x58D7
-	IdPosition                   = 0xA7
-	IdPrevSize                   = 0xAB
-	IdSimpleBlock                = 0xA3
-	IdBlockGroup                 = 0xA0
-	IdBlock                      = 0xA1
-	IdBlockAdditions             = 0x75A1
-	IdBlockMore                  = 0xA6
-	IdBlockAddID                 = 0xEE
-	IdBlockAdditional            = 0xA5
-	IdBlockDuration              = 0x9B
-	IdReferencePriority          = 0xFA
-	IdReferenceBlock             = 0xFB
-	IdCodecState                 = 0xA4
-	IdDiscardPadding             = 0x75A2
-	IdSlices                     = 0x8E
-	IdTimeSlice                  = 0xE8
-	IdLaceNumber                 = 0xCC
-	IdTracks                     = 0x1654AE6B
-	IdTrackEntry                 = 0xAE
-	IdTrackNumber                = 0xD7
-	IdTrackUID                   = 0x73C5
-	IdTrackType                  = 0x83
-	IdFlagEnabled                = 0xB9
-	IdFlagDefault                = 0x88
-	IdFlagForced                 = 0x55AA
-	IdFlagLacing                 = 0x9C
-	IdMinCache                   = 0x6DE7
-	IdMaxCache                   = 0x6DF8
-	IdDefaultDuration            = 0x23E383
-	IdTrackTimecodeScale         = 0x23314F
-	IdMaxBlockAdditionId         = 0x55EE
-	IdName                       = 0x536E
-	IdLanguage                   = 0x22B59C
-	IdCodecID                    = 0x86
-	IdCodecPrivate               = 0x63A2
-	IdCodecName                  = 0x258688
-	IdAttachmentLink             = 0x7446
-	IdCodecDecodeAll             = 0xAA
-	IdTrackOverlay               = 0x6FAB
-	IdCodecDelay                 = 0x56AA
-	IdSeekPreRoll                = 0x56BB
-	IdTrackTranslate             = 0x6624
-	IdTrackTranslateEditionUID   = 0x66FC
-	IdTrackTranslateCodec        = 0x66BF
-	IdTrackTranslateTrackID      = 0x66A5
-	IdVideo                      = 0xE0
-	IdFlagInterlaced             = 0x9A
-	IdStereoMode                 = 0x53B8
-	IdAlphaMode                  = 0x53C0
-	IdPixelWidth                 = 0xB0
-	IdPixelHeight                = 0xBA
-	IdPixelCropBottom            = 0x54AA
-	IdPixelCropTop               = 0x54BB
-	IdPixelCropLeft              = 0x54CC
-	IdPixelCropRight             = 0x54DD
-	IdDisplayWidth               = 0x54B0
-	IdDisplayHeight              = 0x54BA
-	IdDisplayUnit                = 0x54B2
-	IdAspectRatioType            = 0x54B3
-	IdColorSpace                 = 0x2EB524
-	IdFrameRate                  = 0x2383E3
-	IdProjection                 = 0x7670
-	IdProjectionType             = 0x7671
-	IdProjectionPrivate          = 0x7672
-	IdProjectionPoseYaw          = 0x7673
-	IdProjectionPosePitch        = 0x7674
-	IdProjectionPoseRoll         = 0x7675
-	IdAudio                      = 0xE1
-	IdSamplingFrequency          = 0xB5
-	IdOutputSamplingFrequency    = 0x78B5
-	IdChannels                   = 0x9F
-	IdBitDepth                   = 0x6264
-	IdTrackOperation             = 0xE2
-	IdTrackCombinePlanes         = 0xE3
-	IdTrackPlane                 = 0xE4
-	IdTrackPlaneUID              = 0xE5
-	IdTrackPlaneType             = 0xE6
-	IdJoinBlocks                 = 0xE9
-	IdTrackJoinUID               = 0xED
-	IdContentEncodings           = 0x6D80
-	IdContentEncoding            = 0x6240
-	IdContentEncodingOrder       = 0x5031
-	IdContentEncodingScope       = 0x5032
-	IdContentEncodingType        = 0x5033
-	IdContentCompression         = 0x5034
-	IdContentCompAlgo            = 0x4254
-	IdContentCompSettings        = 0x4255
-	IdContentEncryption          = 0x5035
-	IdContentEncAlgo             = 0x47E1
-	IdContentEncKeyID            = 0x47E2
-	IdContentSignature           = 0x47E3
-	IdContentSigKeyID            = 0x47E4
-	IdContentSigAlgo             = 0x47E5
-	IdContentSigHashAlgo         = 0x47E6
-	IdCues                       = 0x1C53BB6B
-	IdCuePoint                   = 0xBB
-	IdCueTime                    = 0xB3
-	IdCueTrackPositions          = 0xB7
-	IdCueTrack                   = 0xF7
-	IdCueClusterPosition         = 0xF1
-	IdCueRelativePosition        = 0xF0
-	IdCueBlockNumber             = 0x5378
-	IdCueCodecState              = 0xEA
-	IdCueReference               = 0xDB
-	IdCueRefTime                 = 0x96
-	IdAttachments                = 0x1941A469
-	IdAttachedFile               = 0x61A7
-	IdFileDescription            = 0x467E
-	IdFileName                   = 0x466E
-	IdFileMimeType               = 0x4660
-	IdFileData                   = 0x465C
-	IdFileUID                    = 0x46AE
-	IdChapters                   = 0x1043A770
-	IdEditionEntry               = 0x45B9
-	IdEditionUID                 = 0x45BC
-	IdEditionFlagHidden          = 0x45BD
-	IdEditionFlagDefault         = 0x45DB
-	IdEditionFlagOrdered         = 0x45DD
-	IdChapterAtom                = 0xB6
-	IdChapterUID                 = 0x73C4
-	IdChapterTimeStart           = 0x91
-	IdChapterTimeEnd             = 0x92
-	IdChapterFlagHidden          = 0x98
-	IdChapterFlagEnabled         = 0x4598
-	IdChapterSegmentUID          = 0x6E67
-	IdChapterSegmentEditionUID   = 0x6EBC
-	IdChapterPhysicalEquiv       = 0x63C3
-	IdChapterTrack               = 0x8F
-	IdChapterTrackNumber         = 0x89
-	IdChapterDisplay             = 0x80
-	IdChapString                 = 0x85
-	IdChapLanguage               = 0x437C
-	IdChapCountry                = 0x437E
-	IdChapProcess                = 0x6944
-	IdChapProcessCodecID         = 0x6955
-	IdChapProcessPrivate         = 0x450D
-	IdChapProcessCommand         = 0x6911
-	IdChapProcessTime            = 0x6922
-	IdChapProcessData            = 0x6933
-	IdTags                       = 0x1254C367
-	IdTag                        = 0x7373
-	IdTargets                    = 0x63C0
-	IdTargetTypeValue            = 0x68CA
-	IdTargetType                 = 0x63CA
-	IdTagTrackUID                = 0x63C5
-	IdTagEditionUID              = 0x63C9
-	IdTagChapterUID              = 0x63C4
-	IdTagAttachmentUID           = 0x63C6
-	IdSimpleTag                  = 0x67C8
-	IdTagName                    = 0x45A3
-	IdTagLanguage                = 0x447A
-	IdTagDefault                 = 0x4484
-	IdTagString                  = 0x4487
-	IdTagBinary                  = 0x4485
+	IdSegment                     = 0x18538067
+	IdSeekHead                    = 0x114D9B74
+	IdSeek                        = 0x4DBB
+	IdSeekID                      = 0x53AB
+	IdSeekPosition                = 0x53AC
+	IdInfo                        = 0x1549A966
+	IdSegmentUID                  = 0x73A4
+	IdSegmentFilename             = 0x7384
+	IdPrevUID                     = 0x3CB923
+	IdPrevFilename                = 0x3C83AB
+	IdNextUID                     = 0x3EB923
+	IdNextFilename                = 0x3E83BB
+	IdSegmentFamily               = 0x4444
+	IdChapterTranslate            = 0x6924
+	IdChapterTranslateID          = 0x69A5
+	IdChapterTranslateCodec       = 0x69BF
+	IdChapterTranslateEditionUID  = 0x69FC
+	IdTimecodeScale               = 0x2AD7B1
+	IdDuration                    = 0x4489
+	IdDateUTC                     = 0x4461
+	IdTitle                       = 0x7BA9
+	IdMuxingApp                   = 0x4D80
+	IdWritingApp                  = 0x5741
+	IdCluster                     = 0x1F43B675
+	IdTimecode                    = 0xE7
+	IdSilentTracks                = 0x5854
+	IdSilentTrackNumber           = 0x58D7
+	IdPosition                    = 0xA7
+	IdPrevSize                    = 0xAB
+	IdSimpleBlock                 = 0xA3
+	IdBlockGroup                  = 0xA0
+	IdBlock                       = 0xA1
+	IdBlockVirtual                = 0xA2
+	IdBlockAdditions              = 0x75A1
+	IdBlockMore                   = 0xA6
+	IdBlockAdditional             = 0xA5
+	IdBlockAddID                  = 0xEE
+	IdBlockDuration               = 0x9B
+	IdReferencePriority           = 0xFA
+	IdReferenceBlock              = 0xFB
+	IdReferenceVirtual            = 0xFD
+	IdCodecState                  = 0xA4
+	IdDiscardPadding              = 0x75A2
+	IdSlices                      = 0x8E
+	IdTimeSlice                   = 0xE8
+	IdLaceNumber                  = 0xCC
+	IdFrameNumber                 = 0xCD
+	IdBlockAdditionID             = 0xCB
+	IdDelay                       = 0xCE
+	IdSliceDuration               = 0xCF
+	IdReferenceFrame              = 0xC8
+	IdReferenceOffset             = 0xC9
+	IdReferenceTimestamp          = 0xCA
+	IdEncryptedBlock              = 0xAF
+	IdTracks                      = 0x1654AE6B
+	IdTrackEntry                  = 0xAE
+	IdTrackNumber                 = 0xD7
+	IdTrackUID                    = 0x73C5
+	IdTrackType                   = 0x83
+	IdFlagEnabled                 = 0xB9
+	IdFlagDefault                 = 0x88
+	IdFlagForced                  = 0x55AA
+	IdFlagHearingImpaired         = 0x55AB
+	IdFlagVisualImpaired          = 0x55AC
+	IdFlagTextDescriptions        = 0x55AD
+	IdFlagOriginal                = 0x55AE
+	IdFlagCommentary              = 0x55AF
+	IdFlagLacing                  = 0x9C
+	IdMinCache                    = 0x6DE7
+	IdMaxCache                    = 0x6DF8
+	IdDefaultDuration             = 0x23E383
+	IdDefaultDecodedFieldDuration = 0x234E7A
+	IdTrackTimecodeScale          = 0x23314F
+	IdTrackOffset                 = 0x537F
+	IdMaxBlockAdditionId          = 0x55EE
+	IdBlockAdditionMapping        = 0x41E4
+	IdBlockAddIDValue             = 0x41F0
+	IdBlockAddIDName              = 0x41A4
+	IdBlockAddIDType              = 0x41E7
+	IdBlockAddIDExtraData         = 0x41ED
+	IdName                        = 0x536E
+	IdLanguage                    = 0x22B59C
+	IdLanguageBCP47               = 0x22B59D
+	IdCodecID                     = 0x86
+	IdCodecPrivate                = 0x63A2
+	IdCodecName                   = 0x258688
+	IdAttachmentLink              = 0x7446
+	IdCodecSettings               = 0x3A9697
+	IdCodecInfoURL                = 0x3B4040
+	IdCodecDownloadURL            = 0x26B240
+	IdCodecDecodeAll              = 0xAA
+	IdTrackOverlay                = 0x6FAB
+	IdCodecDelay                  = 0x56AA
+	IdSeekPreRoll                 = 0x56BB
+	IdTrackTranslate              = 0x6624
+	IdTrackTranslateTrackID       = 0x66A5
+	IdTrackTranslateCodec         = 0x66BF
+	IdTrackTranslateEditionUID    = 0x66FC
+	IdVideo                       = 0xE0
+	IdFlagInterlaced              = 0x9A
+	IdFieldOrder                  = 0x9D
+	IdStereoMode                  = 0x53B8
+	IdAlphaMode                   = 0x53C0
+	IdOldStereoMode               = 0x53B9
+	IdPixelWidth                  = 0xB0
+	IdPixelHeight                 = 0xBA
+	IdPixelCropBottom             = 0x54AA
+	IdPixelCropTop                = 0x54BB
+	IdPixelCropLeft               = 0x54CC
+	IdPixelCropRight              = 0x54DD
+	IdDisplayWidth                = 0x54B0
+	IdDisplayHeight               = 0x54BA
+	IdDisplayUnit                 = 0x54B2
+	IdAspectRatioType             = 0x54B3
+	IdColorSpace                  = 0x2EB524
+	IdGammaValue                  = 0x2FB523
+	IdFrameRate                   = 0x2383E3
+	IdColour                      = 0x55B0
+	IdMatrixCoefficients          = 0x55B1
+	IdBitsPerChannel              = 0x55B2
+	IdChromaSubsamplingHorz       = 0x55B3
+	IdChromaSubsamplingVert       = 0x55B4
+	IdCbSubsamplingHorz           = 0x55B5
+	IdCbSubsamplingVert           = 0x55B6
+	IdChromaSitingHorz            = 0x55B7
+	IdChromaSitingVert            = 0x55B8
+	IdRange                       = 0x55B9
+	IdTransferCharacteristics     = 0x55BA
+	IdPrimaries                   = 0x55BB
+	IdMaxCLL                      = 0x55BC
+	IdMaxFALL                     = 0x55BD
+	IdMasteringMetadata           = 0x55D0
+	IdPrimaryRChromaticityX       = 0x55D1
+	IdPrimaryRChromaticityY       = 0x55D2
+	IdPrimaryGChromaticityX       = 0x55D3
+	IdPrimaryGChromaticityY       = 0x55D4
+	IdPrimaryBChromaticityX       = 0x55D5
+	IdPrimaryBChromaticityY       = 0x55D6
+	IdWhitePointChromaticityX     = 0x55D7
+	IdWhitePointChromaticityY     = 0x55D8
+	IdLuminanceMax                = 0x55D9
+	IdLuminanceMin                = 0x55DA
+	IdProjection                  = 0x7670
+	IdProjectionType              = 0x7671
+	IdProjectionPrivate           = 0x7672
+	IdProjectionPoseYaw           = 0x7673
+	IdProjectionPosePitch         = 0x7674
+	IdProjectionPoseRoll          = 0x7675
+	IdAudio                       = 0xE1
+	IdSamplingFrequency           = 0xB5
+	IdOutputSamplingFrequency     = 0x78B5
+	IdChannels                    = 0x9F
+	IdChannelPositions            = 0x7D7B
+	IdBitDepth                    = 0x6264
+	IdEmphasis                    = 0x52F1
+	IdTrackOperation              = 0xE2
+	IdTrackCombinePlanes          = 0xE3
+	IdTrackPlane                  = 0xE4
+	IdTrackPlaneUID               = 0xE5
+	IdTrackPlaneType              = 0xE6
+	IdJoinBlocks                  = 0xE9
+	IdTrackJoinUID                = 0xED
+	IdContentEncodings            = 0x6D80
+	IdContentEncoding             = 0x6240
+	IdContentEncodingOrder        = 0x5031
+	IdContentEncodingScope        = 0x5032
+	IdContentEncodingType         = 0x5033
+	IdContentCompression          = 0x5034
+	IdContentCompAlgo             = 0x4254
+	IdContentCompSettings         = 0x4255
+	IdContentEncryption           = 0x5035
+	IdContentEncAlgo              = 0x47E1
+	IdContentEncKeyID             = 0x47E2
+	IdContentEncAESSettings       = 0x47E7
+	IdAESSettingsCipherMode       = 0x47E8
+	IdContentSignature            = 0x47E3
+	IdContentSigKeyID             = 0x47E4
+	IdContentSigAlgo              = 0x47E5
+	IdContentSigHashAlgo          = 0x47E6
+	IdCues                        = 0x1C53BB6B
+	IdCuePoint                    = 0xBB
+	IdCueTime                     = 0xB3
+	IdCueTrackPositions           = 0xB7
+	IdCueTrack                    = 0xF7
+	IdCueClusterPosition          = 0xF1
+	IdCueRelativePosition         = 0xF0
+	IdCueDuration                 = 0xB2
+	IdCueBlockNumber              = 0x5378
+	IdCueCodecState               = 0xEA
+	IdCueReference                = 0xDB
+	IdCueRefTime                  = 0x96
+	IdCueRefCluster               = 0x97
+	IdCueRefNumber                = 0x535F
+	IdCueRefCodecState            = 0xEB
+	IdAttachments                 = 0x1941A469
+	IdAttachedFile                = 0x61A7
+	IdFileDescription             = 0x467E
+	IdFileName                    = 0x466E
+	IdFileMimeType                = 0x4660
+	IdFileData                    = 0x465C
+	IdFileUID                     = 0x46AE
+	IdFileReferral                = 0x4675
+	IdFileUsedStartTime           = 0x4661
+	IdFileUsedEndTime             = 0x4662
+	IdChapters                    = 0x1043A770
+	IdEditionEntry                = 0x45B9
+	IdEditionUID                  = 0x45BC
+	IdEditionFlagHidden           = 0x45BD
+	IdEditionFlagDefault          = 0x45DB
+	IdEditionFlagOrdered          = 0x45DD
+	IdEditionDisplay              = 0x4520
+	IdEditionString               = 0x4521
+	IdEditionLanguageIETF         = 0x45E4
+	IdChapterAtom                 = 0xB6
+	IdChapterUID                  = 0x73C4
+	IdChapterStringUID            = 0x5654
+	IdChapterTimeStart            = 0x91
+	IdChapterTimeEnd              = 0x92
+	IdChapterFlagHidden           = 0x98
+	IdChapterFlagEnabled          = 0x4598
+	IdChapterSegmentUID           = 0x6E67
+	IdChapterSkipType             = 0x4588
+	IdChapterSegmentEditionUID    = 0x6EBC
+	IdChapterPhysicalEquiv        = 0x63C3
+	IdChapterTrack                = 0x8F
+	IdChapterTrackNumber          = 0x89
+	IdChapterDisplay              = 0x80
+	IdChapString                  = 0x85
+	IdChapLanguage                = 0x437C
+	IdChapLanguageBCP47           = 0x437D
+	IdChapCountry                 = 0x437E
+	IdChapProcess                 = 0x6944
+	IdChapProcessCodecID          = 0x6955
+	IdChapProcessPrivate          = 0x450D
+	IdChapProcessCommand          = 0x6911
+	IdChapProcessTime             = 0x6922
+	IdChapProcessData             = 0x6933
+	IdTags                        = 0x1254C367
+	IdTag                         = 0x7373
+	IdTargets                     = 0x63C0
+	IdTargetTypeValue             = 0x68CA
+	IdTargetType                  = 0x63CA
+	IdTagTrackUID                 = 0x63C5
+	IdTagEditionUID               = 0x63C9
+	IdTagChapterUID               = 0x63C4
+	IdTagAttachmentUID            = 0x63C6
+	IdSimpleTag                   = 0x67C8
+	IdTagName                     = 0x45A3
+	IdTagLanguage                 = 0x447A
+	IdTagLanguageBCP47            = 0x447B
+	IdTagDefault                  = 0x4484
+	IdTagDefaultBogus             = 0x44B4
+	IdTagString                   = 0x4487
+	IdTagBinary                   = 0x4485
 )
 
-var webmIdTypes = map[int]int{
-	IdSegment:                 ebml.TypeList,
-	IdSeekHead:                ebml.TypeList,
-	IdSeek:                    ebml.TypeList,
-	IdSeekID:                  ebml.TypeUint,
-	IdSeekPosition:            ebml.TypeUint,
-	IdInfo:                    ebml.TypeList,
-	IdTimecodeScale:           ebml.TypeUint,
-	IdDuration:                ebml.TypeFloat,
-	IdDateUTC:                 ebml.TypeUint,
-	IdMuxingApp:               ebml.TypeUTF8,
-	IdWritingApp:              ebml.TypeUTF8,
-	IdCluster:                 ebml.TypeList,
-	IdTimecode:                ebml.TypeUint,
-	IdPrevSize:                ebml.TypeUint,
-	IdSimpleBlock:             ebml.TypeBinary,
-	IdBlockGroup:              ebml.TypeList,
-	IdBlock:                   ebml.TypeBinary,
-	IdBlockDuration:           ebml.TypeUint,
-	IdReferenceBlock:          ebml.TypeInt,
-	IdCodecState:              ebml.TypeBinary,
-	IdDiscardPadding:          ebml.TypeInt,
-	IdSlices:                  ebml.TypeList,
-	IdTimeSlice:               ebml.TypeList,
-	IdLaceNumber:              ebml.TypeUint,
-	IdTracks:                  ebml.TypeList,
-	IdTrackEntry:              ebml.TypeList,
-	IdTrackNumber:             ebml.TypeUint,
-	IdTrackUID:                ebml.TypeUint,
-	IdTrackType:               ebml.TypeUint,
-	IdFlagEnabled:             ebml.TypeUint,
-	IdFlagDefault:             ebml.TypeUint,
-	IdFlagForced:              ebml.TypeUint,
-	IdFlagLacing:              ebml.TypeUint,
-	IdDefaultDuration:         ebml.TypeUint,
-	IdName:                    ebml.TypeUTF8,
-	IdLanguage:                ebml.TypeString,
-	IdCodecID:                 ebml.TypeString,
-	IdCodecPrivate:            ebml.TypeBinary,
-	IdCodecName:               ebml.TypeUTF8,
-	IdCodecDelay:              ebml.TypeUint,
-	IdSeekPreRoll:             ebml.TypeUint,
-	IdVideo:                   ebml.TypeList,
-	IdFlagInterlaced:          ebml.TypeUint,
-	IdStereoMode:              ebml.TypeUint,
-	IdAlphaMode:               ebml.TypeUint,
-	IdPixelWidth:              ebml.TypeUint,
-	IdPixelHeight:             ebml.TypeUint,
-	IdPixelCropBottom:         ebml.TypeUint,
-	IdPixelCropTop:            ebml.TypeUint,
-	IdPixelCropLeft:           ebml.TypeUint,
-	IdPixelCropRight:          ebml.TypeUint,
-	IdDisplayWidth:            ebml.TypeUint,
-	IdDisplayHeight:           ebml.TypeUint,
-	IdDisplayUnit:             ebml.TypeUint,
-	IdAspectRatioType:         ebml.TypeUint,
-	IdFrameRate:               ebml.TypeFloat,
-	IdProjection:              ebml.TypeList,
-	IdProjectionType:          ebml.TypeUint,
-	IdProjectionPrivate:       ebml.TypeBinary,
-	IdProjectionPoseYaw:       ebml.TypeFloat,
-	IdProjectionPosePitch:     ebml.TypeFloat,
-	IdProjectionPoseRoll:      ebml.TypeFloat,
-	IdAudio:                   ebml.TypeList,
-	IdSamplingFrequency:       ebml.TypeFloat,
-	IdOutputSamplingFrequency: ebml.TypeFloat,
-	IdChannels:                ebml.TypeUint,
-	IdBitDepth:                ebml.TypeUint,
-	IdCues:                    ebml.TypeList,
-	IdCuePoint:                ebml.TypeList,
-	IdCueTime:                 ebml.TypeUint,
-	IdCueTrackPositions:       ebml.TypeList,
-	IdCueTrack:                ebml.TypeUint,
-	IdCueClusterPosition:      ebml.TypeUint,
-	IdCueRelativePosition:     ebml.TypeUint,
-	IdCueBlockNumber:          ebml.TypeUint,
-	IdTags:                    ebml.TypeList,
-	IdTag:                     ebml.TypeList,
-	IdTargets:                 ebml.TypeList,
-	IdTargetTypeValue:         ebml.TypeUint,
-	IdTargetType:              ebml.TypeString,
-	IdTagTrackUID:             ebml.TypeUint,
-	IdTagEditionUID:           ebml.TypeUint,
-	IdTagChapterUID:           ebml.TypeUint,
-	IdTagAttachmentUID:        ebml.TypeUint,
-	IdSimpleTag:               ebml.TypeList,
-	IdTagName:                 ebml.TypeUTF8,
-	IdTagLanguage:             ebml.TypeString,
-	IdTagDefault:              ebml.TypeUint,
-	IdTagString:               ebml.TypeUTF8,
-	IdTagBinary:               ebml.TypeBinary,
-}
-var idTypes map[int]int = nil
-
-func IdTypes() map[int]int {
-	if idTypes == nil {
-		idTypes = ebml.IdTypes()
-		for k, v := range webmIdTypes {
-			idTypes[k] = v
-		}
-	}
-	return idTypes
-}
-
-var idToName = map[int]string{
-	IdSegment:                    "Segment",
-	IdSeekHead:                   "SeekHead",
-	IdSeek:                       "Seek",
-	IdSeekID:                     "SeekID",
-	IdSeekPosition:               "SeekPosition",
-	IdInfo:                       "Info",
-	IdSegmentUID:                 "SegmentUID",
-	IdSegmentFilename:            "SegmentFilename",
-	IdPrevUID:                    "PrevUID",
-	IdPrevFilename:               "PrevFilename",
-	IdNextUID:                    "NextUID",
-	IdNextFilename:               "NextFilename",
-	IdSegmentFamily:              "SegmentFamily",
-	IdChapterTranslate:           "ChapterTranslate",
-	IdChapterTranslateEditionUID: "ChapterTranslateEditionUID",
-	IdChapterTranslateCodec:      "ChapterTranslateCodec",
-	IdChapterTranslateID:         "ChapterTranslateID",
-	IdTimecodeScale:              "TimecodeScale",
-	IdDuration:                   "Duration",
-	IdDateUTC:                    "DateUTC",
-	IdTitle:                      "Title",
-	IdMuxingApp:                  "MuxingApp",
-	IdWritingApp:                 "WritingApp",
-	IdCluster:                    "Cluster",
-	IdTimecode:                   "Timecode",
-	IdSilentTracks:               "SilentTracks",
-	IdSilentTrackNumber:          "SilentTrackNumber",
-	IdPosition:                   "Position",
-	IdPrevSize:                   "PrevSize",
-	IdSimpleBlock:                "SimpleBlock",
-	IdBlockGroup:                 "BlockGroup",
-	IdBlock:                      "Block",
-	IdBlockAdditions:             "BlockAdditions",
-	IdBlockMore:                  "BlockMore",
-	IdBlockAddID:                 "BlockAddID",
-	IdBlockAdditional:            "BlockAdditional",
-	IdBlockDuration:              "BlockDuration",
-	IdReferencePriority:          "ReferencePriority",
-	IdReferenceBlock:             "ReferenceBlock",
-	IdCodecState:                 "CodecState",
-	IdDiscardPadding:             "DiscardPadding",
-	IdSlices:                     "Slices",
-	IdTimeSlice:                  "TimeSlice",
-	IdLaceNumber:                 "LaceNumber",
-	IdTracks:                     "Tracks",
-	IdTrackEntry:                 "TrackEntry",
-	IdTrackNumber:                "TrackNumber",
-	IdTrackUID:                   "TrackUID",
-	IdTrackType:                  "TrackType",
-	IdFlagEnabled:                "FlagEnabled",
-	IdFlagDefault:                "FlagDefault",
-	IdFlagForced:                 "FlagForced",
-	IdFlagLacing:                 "FlagLacing",
-	IdMinCache:                   "MinCache",
-	IdMaxCache:                   "MaxCache",
-	IdDefaultDuration:            "DefaultDuration",
-	IdTrackTimecodeScale:         "TrackTimecodeScale",
-	IdMaxBlockAdditionId:         "MaxBlockAdditionId",
-	IdName:                       "Name",
-	IdLanguage:                   "Language",
-	IdCodecID:                    "CodecID",
-	IdCodecPrivate:               "CodecPrivate",
-	IdCodecName:                  "CodecName",
-	IdAttachmentLink:             "AttachmentLink",
-	IdCodecDecodeAll:             "CodecDecodeAll",
-	IdTrackOverlay:               "TrackOverlay",
-	IdCodecDelay:                 "CodecDelay",
-	IdSeekPreRoll:                "SeekPreRoll",
-	IdTrackTranslate:             "TrackTranslate",
-	IdTrackTranslateEditionUID:   "TrackTranslateEditionUID",
-	IdTrackTranslateCodec:        "TrackTranslateCodec",
-	IdTrackTranslateTrackID:      "TrackTranslateTrackID",
-	IdVideo:                      "Video",
-	IdFlagInterlaced:             "FlagInterlaced",
-	IdStereoMode:                 "StereoMode",
-	IdAlphaMode:                  "AlphaMode",
-	IdPixelWidth:                 "PixelWidth",
-	IdPixelHeight:                "PixelHeight",
-	IdPixelCropBottom:            "PixelCropBottom",
-	IdPixelCropTop:               "PixelCropTop",
-	IdPixelCropLeft:              "PixelCropLeft",
-	IdPixelCropRight:             "PixelCropRight",
-	IdDisplayWidth:               "DisplayWidth",
-	IdDisplayHeight:              "DisplayHeight",
-	IdDisplayUnit:                "DisplayUnit",
-	IdAspectRatioType:            "AspectRatioType",
-	IdColorSpace:                 "ColorSpace",
-	IdFrameRate:                  "FrameRate",
-	IdProjection:                 "Projection",
-	IdProjectionType:             "ProjectionType",
-	IdProjectionPrivate:          "ProjectionPrivate",
-	IdProjectionPoseYaw:          "ProjectionPoseYaw",
-	IdProjectionPosePitch:        "ProjectionPosePitch",
-	IdProjectionPoseRoll:         "ProjectionPoseRoll",
-	IdAudio:                      "Audio",
-	IdSamplingFrequency:          "SamplingFrequency",
-	IdOutputSamplingFrequency:    "OutputSamplingFrequency",
-	IdChannels:                   "Channels",
-	IdBitDepth:                   "BitDepth",
-	IdTrackOperation:             "TrackOperation",
-	IdTrackCombinePlanes:         "TrackCombinePlanes",
-	IdTrackPlane:                 "TrackPlane",
-	IdTrackPlaneUID:              "TrackPlaneUID",
-	IdTrackPlaneType:             "TrackPlaneType",
-	IdJoinBlocks:                 "JoinBlocks",
-	IdTrackJoinUID:               "TrackJoinUID",
-	IdContentEncodings:           "ContentEncodings",
-	IdContentEncoding:            "ContentEncoding",
-	IdContentEncodingOrder:       "ContentEncodingOrder",
-	IdContentEncodingScope:       "ContentEncodingScope",
-	IdContentEncodingType:        "ContentEncodingType",
-	IdContentCompression:         "ContentCompression",
-	IdContentCompAlgo:            "ContentCompAlgo",
-	IdContentCompSettings:        "ContentCompSettings",
-	IdContentEncryption:          "ContentEncryption",
-	IdContentEncAlgo:             "ContentEncAlgo",
-	IdContentEncKeyID:            "ContentEncKeyID",
-	IdContentSignature:           "ContentSignature",
-	IdContentSigKeyID:            "ContentSigKeyID",
-	IdContentSigAlgo:             "ContentSigAlgo",
-	IdContentSigHashAlgo:         "ContentSigHashAlgo",
-	IdCues:                       "Cues",
-	IdCuePoint:                   "CuePoint",
-	IdCueTime:                    "CueTime",
-	IdCueTrackPositions:          "CueTrackPositions",
-	IdCueTrack:                   "CueTrack",
-	IdCueClusterPosition:         "CueClusterPosition",
-	IdCueRelativePosition:        "CueRelativePosition",
-	IdCueBlockNumber:             "CueBlockNumber",
-	IdCueCodecState:              "CueCodecState",
-	IdCueReference:               "CueReference",
-	IdCueRefTime:                 "CueRefTime",
-	IdAttachments:                "Attachments",
-	IdAttachedFile:               "AttachedFile",
-	IdFileDescription:            "FileDescription",
-	IdFileName:                   "FileName",
-	IdFileMimeType:               "FileMimeType",
-	IdFileData:                   "FileData",
-	IdFileUID:                    "FileUID",
-	IdChapters:                   "Chapters",
-	IdEditionEntry:               "EditionEntry",
-	IdEditionUID:                 "EditionUID",
-	IdEditionFlagHidden:          "EditionFlagHidden",
-	IdEditionFlagDefault:         "EditionFlagDefault",
-	IdEditionFlagOrdered:         "EditionFlagOrdered",
-	IdChapterAtom:                "ChapterAtom",
-	IdChapterUID:                 "ChapterUID",
-	IdChapterTimeStart:           "ChapterTimeStart",
-	IdChapterTimeEnd:             "ChapterTimeEnd",
-	IdChapterFlagHidden:          "ChapterFlagHidden",
-	IdChapterFlagEnabled:         "ChapterFlagEnabled",
-	IdChapterSegmentUID:          "ChapterSegmentUID",
-	IdChapterSegmentEditionUID:   "ChapterSegmentEditionUID",
-	IdChapterPhysicalEquiv:       "ChapterPhysicalEquiv",
-	IdChapterTrack:               "ChapterTrack",
-	IdChapterTrackNumber:         "ChapterTrackNumber",
-	IdChapterDisplay:             "ChapterDisplay",
-	IdChapString:                 "ChapString",
-	IdChapLanguage:               "ChapLanguage",
-	IdChapCountry:                "ChapCountry",
-	IdChapProcess:                "ChapProcess",
-	IdChapProcessCodecID:         "ChapProcessCodecID",
-	IdChapProcessPrivate:         "ChapProcessPrivate",
-	IdChapProcessCommand:         "ChapProcessCommand",
-	IdChapProcessTime:            "ChapProcessTime",
-	IdChapProcessData:            "ChapProcessData",
-	IdTags:                       "Tags",
-	IdTag:                        "Tag",
-	IdTargets:                    "Targets",
-	IdTargetTypeValue:            "TargetTypeValue",
-	IdTargetType:                 "TargetType",
-	IdTagTrackUID:                "TagTrackUID",
-	IdTagEditionUID:              "TagEditionUID",
-	IdTagChapterUID:              "TagChapterUID",
-	IdTagAttachmentUID:           "TagAttachmentUID",
-	IdSimpleTag:                  "SimpleTag",
-	IdTagName:                    "TagName",
-	IdTagLanguage:                "TagLanguage",
-	IdTagDefault:                 "TagDefault",
-	IdTagString:                  "TagString",
-	IdTagBinary:                  "TagBinary",
-}
-
-func IdToName(id int) string {
-	if name, ok := idToName[id]; ok {
-		return name
-	}
-	return ebml.IdToName(id)
-}
-
-func UnknownSizeInfo() map[int][]int {
-	return map[int][]int{
-		IdSegment: []int{
-			ebml.IdHeader,
-			IdSegment},
-		IdCluster: []int{
-			IdSegment,
-			IdSeekHead,
-			IdInfo,
-			IdCluster,
-			IdTracks,
-			IdCues,
-			IdAttachments,
-			IdChapters,
-			IdTags}}
+var elementDefs = []ebml.ElementDef{
+	{ID: IdSegment, Name: "Segment", Type: ebml.TypeList, Parent: -1, UnknownSizeAllowed: true},
+	{ID: IdSeekHead, Name: "SeekHead", Type: ebml.TypeList, Parent: IdSegment},
+	{ID: IdSeek, Name: "Seek", Type: ebml.TypeList, Parent: IdSeekHead},
+	{ID: IdSeekID, Name: "SeekID", Type: ebml.TypeBinary, Parent: IdSeek},
+	{ID: IdSeekPosition, Name: "SeekPosition", Type: ebml.TypeUint, Parent: IdSeek},
+	{ID: IdInfo, Name: "Info", Type: ebml.TypeList, Parent: IdSegment},
+	{ID: IdSegmentUID, Name: "SegmentUID", Type: ebml.TypeBinary, Parent: IdInfo},
+	{ID: IdSegmentFilename, Name: "SegmentFilename", Type: ebml.TypeUTF8, Parent: IdInfo},
+	{ID: IdPrevUID, Name: "PrevUID", Type: ebml.TypeBinary, Parent: IdInfo},
+	{ID: IdPrevFilename, Name: "PrevFilename", Type: ebml.TypeUTF8, Parent: IdInfo},
+	{ID: IdNextUID, Name: "NextUID", Type: ebml.TypeBinary, Parent: IdInfo},
+	{ID: IdNextFilename, Name: "NextFilename", Type: ebml.TypeUTF8, Parent: IdInfo},
+	{ID: IdSegmentFamily, Name: "SegmentFamily", Type: ebml.TypeBinary, Parent: IdInfo},
+	{ID: IdChapterTranslate, Name: "ChapterTranslate", Type: ebml.TypeList, Parent: IdInfo},
+	{ID: IdChapterTranslateID, Name: "ChapterTranslateID", Type: ebml.TypeBinary, Parent: IdChapterTranslate},
+	{ID: IdChapterTranslateCodec, Name: "ChapterTranslateCodec", Type: ebml.TypeUint, Parent: IdChapterTranslate},
+	{ID: IdChapterTranslateEditionUID, Name: "ChapterTranslateEditionUID", Type: ebml.TypeUint, Parent: IdChapterTranslate},
+	{ID: IdTimecodeScale, Name: "TimecodeScale", Type: ebml.TypeUint, Parent: IdInfo},
+	{ID: IdDuration, Name: "Duration", Type: ebml.TypeFloat, Parent: IdInfo},
+	{ID: IdDateUTC, Name: "DateUTC", Type: ebml.TypeDate, Parent: IdInfo},
+	{ID: IdTitle, Name: "Title", Type: ebml.TypeUTF8, Parent: IdInfo},
+	{ID: IdMuxingApp, Name: "MuxingApp", Type: ebml.TypeUTF8, Parent: IdInfo},
+	{ID: IdWritingApp, Name: "WritingApp", Type: ebml.TypeUTF8, Parent: IdInfo},
+	{ID: IdCluster, Name: "Cluster", Type: ebml.TypeList, Parent: IdSegment, UnknownSizeAllowed: true},
+	{ID: IdTimecode, Name: "Timecode", Type: ebml.TypeUint, Parent: IdCluster},
+	{ID: IdSilentTracks, Name: "SilentTracks", Type: ebml.TypeList, Parent: IdCluster},
+	{ID: IdSilentTrackNumber, Name: "SilentTrackNumber", Type: ebml.TypeUint, Parent: IdSilentTracks},
+	{ID: IdPosition, Name: "Position", Type: ebml.TypeUint, Parent: IdCluster},
+	{ID: IdPrevSize, Name: "PrevSize", Type: ebml.TypeUint, Parent: IdCluster},
+	{ID: IdSimpleBlock, Name: "SimpleBlock", Type: ebml.TypeBinary, Parent: IdCluster},
+	{ID: IdBlockGroup, Name: "BlockGroup", Type: ebml.TypeList, Parent: IdCluster},
+	{ID: IdBlock, Name: "Block", Type: ebml.TypeBinary, Parent: IdBlockGroup},
+	{ID: IdBlockVirtual, Name: "BlockVirtual", Type: ebml.TypeBinary, Parent: IdBlockGroup},
+	{ID: IdBlockAdditions, Name: "BlockAdditions", Type: ebml.TypeList, Parent: IdBlockGroup},
+	{ID: IdBlockMore, Name: "BlockMore", Type: ebml.TypeList, Parent: IdBlockAdditions},
+	{ID: IdBlockAdditional, Name: "BlockAdditional", Type: ebml.TypeBinary, Parent: IdBlockMore},
+	{ID: IdBlockAddID, Name: "BlockAddID", Type: ebml.TypeUint, Parent: IdBlockMore},
+	{ID: IdBlockDuration, Name: "BlockDuration", Type: ebml.TypeUint, Parent: IdBlockGroup},
+	{ID: IdReferencePriority, Name: "ReferencePriority", Type: ebml.TypeUint, Parent: IdBlockGroup},
+	{ID: IdReferenceBlock, Name: "ReferenceBlock", Type: ebml.TypeInt, Parent: IdBlockGroup},
+	{ID: IdReferenceVirtual, Name: "ReferenceVirtual", Type: ebml.TypeInt, Parent: IdBlockGroup},
+	{ID: IdCodecState, Name: "CodecState", Type: ebml.TypeBinary, Parent: IdBlockGroup},
+	{ID: IdDiscardPadding, Name: "DiscardPadding", Type: ebml.TypeInt, Parent: IdBlockGroup},
+	{ID: IdSlices, Name: "Slices", Type: ebml.TypeList, Parent: IdBlockGroup},
+	{ID: IdTimeSlice, Name: "TimeSlice", Type: ebml.TypeList, Parent: IdSlices},
+	{ID: IdLaceNumber, Name: "LaceNumber", Type: ebml.TypeUint, Parent: IdTimeSlice},
+	{ID: IdFrameNumber, Name: "FrameNumber", Type: ebml.TypeUint, Parent: IdTimeSlice},
+	{ID: IdBlockAdditionID, Name: "BlockAdditionID", Type: ebml.TypeUint, Parent: IdTimeSlice},
+	{ID: IdDelay, Name: "Delay", Type: ebml.TypeUint, Parent: IdTimeSlice},
+	{ID: IdSliceDuration, Name: "SliceDuration", Type: ebml.TypeUint, Parent: IdTimeSlice},
+	{ID: IdReferenceFrame, Name: "ReferenceFrame", Type: ebml.TypeList, Parent: IdBlockGroup},
+	{ID: IdReferenceOffset, Name: "ReferenceOffset", Type: ebml.TypeUint, Parent: IdReferenceFrame},
+	{ID: IdReferenceTimestamp, Name: "ReferenceTimestamp", Type: ebml.TypeUint, Parent: IdReferenceFrame},
+	{ID: IdEncryptedBlock, Name: "EncryptedBlock", Type: ebml.TypeBinary, Parent: IdCluster},
+	{ID: IdTracks, Name: "Tracks", Type: ebml.TypeList, Parent: IdSegment},
+	{ID: IdTrackEntry, Name: "TrackEntry", Type: ebml.TypeList, Parent: IdTracks},
+	{ID: IdTrackNumber, Name: "TrackNumber", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdTrackUID, Name: "TrackUID", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdTrackType, Name: "TrackType", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdFlagEnabled, Name: "FlagEnabled", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdFlagDefault, Name: "FlagDefault", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdFlagForced, Name: "FlagForced", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdFlagHearingImpaired, Name: "FlagHearingImpaired", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdFlagVisualImpaired, Name: "FlagVisualImpaired", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdFlagTextDescriptions, Name: "FlagTextDescriptions", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdFlagOriginal, Name: "FlagOriginal", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdFlagCommentary, Name: "FlagCommentary", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdFlagLacing, Name: "FlagLacing", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdMinCache, Name: "MinCache", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdMaxCache, Name: "MaxCache", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdDefaultDuration, Name: "DefaultDuration", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdDefaultDecodedFieldDuration, Name: "DefaultDecodedFieldDuration", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdTrackTimecodeScale, Name: "TrackTimecodeScale", Type: ebml.TypeFloat, Parent: IdTrackEntry},
+	{ID: IdTrackOffset, Name: "TrackOffset", Type: ebml.TypeInt, Parent: IdTrackEntry},
+	{ID: IdMaxBlockAdditionId, Name: "MaxBlockAdditionId", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdBlockAdditionMapping, Name: "BlockAdditionMapping", Type: ebml.TypeList, Parent: IdTrackEntry},
+	{ID: IdBlockAddIDValue, Name: "BlockAddIDValue", Type: ebml.TypeUint, Parent: IdBlockAdditionMapping},
+	{ID: IdBlockAddIDName, Name: "BlockAddIDName", Type: ebml.TypeString, Parent: IdBlockAdditionMapping},
+	{ID: IdBlockAddIDType, Name: "BlockAddIDType", Type: ebml.TypeUint, Parent: IdBlockAdditionMapping},
+	{ID: IdBlockAddIDExtraData, Name: "BlockAddIDExtraData", Type: ebml.TypeBinary, Parent: IdBlockAdditionMapping},
+	{ID: IdName, Name: "Name", Type: ebml.TypeUTF8, Parent: IdTrackEntry},
+	{ID: IdLanguage, Name: "Language", Type: ebml.TypeString, Parent: IdTrackEntry},
+	{ID: IdLanguageBCP47, Name: "LanguageBCP47", Type: ebml.TypeString, Parent: IdTrackEntry},
+	{ID: IdCodecID, Name: "CodecID", Type: ebml.TypeString, Parent: IdTrackEntry},
+	{ID: IdCodecPrivate, Name: "CodecPrivate", Type: ebml.TypeBinary, Parent: IdTrackEntry},
+	{ID: IdCodecName, Name: "CodecName", Type: ebml.TypeUTF8, Parent: IdTrackEntry},
+	{ID: IdAttachmentLink, Name: "AttachmentLink", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdCodecSettings, Name: "CodecSettings", Type: ebml.TypeUTF8, Parent: IdTrackEntry},
+	{ID: IdCodecInfoURL, Name: "CodecInfoURL", Type: ebml.TypeString, Parent: IdTrackEntry},
+	{ID: IdCodecDownloadURL, Name: "CodecDownloadURL", Type: ebml.TypeString, Parent: IdTrackEntry},
+	{ID: IdCodecDecodeAll, Name: "CodecDecodeAll", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdTrackOverlay, Name: "TrackOverlay", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdCodecDelay, Name: "CodecDelay", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdSeekPreRoll, Name: "SeekPreRoll", Type: ebml.TypeUint, Parent: IdTrackEntry},
+	{ID: IdTrackTranslate, Name: "TrackTranslate", Type: ebml.TypeList, Parent: IdTrackEntry},
+	{ID: IdTrackTranslateTrackID, Name: "TrackTranslateTrackID", Type: ebml.TypeBinary, Parent: IdTrackTranslate},
+	{ID: IdTrackTranslateCodec, Name: "TrackTranslateCodec", Type: ebml.TypeUint, Parent: IdTrackTranslate},
+	{ID: IdTrackTranslateEditionUID, Name: "TrackTranslateEditionUID", Type: ebml.TypeUint, Parent: IdTrackTranslate},
+	{ID: IdVideo, Name: "Video", Type: ebml.TypeList, Parent: IdTrackEntry},
+	{ID: IdFlagInterlaced, Name: "FlagInterlaced", Type: ebml.TypeUint, Parent: IdVideo},
+	{ID: IdFieldOrder, Name: "FieldOrder", Type: ebml.TypeUint, Parent: IdVideo},
+	{ID: IdStereoMode, Name: "StereoMode", Type: ebml.TypeUint, Parent: IdVideo},
+	{ID: IdAlphaMode, Name: "AlphaMode", Type: ebml.TypeUint, Parent: IdVideo},
+	{ID: IdOldStereoMode, Name: "OldStereoMode", Type: ebml.TypeUint, Parent: IdVideo},
+	{ID: IdPixelWidth, Name: "PixelWidth", Type: ebml.TypeUint, Parent: IdVideo},
+	{ID: IdPixelHeight, Name: "PixelHeight", Type: ebml.TypeUint, Parent: IdVideo},
+	{ID: IdPixelCropBottom, Name: "PixelCropBottom", Type: ebml.TypeUint, Parent: IdVideo},
+	{ID: IdPixelCropTop, Name: "PixelCropTop", Type: ebml.TypeUint, Parent: IdVideo},
+	{ID: IdPixelCropLeft, Name: "PixelCropLeft", Type: ebml.TypeUint, Parent: IdVideo},
+	{ID: IdPixelCropRight, Name: "PixelCropRight", Type: ebml.TypeUint, Parent: IdVideo},
+	{ID: IdDisplayWidth, Name: "DisplayWidth", Type: ebml.TypeUint, Parent: IdVideo},
+	{ID: IdDisplayHeight, Name: "DisplayHeight", Type: ebml.TypeUint, Parent: IdVideo},
+	{ID: IdDisplayUnit, Name: "DisplayUnit", Type: ebml.TypeUint, Parent: IdVideo},
+	{ID: IdAspectRatioType, Name: "AspectRatioType", Type: ebml.TypeUint, Parent: IdVideo},
+	{ID: IdColorSpace, Name: "ColorSpace", Type: ebml.TypeBinary, Parent: IdVideo},
+	{ID: IdGammaValue, Name: "GammaValue", Type: ebml.TypeFloat, Parent: IdVideo},
+	{ID: IdFrameRate, Name: "FrameRate", Type: ebml.TypeFloat, Parent: IdVideo},
+	{ID: IdColour, Name: "Colour", Type: ebml.TypeList, Parent: IdVideo},
+	{ID: IdMatrixCoefficients, Name: "MatrixCoefficients", Type: ebml.TypeUint, Parent: IdColour},
+	{ID: IdBitsPerChannel, Name: "BitsPerChannel", Type: ebml.TypeUint, Parent: IdColour},
+	{ID: IdChromaSubsamplingHorz, Name: "ChromaSubsamplingHorz", Type: ebml.TypeUint, Parent: IdColour},
+	{ID: IdChromaSubsamplingVert, Name: "ChromaSubsamplingVert", Type: ebml.TypeUint, Parent: IdColour},
+	{ID: IdCbSubsamplingHorz, Name: "CbSubsamplingHorz", Type: ebml.TypeUint, Parent: IdColour},
+	{ID: IdCbSubsamplingVert, Name: "CbSubsamplingVert", Type: ebml.TypeUint, Parent: IdColour},
+	{ID: IdChromaSitingHorz, Name: "ChromaSitingHorz", Type: ebml.TypeUint, Parent: IdColour},
+	{ID: IdChromaSitingVert, Name: "ChromaSitingVert", Type: ebml.TypeUint, Parent: IdColour},
+	{ID: IdRange, Name: "Range", Type: ebml.TypeUint, Parent: IdColour},
+	{ID: IdTransferCharacteristics, Name: "TransferCharacteristics", Type: ebml.TypeUint, Parent: IdColour},
+	{ID: IdPrimaries, Name: "Primaries", Type: ebml.TypeUint, Parent: IdColour},
+	{ID: IdMaxCLL, Name: "MaxCLL", Type: ebml.TypeUint, Parent: IdColour},
+	{ID: IdMaxFALL, Name: "MaxFALL", Type: ebml.TypeUint, Parent: IdColour},
+	{ID: IdMasteringMetadata, Name: "MasteringMetadata", Type: ebml.TypeList, Parent: IdColour},
+	{ID: IdPrimaryRChromaticityX, Name: "PrimaryRChromaticityX", Type: ebml.TypeFloat, Parent: IdMasteringMetadata},
+	{ID: IdPrimaryRChromaticityY, Name: "PrimaryRChromaticityY", Type: ebml.TypeFloat, Parent: IdMasteringMetadata},
+	{ID: IdPrimaryGChromaticityX, Name: "PrimaryGChromaticityX", Type: ebml.TypeFloat, Parent: IdMasteringMetadata},
+	{ID: IdPrimaryGChromaticityY, Name: "PrimaryGChromaticityY", Type: ebml.TypeFloat, Parent: IdMasteringMetadata},
+	{ID: IdPrimaryBChromaticityX, Name: "PrimaryBChromaticityX", Type: ebml.TypeFloat, Parent: IdMasteringMetadata},
+	{ID: IdPrimaryBChromaticityY, Name: "PrimaryBChromaticityY", Type: ebml.TypeFloat, Parent: IdMasteringMetadata},
+	{ID: IdWhitePointChromaticityX, Name: "WhitePointChromaticityX", Type: ebml.TypeFloat, Parent: IdMasteringMetadata},
+	{ID: IdWhitePointChromaticityY, Name: "WhitePointChromaticityY", Type: ebml.TypeFloat, Parent: IdMasteringMetadata},
+	{ID: IdLuminanceMax, Name: "LuminanceMax", Type: ebml.TypeFloat, Parent: IdMasteringMetadata},
+	{ID: IdLuminanceMin, Name: "LuminanceMin", Type: ebml.TypeFloat, Parent: IdMasteringMetadata},
+	{ID: IdProjection, Name: "Projection", Type: ebml.TypeList, Parent: IdVideo},
+	{ID: IdProjectionType, Name: "ProjectionType", Type: ebml.TypeUint, Parent: IdProjection},
+	{ID: IdProjectionPrivate, Name: "ProjectionPrivate", Type: ebml.TypeBinary, Parent: IdProjection},
+	{ID: IdProjectionPoseYaw, Name: "ProjectionPoseYaw", Type: ebml.TypeFloat, Parent: IdProjection},
+	{ID: IdProjectionPosePitch, Name: "ProjectionPosePitch", Type: ebml.TypeFloat, Parent: IdProjection},
+	{ID: IdProjectionPoseRoll, Name: "ProjectionPoseRoll", Type: ebml.TypeFloat, Parent: IdProjection},
+	{ID: IdAudio, Name: "Audio", Type: ebml.TypeList, Parent: IdTrackEntry},
+	{ID: IdSamplingFrequency, Name: "SamplingFrequency", Type: ebml.TypeFloat, Parent: IdAudio},
+	{ID: IdOutputSamplingFrequency, Name: "OutputSamplingFrequency", Type: ebml.TypeFloat, Parent: IdAudio},
+	{ID: IdChannels, Name: "Channels", Type: ebml.TypeUint, Parent: IdAudio},
+	{ID: IdChannelPositions, Name: "ChannelPositions", Type: ebml.TypeBinary, Parent: IdAudio},
+	{ID: IdBitDepth, Name: "BitDepth", Type: ebml.TypeUint, Parent: IdAudio},
+	{ID: IdEmphasis, Name: "Emphasis", Type: ebml.TypeUint, Parent: IdAudio},
+	{ID: IdTrackOperation, Name: "TrackOperation", Type: ebml.TypeList, Parent: IdTrackEntry},
+	{ID: IdTrackCombinePlanes, Name: "TrackCombinePlanes", Type: ebml.TypeList, Parent: IdTrackOperation},
+	{ID: IdTrackPlane, Name: "TrackPlane", Type: ebml.TypeList, Parent: IdTrackCombinePlanes},
+	{ID: IdTrackPlaneUID, Name: "TrackPlaneUID", Type: ebml.TypeUint, Parent: IdTrackPlane},
+	{ID: IdTrackPlaneType, Name: "TrackPlaneType", Type: ebml.TypeUint, Parent: IdTrackPlane},
+	{ID: IdJoinBlocks, Name: "JoinBlocks", Type: ebml.TypeList, Parent: IdTrackOperation},
+	{ID: IdTrackJoinUID, Name: "TrackJoinUID", Type: ebml.TypeUint, Parent: IdJoinBlocks},
+	{ID: IdContentEncodings, Name: "ContentEncodings", Type: ebml.TypeList, Parent: IdTrackEntry},
+	{ID: IdContentEncoding, Name: "ContentEncoding", Type: ebml.TypeList, Parent: IdContentEncodings},
+	{ID: IdContentEncodingOrder, Name: "ContentEncodingOrder", Type: ebml.TypeUint, Parent: IdContentEncoding},
+	{ID: IdContentEncodingScope, Name: "ContentEncodingScope", Type: ebml.TypeUint, Parent: IdContentEncoding},
+	{ID: IdContentEncodingType, Name: "ContentEncodingType", Type: ebml.TypeUint, Parent: IdContentEncoding},
+	{ID: IdContentCompression, Name: "ContentCompression", Type: ebml.TypeList, Parent: IdContentEncoding},
+	{ID: IdContentCompAlgo, Name: "ContentCompAlgo", Type: ebml.TypeUint, Parent: IdContentCompression},
+	{ID: IdContentCompSettings, Name: "ContentCompSettings", Type: ebml.TypeBinary, Parent: IdContentCompression},
+	{ID: IdContentEncryption, Name: "ContentEncryption", Type: ebml.TypeList, Parent: IdContentEncoding},
+	{ID: IdContentEncAlgo, Name: "ContentEncAlgo", Type: ebml.TypeUint, Parent: IdContentEncryption},
+	{ID: IdContentEncKeyID, Name: "ContentEncKeyID", Type: ebml.TypeBinary, Parent: IdContentEncryption},
+	{ID: IdContentEncAESSettings, Name: "ContentEncAESSettings", Type: ebml.TypeList, Parent: IdContentEncryption},
+	{ID: IdAESSettingsCipherMode, Name: "AESSettingsCipherMode", Type: ebml.TypeUint, Parent: IdContentEncAESSettings},
+	{ID: IdContentSignature, Name: "ContentSignature", Type: ebml.TypeBinary, Parent: IdContentEncryption},
+	{ID: IdContentSigKeyID, Name: "ContentSigKeyID", Type: ebml.TypeBinary, Parent: IdContentEncryption},
+	{ID: IdContentSigAlgo, Name: "ContentSigAlgo", Type: ebml.TypeUint, Parent: IdContentEncryption},
+	{ID: IdContentSigHashAlgo, Name: "ContentSigHashAlgo", Type: ebml.TypeUint, Parent: IdContentEncryption},
+	{ID: IdCues, Name: "Cues", Type: ebml.TypeList, Parent: IdSegment},
+	{ID: IdCuePoint, Name: "CuePoint", Type: ebml.TypeList, Parent: IdCues},
+	{ID: IdCueTime, Name: "CueTime", Type: ebml.TypeUint, Parent: IdCuePoint},
+	{ID: IdCueTrackPositions, Name: "CueTrackPositions", Type: ebml.TypeList, Parent: IdCuePoint},
+	{ID: IdCueTrack, Name: "CueTrack", Type: ebml.TypeUint, Parent: IdCueTrackPositions},
+	{ID: IdCueClusterPosition, Name: "CueClusterPosition", Type: ebml.TypeUint, Parent: IdCueTrackPositions},
+	{ID: IdCueRelativePosition, Name: "CueRelativePosition", Type: ebml.TypeUint, Parent: IdCueTrackPositions},
+	{ID: IdCueDuration, Name: "CueDuration", Type: ebml.TypeUint, Parent: IdCueTrackPositions},
+	{ID: IdCueBlockNumber, Name: "CueBlockNumber", Type: ebml.TypeUint, Parent: IdCueTrackPositions},
+	{ID: IdCueCodecState, Name: "CueCodecState", Type: ebml.TypeUint, Parent: IdCueTrackPositions},
+	{ID: IdCueReference, Name: "CueReference", Type: ebml.TypeList, Parent: IdCueTrackPositions},
+	{ID: IdCueRefTime, Name: "CueRefTime", Type: ebml.TypeUint, Parent: IdCueReference},
+	{ID: IdCueRefCluster, Name: "CueRefCluster", Type: ebml.TypeUint, Parent: IdCueReference},
+	{ID: IdCueRefNumber, Name: "CueRefNumber", Type: ebml.TypeUint, Parent: IdCueReference},
+	{ID: IdCueRefCodecState, Name: "CueRefCodecState", Type: ebml.TypeUint, Parent: IdCueReference},
+	{ID: IdAttachments, Name: "Attachments", Type: ebml.TypeList, Parent: IdSegment},
+	{ID: IdAttachedFile, Name: "AttachedFile", Type: ebml.TypeList, Parent: IdAttachments},
+	{ID: IdFileDescription, Name: "FileDescription", Type: ebml.TypeUTF8, Parent: IdAttachedFile},
+	{ID: IdFileName, Name: "FileName", Type: ebml.TypeUTF8, Parent: IdAttachedFile},
+	{ID: IdFileMimeType, Name: "FileMimeType", Type: ebml.TypeString, Parent: IdAttachedFile},
+	{ID: IdFileData, Name: "FileData", Type: ebml.TypeBinary, Parent: IdAttachedFile},
+	{ID: IdFileUID, Name: "FileUID", Type: ebml.TypeUint, Parent: IdAttachedFile},
+	{ID: IdFileReferral, Name: "FileReferral", Type: ebml.TypeBinary, Parent: IdAttachedFile},
+	{ID: IdFileUsedStartTime, Name: "FileUsedStartTime", Type: ebml.TypeUint, Parent: IdAttachedFile},
+	{ID: IdFileUsedEndTime, Name: "FileUsedEndTime", Type: ebml.TypeUint, Parent: IdAttachedFile},
+	{ID: IdChapters, Name: "Chapters", Type: ebml.TypeList, Parent: IdSegment},
+	{ID: IdEditionEntry, Name: "EditionEntry", Type: ebml.TypeList, Parent: IdChapters},
+	{ID: IdEditionUID, Name: "EditionUID", Type: ebml.TypeUint, Parent: IdEditionEntry},
+	{ID: IdEditionFlagHidden, Name: "EditionFlagHidden", Type: ebml.TypeUint, Parent: IdEditionEntry},
+	{ID: IdEditionFlagDefault, Name: "EditionFlagDefault", Type: ebml.TypeUint, Parent: IdEditionEntry},
+	{ID: IdEditionFlagOrdered, Name: "EditionFlagOrdered", Type: ebml.TypeUint, Parent: IdEditionEntry},
+	{ID: IdEditionDisplay, Name: "EditionDisplay", Type: ebml.TypeList, Parent: IdEditionEntry},
+	{ID: IdEditionString, Name: "EditionString", Type: ebml.TypeUTF8, Parent: IdEditionDisplay},
+	{ID: IdEditionLanguageIETF, Name: "EditionLanguageIETF", Type: ebml.TypeString, Parent: IdEditionDisplay},
+	{ID: IdChapterAtom, Name: "ChapterAtom", Type: ebml.TypeList, Parent: IdEditionEntry, Recursive: true},
+	{ID: IdChapterUID, Name: "ChapterUID", Type: ebml.TypeUint, Parent: IdChapterAtom},
+	{ID: IdChapterStringUID, Name: "ChapterStringUID", Type: ebml.TypeUTF8, Parent: IdChapterAtom},
+	{ID: IdChapterTimeStart, Name: "ChapterTimeStart", Type: ebml.TypeUint, Parent: IdChapterAtom},
+	{ID: IdChapterTimeEnd, Name: "ChapterTimeEnd", Type: ebml.TypeUint, Parent: IdChapterAtom},
+	{ID: IdChapterFlagHidden, Name: "ChapterFlagHidden", Type: ebml.TypeUint, Parent: IdChapterAtom},
+	{ID: IdChapterFlagEnabled, Name: "ChapterFlagEnabled", Type: ebml.TypeUint, Parent: IdChapterAtom},
+	{ID: IdChapterSegmentUID, Name: "ChapterSegmentUID", Type: ebml.TypeBinary, Parent: IdChapterAtom},
+	{ID: IdChapterSkipType, Name: "ChapterSkipType", Type: ebml.TypeUint, Parent: IdChapterAtom},
+	{ID: IdChapterSegmentEditionUID, Name: "ChapterSegmentEditionUID", Type: ebml.TypeUint, Parent: IdChapterAtom},
+	{ID: IdChapterPhysicalEquiv, Name: "ChapterPhysicalEquiv", Type: ebml.TypeUint, Parent: IdChapterAtom},
+	{ID: IdChapterTrack, Name: "ChapterTrack", Type: ebml.TypeList, Parent: IdChapterAtom},
+	{ID: IdChapterTrackNumber, Name: "ChapterTrackNumber", Type: ebml.TypeUint, Parent: IdChapterTrack},
+	{ID: IdChapterDisplay, Name: "ChapterDisplay", Type: ebml.TypeList, Parent: IdChapterAtom},
+	{ID: IdChapString, Name: "ChapString", Type: ebml.TypeUTF8, Parent: IdChapterDisplay},
+	{ID: IdChapLanguage, Name: "ChapLanguage", Type: ebml.TypeString, Parent: IdChapterDisplay},
+	{ID: IdChapLanguageBCP47, Name: "ChapLanguageBCP47", Type: ebml.TypeString, Parent: IdChapterDisplay},
+	{ID: IdChapCountry, Name: "ChapCountry", Type: ebml.TypeString, Parent: IdChapterDisplay},
+	{ID: IdChapProcess, Name: "ChapProcess", Type: ebml.TypeList, Parent: IdChapterAtom},
+	{ID: IdChapProcessCodecID, Name: "ChapProcessCodecID", Type: ebml.TypeUint, Parent: IdChapProcess},
+	{ID: IdChapProcessPrivate, Name: "ChapProcessPrivate", Type: ebml.TypeBinary, Parent: IdChapProcess},
+	{ID: IdChapProcessCommand, Name: "ChapProcessCommand", Type: ebml.TypeList, Parent: IdChapProcess},
+	{ID: IdChapProcessTime, Name: "ChapProcessTime", Type: ebml.TypeUint, Parent: IdChapProcessCommand},
+	{ID: IdChapProcessData, Name: "ChapProcessData", Type: ebml.TypeBinary, Parent: IdChapProcessCommand},
+	{ID: IdTags, Name: "Tags", Type: ebml.TypeList, Parent: IdSegment},
+	{ID: IdTag, Name: "Tag", Type: ebml.TypeList, Parent: IdTags},
+	{ID: IdTargets, Name: "Targets", Type: ebml.TypeList, Parent: IdTag},
+	{ID: IdTargetTypeValue, Name: "TargetTypeValue", Type: ebml.TypeUint, Parent: IdTargets},
+	{ID: IdTargetType, Name: "TargetType", Type: ebml.TypeString, Parent: IdTargets},
+	{ID: IdTagTrackUID, Name: "TagTrackUID", Type: ebml.TypeUint, Parent: IdTargets},
+	{ID: IdTagEditionUID, Name: "TagEditionUID", Type: ebml.TypeUint, Parent: IdTargets},
+	{ID: IdTagChapterUID, Name: "TagChapterUID", Type: ebml.TypeUint, Parent: IdTargets},
+	{ID: IdTagAttachmentUID, Name: "TagAttachmentUID", Type: ebml.TypeUint, Parent: IdTargets},
+	{ID: IdSimpleTag, Name: "SimpleTag", Type: ebml.TypeList, Parent: IdTag, Recursive: true},
+	{ID: IdTagName, Name: "TagName", Type: ebml.TypeUTF8, Parent: IdSimpleTag},
+	{ID: IdTagLanguage, Name: "TagLanguage", Type: ebml.TypeString, Parent: IdSimpleTag},
+	{ID: IdTagLanguageBCP47, Name: "TagLanguageBCP47", Type: ebml.TypeString, Parent: IdSimpleTag},
+	{ID: IdTagDefault, Name: "TagDefault", Type: ebml.TypeUint, Parent: IdSimpleTag},
+	{ID: IdTagDefaultBogus, Name: "TagDefaultBogus", Type: ebml.TypeUint, Parent: IdSimpleTag},
+	{ID: IdTagString, Name: "TagString", Type: ebml.TypeUTF8, Parent: IdSimpleTag},
+	{ID: IdTagBinary, Name: "TagBinary", Type: ebml.TypeBinary, Parent: IdSimpleTag},
 }
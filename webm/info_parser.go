@@ -15,117 +15,58 @@
 package webm
 
 import (
+	"errors"
+	"fmt"
 	"github.com/acolwell/mse-tools/ebml"
-	"log"
 	"math"
+	"time"
 )
 
 type InfoElement interface {
 	TimecodeScale() uint64
 	Duration() float64
-	Date() int64
+	Date() time.Time
 }
 
-type infoParserClient struct {
-	timecodeScale uint64
-	duration      float64
-	date          int64
+type infoElement struct {
+	TimecodeScale uint64    `ebml:"0x2AD7B1"`
+	Duration      float64   `ebml:"0x4489"`
+	DateUTC       time.Time `ebml:"0x4461"`
 }
 
-func (p *infoParserClient) TimecodeScale() uint64 {
-	return p.timecodeScale
+type info struct {
+	element infoElement
 }
 
-func (p *infoParserClient) Duration() float64 {
-	return p.duration
+func (i *info) TimecodeScale() uint64 {
+	return i.element.TimecodeScale
 }
 
-func (p *infoParserClient) Date() int64 {
-	return p.date
+func (i *info) Duration() float64 {
+	return i.element.Duration
 }
 
-func (p *infoParserClient) OnListStart(offset int64, id int) bool {
-	return false
+func (i *info) Date() time.Time {
+	return i.element.DateUTC
 }
 
-func (p *infoParserClient) OnListEnd(offset int64, id int) bool {
-	return false
-}
-
-func (p *infoParserClient) OnBinary(id int, value []byte) bool {
-	switch id {
-	case ebml.IdCRC32,
-		ebml.IdVoid,
-		IdSegmentUID,
-		IdSegmentFilename,
-		IdPrevUID,
-		IdPrevFilename,
-		IdNextUID,
-		IdNextFilename,
-		IdSegmentFamily,
-		IdChapterTranslate,
-		IdChapterTranslateEditionUID,
-		IdChapterTranslateCodec,
-		IdChapterTranslateID,
-		IdTitle,
-		IdMuxingApp,
-		IdWritingApp:
-		return true
-	}
-	return false
-}
-
-func (p *infoParserClient) OnInt(id int, value int64) bool {
-	if id != IdDateUTC {
-		return false
-	}
-	p.date = value
-	return true
-}
-
-func (p *infoParserClient) OnUint(id int, value uint64) bool {
-	if id == IdTimecodeScale {
-		p.timecodeScale = value
-		return true
-	}
-
-	return false
-}
+func ParseInfoElement(buf []byte) (InfoElement, error) {
+	i := &info{element: infoElement{
+		TimecodeScale: 1000000,
+		Duration:      math.Inf(1),
+		DateUTC:       ebml.DateEpoch}}
 
-func (p *infoParserClient) OnFloat(id int, value float64) bool {
-	if id != IdDuration {
-		return false
+	if err := ebml.Unmarshal(buf, &i.element); err != nil {
+		return nil, err
 	}
-	p.duration = value
-	return true
-}
-
-func (p *infoParserClient) OnString(id int, value string) bool {
-	return false
-}
-
-func ParseInfoElement(buf []byte) InfoElement {
-	typeInfo := map[int]int{
-		IdTimecodeScale: ebml.TypeUint,
-		IdDuration:      ebml.TypeFloat,
-		IdDateUTC:       ebml.TypeInt}
-
-	client := &infoParserClient{
-		timecodeScale: 1000000,
-		duration:      math.Inf(1),
-		date:          0}
-	parser := ebml.NewParser(ebml.GetListIDs(typeInfo), map[int][]int{},
-		ebml.NewElementParser(client, typeInfo))
 
-	if !parser.Append(buf) {
-		log.Printf("Failed to parse info.")
-		return nil
+	if i.TimecodeScale() == 0 {
+		return nil, errors.New("invalid TimecodeScale 0")
 	}
 
-	if client.TimecodeScale() == 0 ||
-		client.Duration() <= 0 {
-		return nil
+	if i.Duration() <= 0 {
+		return nil, fmt.Errorf("invalid Duration %f", i.Duration())
 	}
 
-	return client
+	return i, nil
 }
@@ -0,0 +1,28 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import "github.com/acolwell/mse-tools/ebml"
+
+// Find returns the first element in data that matches a path like
+// "Segment/Tracks/TrackEntry[TrackType=1]/CodecID" or nil if there isn't
+// one. See ebml.FindAllNodes() for the path syntax.
+func Find(data []byte, path string) (*ebml.Node, error) {
+	return ebml.Find(data, path, schema)
+}
+
+func FindAll(data []byte, path string) ([]*ebml.Node, error) {
+	return ebml.FindAll(data, path, schema)
+}
@@ -0,0 +1,30 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import "github.com/acolwell/mse-tools/ebml"
+
+// ResyncIDs returns the IDs of the Level 1 elements, mapped to their parent,
+// for use with ebml.Parser.SetResyncIDs(). Their 4 byte IDs are unlikely to
+// show up by accident in corrupt data.
+func ResyncIDs() map[int]int {
+	resyncIDs := map[int]int{ebml.IdHeader: -1}
+	for _, def := range schema.Definitions() {
+		if schema.Level(def.ID) == 1 {
+			resyncIDs[def.ID] = def.Parent
+		}
+	}
+	return resyncIDs
+}
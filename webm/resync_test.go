@@ -0,0 +1,147 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"fmt"
+	"github.com/acolwell/mse-tools/ebml"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// resyncClient records lists, Uints, Strings and resyncs by element name.
+type resyncClient struct {
+	events []string
+}
+
+func (c *resyncClient) add(format string, args ...interface{}) error {
+	c.events = append(c.events, fmt.Sprintf(format, args...))
+	return nil
+}
+
+func (c *resyncClient) OnListStart(offset int64, id int) error {
+	return c.add("start %s", IdToName(id))
+}
+func (c *resyncClient) OnListEnd(offset int64, id int) error {
+	return c.add("end %s", IdToName(id))
+}
+func (c *resyncClient) OnUint(id int, value uint64) error {
+	return c.add("%s %d", IdToName(id), value)
+}
+func (c *resyncClient) OnString(id int, value string) error {
+	return c.add("%s %s", IdToName(id), value)
+}
+func (c *resyncClient) OnResync(offset int64, size int64) error {
+	return c.add("resync %d+%d", offset, size)
+}
+func (c *resyncClient) OnBinary(id int, value []byte) error  { return nil }
+func (c *resyncClient) OnInt(id int, value int64) error      { return nil }
+func (c *resyncClient) OnFloat(id int, value float64) error  { return nil }
+func (c *resyncClient) OnDate(id int, value time.Time) error { return nil }
+
+func TestResyncIDs(t *testing.T) {
+	resyncIDs := ResyncIDs()
+	for id, parent := range map[int]int{ebml.IdHeader: -1, IdInfo: IdSegment, IdTracks: IdSegment, IdCluster: IdSegment, IdCues: IdSegment} {
+		if got, present := resyncIDs[id]; !present || got != parent {
+			t.Errorf("got %d, %v for %s, want %d", got, present, IdToName(id), parent)
+		}
+	}
+	for _, id := range []int{IdSegment, IdTrackEntry, IdTimecode, IdSimpleBlock} {
+		if _, present := resyncIDs[id]; present {
+			t.Errorf("%s is a resync ID", IdToName(id))
+		}
+	}
+}
+
+func TestResync(t *testing.T) {
+	const (
+		header   = "1A45DFA3 87 4282 84 7765626D"
+		segment  = "18538067 01FFFFFFFFFFFFFF"
+		tracks   = "1654AE6B 85 AE 83 D7 81 01"
+		cluster0 = "1F43B675 83 E7 81 00"
+		cluster1 = "1F43B675 83 E7 81 01"
+	)
+	tracksEvents := []string{"start Tracks", "start TrackEntry", "TrackNumber 1", "end TrackEntry", "end Tracks"}
+
+	tests := []struct {
+		name string
+		data string
+		want [][]string
+	}{
+		{
+			// The Info at 24 has an invalid ID at 29 and Tracks at 37.
+			"invalid ID in Info",
+			header + segment + "1549A966 88 00 2AD7B1 83 0F4240" + tracks + cluster0,
+			[][]string{
+				{"start Info", "resync 29+8", "end Info"},
+				tracksEvents,
+				{"start Cluster", "Timecode 0", "end Cluster"},
+			},
+		},
+		{
+			// The SimpleBlock at 54 in the first Cluster is larger than the
+			// Cluster. The next Cluster is at 59.
+			"SimpleBlock larger than its Cluster",
+			header + segment + "1549A966 87 2AD7B1 83 0F4240" + tracks + "1F43B675 88 E7 81 00 A3 89 81 0000" + cluster1,
+			[][]string{
+				{"start Info", "TimecodeScale 1000000", "end Info"},
+				tracksEvents,
+				{"start Cluster", "Timecode 0", "resync 54+5", "end Cluster"},
+				{"start Cluster", "Timecode 1", "end Cluster"},
+			},
+		},
+		{
+			// Garbage between the Clusters at 34 and 46.
+			"garbage between Clusters",
+			header + segment + tracks + cluster0 + "FF 00 1F43" + cluster1,
+			[][]string{
+				tracksEvents,
+				{"start Cluster", "Timecode 0", "end Cluster", "resync 42+4"},
+				{"start Cluster", "Timecode 1", "end Cluster"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		want := []string{"start EBMLHeader", "DocType webm", "end EBMLHeader", "start Segment"}
+		for _, events := range test.want {
+			want = append(want, events...)
+		}
+		want = append(want, "end Segment")
+
+		data := fromHex(t, test.data)
+		for _, chunkSize := range []int{len(data), 1, 7} {
+			c := &resyncClient{}
+			p := ebml.NewParser(Schema(), ebml.NewElementParser(c, Schema()))
+			p.SetResyncIDs(ResyncIDs())
+			for i := 0; i < len(data); i += chunkSize {
+				end := i + chunkSize
+				if end > len(data) {
+					end = len(data)
+				}
+				if err := p.Append(data[i:end]); err != nil {
+					t.Fatalf("%s: %v", test.name, err)
+				}
+			}
+			if err := p.EndOfData(); err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+			if !reflect.DeepEqual(c.events, want) {
+				t.Errorf("%s in %d byte chunks: got %q, want %q", test.name, chunkSize, c.events, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import "github.com/acolwell/mse-tools/ebml"
+
+var schema = func() *ebml.Schema {
+	s, err := ebml.HeaderSchema().Extend(elementDefs...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}()
+
+// Schema returns the Schema for WebM files. Use Schema().Extend() to add
+// private or extension elements.
+func Schema() *ebml.Schema {
+	return schema
+}
+
+// IdTypes returns a new map from element ID to type that callers are free
+// to modify.
+func IdTypes() map[int]int {
+	return schema.TypeMap()
+}
+
+func IdToName(id int) string {
+	if _, ok := schema.Definition(id); ok {
+		return schema.Name(id)
+	}
+	return ebml.IdToName(id)
+}
+
+// ParentID returns the ID of the element's parent or -1 for top level
+// and unknown elements. Recursive elements report the parent of their
+// shallowest occurrence.
+func ParentID(id int) int {
+	if def, ok := schema.Definition(id); ok {
+		return def.Parent
+	}
+	return -1
+}
+
+// Level returns the element's depth below the top level (Segment is level
+// 0) or -1 for unknown elements.
+func Level(id int) int {
+	return schema.Level(id)
+}
+
+func IsRecursive(id int) bool {
+	def, _ := schema.Definition(id)
+	return def.Recursive
+}
+
+func UnknownSizeInfo() map[int][]int {
+	return schema.UnknownSizeInfo()
+}
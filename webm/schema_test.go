@@ -0,0 +1,157 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"bytes"
+	"github.com/acolwell/mse-tools/ebml"
+	"sync"
+	"testing"
+)
+
+const (
+	testIdPrivateData  = 0x7FA0
+	testIdPrivateValue = 0x7FA1
+)
+
+var privateDefs = []ebml.ElementDef{
+	{ID: testIdPrivateData, Name: "PrivateData", Type: ebml.TypeList, Parent: IdTag},
+	{ID: testIdPrivateValue, Name: "PrivateValue", Type: ebml.TypeBinary, Parent: testIdPrivateData},
+}
+
+// writePrivateTags returns a Tags element holding a Tag with a SimpleTag
+// and a PrivateData list.
+func writePrivateTags(t *testing.T) []byte {
+	tag, err := ebml.EncodeNodes([]*ebml.Node{
+		ebml.NewListNode(IdSimpleTag,
+			ebml.NewNode(IdTagName, ebml.TypeUTF8, "TITLE"),
+			ebml.NewNode(IdTagString, ebml.TypeUTF8, "test"),
+		),
+		ebml.NewListNode(testIdPrivateData,
+			ebml.NewNode(testIdPrivateValue, ebml.TypeBinary, []byte("secret")),
+		),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bw := ebml.NewBufferWriter(1024)
+	w := ebml.NewWriter(bw)
+	if err := w.WriteListStart(IdTags); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(IdTag, tag); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteListEnd(IdTags); err != nil {
+		t.Fatal(err)
+	}
+	return bw.Bytes()
+}
+
+func TestPrivateElements(t *testing.T) {
+	s, err := Schema().Extend(privateDefs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name(testIdPrivateData) != "PrivateData" || s.Name(testIdPrivateValue) != "PrivateValue" {
+		t.Errorf("got names %s and %s", s.Name(testIdPrivateData), s.Name(testIdPrivateValue))
+	}
+	if s.Level(testIdPrivateValue) != 4 {
+		t.Errorf("got level %d for PrivateValue, want 4", s.Level(testIdPrivateValue))
+	}
+	if s.Name(IdSimpleTag) != "SimpleTag" {
+		t.Errorf("got %s for SimpleTag", s.Name(IdSimpleTag))
+	}
+
+	// Extending the schema doesn't register the elements globally.
+	if _, ok := Schema().Definition(testIdPrivateData); ok {
+		t.Error("the WebM schema has a PrivateData element")
+	}
+	if name := IdToName(testIdPrivateData); name == "PrivateData" {
+		t.Errorf("IdToName() returned %s", name)
+	}
+
+	data := writePrivateTags(t)
+	filtered, err := ebml.Filter(data, []int{testIdPrivateValue}, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := ebml.ParseNodes(filtered, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || nodes[0].Lookup(IdTag, testIdPrivateData) == nil {
+		t.Fatalf("PrivateData is missing from the filtered data %X", filtered)
+	}
+	if nodes[0].Lookup(IdTag, testIdPrivateData, testIdPrivateValue) != nil {
+		t.Error("PrivateValue wasn't filtered out")
+	}
+	if n := nodes[0].Lookup(IdTag, IdSimpleTag, IdTagString); n == nil || n.Value != "test" {
+		t.Errorf("got TagString %+v", n)
+	}
+
+	// Without the definitions PrivateData is an opaque binary element.
+	unchanged, err := Filter(data, []int{testIdPrivateValue})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(unchanged, fromHex(t, "7FA0 89 7FA1 86 736563726574")) {
+		t.Errorf("PrivateData was changed in %X", unchanged)
+	}
+
+	if n, err := ebml.Find(data, "Tags/Tag/PrivateData/PrivateValue", s); err != nil || n == nil || string(n.Value.([]byte)) != "secret" {
+		t.Errorf("got %+v, %v", n, err)
+	}
+}
+
+// TestSchemaConcurrentUse is meant to be run with -race.
+func TestSchemaConcurrentUse(t *testing.T) {
+	data := writePrivateTags(t)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s, err := Schema().Extend(privateDefs...)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			IdTypes()[IdTags] = i
+			IdToName(IdSimpleTag)
+			Level(IdTagString)
+			ParentID(IdTag)
+			IsRecursive(IdSimpleTag)
+			UnknownSizeInfo()[IdSegment] = nil
+			Schema().WithTypes(map[int]int{IdTags: ebml.TypeList})
+			if _, err := Filter(data, []int{IdSimpleTag}); err != nil {
+				t.Error(err)
+			}
+			if _, err := ebml.Filter(data, []int{testIdPrivateValue}, s); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if elementType, _ := Schema().Type(IdTags); elementType != ebml.TypeList {
+		t.Errorf("got type %d for Tags, want a list", elementType)
+	}
+	if len(UnknownSizeInfo()[IdSegment]) == 0 {
+		t.Error("the Segment unknown size info was changed")
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"fmt"
+	"github.com/acolwell/mse-tools/ebml"
+)
+
+// Seek points at a Level 1 element. SeekPosition is relative to the start of
+// the Segment data.
+type Seek struct {
+	SeekID       []byte `ebml:"0x53AB"`
+	SeekPosition uint64 `ebml:"0x53AC"`
+}
+
+// ID returns SeekID as an element ID.
+func (s *Seek) ID() int {
+	id := 0
+	for _, b := range s.SeekID {
+		id = (id << 8) | int(b)
+	}
+	return id
+}
+
+type SeekHead struct {
+	Seeks []Seek `ebml:"0x4DBB"`
+}
+
+// ParseSeekHead parses the body of a SeekHead element.
+func ParseSeekHead(buf []byte) (*SeekHead, error) {
+	seekHead := &SeekHead{Seeks: []Seek{}}
+	if err := ebml.Unmarshal(buf, seekHead); err != nil {
+		return nil, err
+	}
+	return seekHead, nil
+}
+
+// Add appends an entry for the element id at position.
+func (s *SeekHead) Add(id int, position uint64) {
+	idBytes := []byte{}
+	for ; id > 0; id >>= 8 {
+		idBytes = append([]byte{byte(id & 0xff)}, idBytes...)
+	}
+	s.Seeks = append(s.Seeks, Seek{SeekID: idBytes, SeekPosition: position})
+}
+
+// Position returns the SeekPosition of the first entry for the element id.
+func (s *SeekHead) Position(id int) (uint64, bool) {
+	for i := range s.Seeks {
+		if s.Seeks[i].ID() == id {
+			return s.Seeks[i].SeekPosition, true
+		}
+	}
+	return 0, false
+}
+
+// Write writes a SeekHead element followed by a Void element that pads the
+// output to reservedSize bytes. This allows a SeekHead to be written over
+// space that was reserved before the element positions were known. A
+// reservedSize of 0 disables padding. An error is returned, and nothing is
+// written, if the SeekHead doesn't fit in reservedSize.
+func (s *SeekHead) Write(w *ebml.Writer, reservedSize int) error {
+	// SeekPosition is always written with 8 bytes so the element size
+	// only depends on the number of entries and the SeekID lengths.
+	size := 0
+	for i := range s.Seeks {
+		if seekBodySize(&s.Seeks[i]) > maxSeekBodySize {
+			return fmt.Errorf("webm: %d byte SeekID is too long", len(s.Seeks[i].SeekID))
+		}
+		size += 2 + 1 + seekBodySize(&s.Seeks[i])
+	}
+	total := 4 + 8 + size
+
+	padding := 0
+	if reservedSize > 0 {
+		padding = reservedSize - total
+		if padding < 0 || padding == 1 {
+			return fmt.Errorf("webm: SeekHead needs %d bytes but only %d are reserved", total, reservedSize)
+		}
+	}
+
+	if _, err := w.WriteElementHeader(IdSeekHead, int64(size), 8); err != nil {
+		return err
+	}
+	for i := range s.Seeks {
+		seek := &s.Seeks[i]
+		position := make([]byte, 8)
+		for j, p := 7, seek.SeekPosition; j >= 0; j, p = j-1, p>>8 {
+			position[j] = byte(p)
+		}
+
+		if _, err := w.WriteElementHeader(IdSeek, int64(seekBodySize(seek)), 1); err != nil {
+			return err
+		}
+		if _, err := w.WriteElementHeader(IdSeekID, int64(len(seek.SeekID)), 1); err != nil {
+			return err
+		}
+		if _, err := w.WriteToOutput(seek.SeekID); err != nil {
+			return err
+		}
+		if _, err := w.WriteElementHeader(IdSeekPosition, int64(len(position)), 1); err != nil {
+			return err
+		}
+		if _, err := w.WriteToOutput(position); err != nil {
+			return err
+		}
+	}
+
+	if padding > 0 {
+		if _, err := w.WriteVoid(padding); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxSeekBodySize is the largest size that fits in the 1 byte size field
+// Write() uses for Seek elements.
+const maxSeekBodySize = 0x7e
+
+// seekBodySize returns the size of the Seek body written by Write().
+func seekBodySize(seek *Seek) int {
+	return 2 + 1 + len(seek.SeekID) + 2 + 1 + 8
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"github.com/acolwell/mse-tools/ebml"
+	"testing"
+)
+
+func writeSeekHead(s *SeekHead, reservedSize int) ([]byte, error) {
+	bw := ebml.NewBufferWriter(1024)
+	if err := s.Write(ebml.NewWriter(bw), reservedSize); err != nil {
+		return nil, err
+	}
+	return bw.Bytes(), nil
+}
+
+func TestSeekHeadRoundTrip(t *testing.T) {
+	s := &SeekHead{}
+	s.Add(IdInfo, 180)
+	s.Add(IdTracks, 300)
+	s.Add(IdCues, 1<<40)
+
+	body := encodeBody(t, IdSeekHead, func(w *ebml.Writer) error { return s.Write(w, 0) })
+	parsed, err := ParseSeekHead(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Seeks) != 3 {
+		t.Fatalf("got %d seeks, want 3", len(parsed.Seeks))
+	}
+	for _, want := range []struct {
+		id       int
+		position uint64
+	}{{IdInfo, 180}, {IdTracks, 300}, {IdCues, 1 << 40}} {
+		if position, ok := parsed.Position(want.id); !ok || position != want.position {
+			t.Errorf("Position(%s) got %d %v, want %d", IdToName(want.id), position, ok, want.position)
+		}
+	}
+	if _, ok := parsed.Position(IdChapters); ok {
+		t.Errorf("Position() found an element that isn't in the SeekHead")
+	}
+	if parsed.Seeks[1].ID() != IdTracks {
+		t.Errorf("got ID 0x%X, want 0x%X", parsed.Seeks[1].ID(), IdTracks)
+	}
+}
+
+func TestSeekHeadFixedSize(t *testing.T) {
+	small := &SeekHead{}
+	large := &SeekHead{}
+	for _, id := range []int{IdInfo, IdTracks, IdCluster, IdCues, IdTags, IdChapters} {
+		small.Add(id, 0)
+		large.Add(id, 1<<60)
+	}
+
+	smallData, err := writeSeekHead(small, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	largeData, err := writeSeekHead(large, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(smallData) != len(largeData) || len(smallData) != 4+8+6*21 {
+		t.Errorf("got sizes %d and %d, want %d", len(smallData), len(largeData), 4+8+6*21)
+	}
+}
+
+func TestSeekHeadReservedSize(t *testing.T) {
+	s := &SeekHead{}
+	s.Add(IdInfo, 100)
+	size := 4 + 8 + 21
+
+	data, err := writeSeekHead(s, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 100 {
+		t.Errorf("got %d bytes, want 100", len(data))
+	}
+	if data[size] != 0xEC {
+		t.Errorf("got 0x%X after the SeekHead, want a Void element", data[size])
+	}
+
+	if data, err := writeSeekHead(s, size); err != nil || len(data) != size {
+		t.Errorf("got %d bytes %v for an exact fit, want %d", len(data), err, size)
+	}
+	// A Void element needs at least 2 bytes.
+	if _, err := writeSeekHead(s, size+1); err == nil {
+		t.Errorf("Write() accepted a 1 byte gap")
+	}
+	if _, err := writeSeekHead(s, size-1); err == nil {
+		t.Errorf("Write() accepted a reserved size that is too small")
+	}
+}
+
+func TestSeekHeadLongSeekID(t *testing.T) {
+	for _, test := range []struct {
+		length int
+		ok     bool
+	}{{112, true}, {113, false}, {126, false}, {127, false}} {
+		s := &SeekHead{}
+		s.Add(IdInfo, 100)
+		s.Seeks = append(s.Seeks, Seek{SeekID: make([]byte, test.length), SeekPosition: 200})
+
+		bw := ebml.NewBufferWriter(1024)
+		err := s.Write(ebml.NewWriter(bw), 0)
+		if !test.ok {
+			if err == nil {
+				t.Errorf("Write() accepted a %d byte SeekID", test.length)
+			}
+			if len(bw.Bytes()) != 0 {
+				t.Errorf("Write() wrote %d bytes for a %d byte SeekID", len(bw.Bytes()), test.length)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%d byte SeekID: %v", test.length, err)
+			continue
+		}
+		parsed, err := ParseSeekHead(bw.Bytes()[12:])
+		if err != nil {
+			t.Errorf("%d byte SeekID: %v", test.length, err)
+			continue
+		}
+		if len(parsed.Seeks) != 2 || len(parsed.Seeks[1].SeekID) != test.length || parsed.Seeks[1].SeekPosition != 200 {
+			t.Errorf("got %+v", parsed.Seeks)
+		}
+	}
+}
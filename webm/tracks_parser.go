@@ -16,7 +16,6 @@ package webm
 
 import (
 	"github.com/acolwell/mse-tools/ebml"
-	"log"
 )
 
 const (
@@ -28,111 +27,185 @@ type Track interface {
 	ID() uint64
 	Type() int
 	CodecID() string
+	Entry() *TrackEntry
 }
 
-type tracksParserClient struct {
-	tracks      []Track
-	trackNumber uint64
-	trackType   int
-	codecID     string
+// TrackEntry holds the elements of a TrackEntry. Elements that are missing
+// get their default values.
+type TrackEntry struct {
+	TrackNumber      uint64            `ebml:"0xD7"`
+	TrackUID         uint64            `ebml:"0x73C5,omitempty"`
+	TrackType        uint64            `ebml:"0x83"`
+	FlagEnabled      bool              `ebml:"0xB9,default=1"`
+	FlagDefault      bool              `ebml:"0x88,default=1"`
+	FlagForced       bool              `ebml:"0x55AA,omitempty"`
+	FlagLacing       bool              `ebml:"0x9C,default=1"`
+	DefaultDuration  uint64            `ebml:"0x23E383,omitempty"`
+	Name             string            `ebml:"0x536E,omitempty"`
+	Language         string            `ebml:"0x22B59C,default=eng"`
+	CodecID          string            `ebml:"0x86"`
+	CodecPrivate     []byte            `ebml:"0x63A2,omitempty"`
+	CodecName        string            `ebml:"0x258688,omitempty"`
+	CodecDelay       uint64            `ebml:"0x56AA,omitempty"`
+	SeekPreRoll      uint64            `ebml:"0x56BB,omitempty"`
+	Video            *VideoSettings    `ebml:"0xE0"`
+	Audio            *AudioSettings    `ebml:"0xE1"`
+	ContentEncodings *ContentEncodings `ebml:"0x6D80"`
 }
 
-type track struct {
-	id        uint64
-	trackType int
-	codecID   string
+// VideoSettings holds the Video elements of a track. DisplayWidth and
+// DisplayHeight are 0 when they aren't present, which means the display
+// size is the pixel size.
+type VideoSettings struct {
+	FlagInterlaced  uint64      `ebml:"0x9A,omitempty"`
+	StereoMode      uint64      `ebml:"0x53B8,omitempty"`
+	AlphaMode       uint64      `ebml:"0x53C0,omitempty"`
+	PixelWidth      uint64      `ebml:"0xB0"`
+	PixelHeight     uint64      `ebml:"0xBA"`
+	PixelCropBottom uint64      `ebml:"0x54AA,omitempty"`
+	PixelCropTop    uint64      `ebml:"0x54BB,omitempty"`
+	PixelCropLeft   uint64      `ebml:"0x54CC,omitempty"`
+	PixelCropRight  uint64      `ebml:"0x54DD,omitempty"`
+	DisplayWidth    uint64      `ebml:"0x54B0,omitempty"`
+	DisplayHeight   uint64      `ebml:"0x54BA,omitempty"`
+	DisplayUnit     uint64      `ebml:"0x54B2,omitempty"`
+	AspectRatioType uint64      `ebml:"0x54B3,omitempty"`
+	FrameRate       float64     `ebml:"0x2383E3,omitempty"`
+	Colour          *Colour     `ebml:"0x55B0"`
+	Projection      *Projection `ebml:"0x7670"`
 }
 
-func (t *track) ID() uint64 {
-	return t.id
+// Colour describes the colour format of a video track. Elements with a
+// default of 2 mean unspecified.
+type Colour struct {
+	MatrixCoefficients      uint64             `ebml:"0x55B1,default=2"`
+	BitsPerChannel          uint64             `ebml:"0x55B2,omitempty"`
+	ChromaSubsamplingHorz   uint64             `ebml:"0x55B3,omitempty"`
+	ChromaSubsamplingVert   uint64             `ebml:"0x55B4,omitempty"`
+	CbSubsamplingHorz       uint64             `ebml:"0x55B5,omitempty"`
+	CbSubsamplingVert       uint64             `ebml:"0x55B6,omitempty"`
+	ChromaSitingHorz        uint64             `ebml:"0x55B7,omitempty"`
+	ChromaSitingVert        uint64             `ebml:"0x55B8,omitempty"`
+	Range                   uint64             `ebml:"0x55B9,omitempty"`
+	TransferCharacteristics uint64             `ebml:"0x55BA,default=2"`
+	Primaries               uint64             `ebml:"0x55BB,default=2"`
+	MaxCLL                  uint64             `ebml:"0x55BC,omitempty"`
+	MaxFALL                 uint64             `ebml:"0x55BD,omitempty"`
+	MasteringMetadata       *MasteringMetadata `ebml:"0x55D0"`
 }
 
-func (t *track) Type() int {
-	return t.trackType
+type MasteringMetadata struct {
+	PrimaryRChromaticityX   float64 `ebml:"0x55D1,omitempty"`
+	PrimaryRChromaticityY   float64 `ebml:"0x55D2,omitempty"`
+	PrimaryGChromaticityX   float64 `ebml:"0x55D3,omitempty"`
+	PrimaryGChromaticityY   float64 `ebml:"0x55D4,omitempty"`
+	PrimaryBChromaticityX   float64 `ebml:"0x55D5,omitempty"`
+	PrimaryBChromaticityY   float64 `ebml:"0x55D6,omitempty"`
+	WhitePointChromaticityX float64 `ebml:"0x55D7,omitempty"`
+	WhitePointChromaticityY float64 `ebml:"0x55D8,omitempty"`
+	LuminanceMax            float64 `ebml:"0x55D9,omitempty"`
+	LuminanceMin            float64 `ebml:"0x55DA,omitempty"`
 }
 
-func (t *track) CodecID() string {
-	return t.codecID
+type Projection struct {
+	ProjectionType      uint64  `ebml:"0x7671"`
+	ProjectionPrivate   []byte  `ebml:"0x7672,omitempty"`
+	ProjectionPoseYaw   float64 `ebml:"0x7673,omitempty"`
+	ProjectionPosePitch float64 `ebml:"0x7674,omitempty"`
+	ProjectionPoseRoll  float64 `ebml:"0x7675,omitempty"`
 }
 
-func (p *tracksParserClient) Tracks() []Track {
-	return p.tracks
+// AudioSettings holds the Audio elements of a track.
+// OutputSamplingFrequency is 0 when it is the same as SamplingFrequency.
+type AudioSettings struct {
+	SamplingFrequency       float64 `ebml:"0xB5,default=8000"`
+	OutputSamplingFrequency float64 `ebml:"0x78B5,omitempty"`
+	Channels                uint64  `ebml:"0x9F,default=1"`
+	BitDepth                uint64  `ebml:"0x6264,omitempty"`
 }
 
-func (p *tracksParserClient) OnListStart(offset int64, id int) bool {
-	if id != IdTrackEntry {
-		return false
-	}
+type ContentEncodings struct {
+	ContentEncoding []ContentEncoding `ebml:"0x6240"`
+}
 
-	p.trackNumber = 0
-	p.trackType = 0
-	p.codecID = ""
+type ContentEncoding struct {
+	ContentEncodingOrder uint64              `ebml:"0x5031"`
+	ContentEncodingScope uint64              `ebml:"0x5032,default=1"`
+	ContentEncodingType  uint64              `ebml:"0x5033"`
+	ContentCompression   *ContentCompression `ebml:"0x5034"`
+	ContentEncryption    *ContentEncryption  `ebml:"0x5035"`
+}
 
-	return true
+type ContentCompression struct {
+	ContentCompAlgo     uint64 `ebml:"0x4254"`
+	ContentCompSettings []byte `ebml:"0x4255,omitempty"`
 }
 
-func (p *tracksParserClient) OnListEnd(offset int64, id int) bool {
-	if id != IdTrackEntry {
-		return false
-	}
+type ContentEncryption struct {
+	ContentEncAlgo        uint64                 `ebml:"0x47E1"`
+	ContentEncKeyID       []byte                 `ebml:"0x47E2,omitempty"`
+	ContentEncAESSettings *ContentEncAESSettings `ebml:"0x47E7"`
+}
 
-	p.tracks = append(p.tracks, &track{id: p.trackNumber, trackType: p.trackType, codecID: p.codecID})
-	return true
+type ContentEncAESSettings struct {
+	AESSettingsCipherMode uint64 `ebml:"0x47E8"`
 }
 
-func (p *tracksParserClient) OnBinary(id int, value []byte) bool {
-	return true
+type tracksElement struct {
+	Entries []TrackEntry `ebml:"0xAE"`
 }
 
-func (p *tracksParserClient) OnInt(id int, value int64) bool {
-	return false
+type track struct {
+	entry TrackEntry
 }
 
-func (p *tracksParserClient) OnUint(id int, value uint64) bool {
-	if id == IdTrackNumber {
-		p.trackNumber = value
-		return true
-	}
+func (t *track) ID() uint64 {
+	return t.entry.TrackNumber
+}
 
-	if id == IdTrackType {
-		p.trackType = int(value)
-		return true
-	}
+func (t *track) Type() int {
+	return int(t.entry.TrackType)
+}
 
-	return false
+func (t *track) CodecID() string {
+	return t.entry.CodecID
 }
 
-func (p *tracksParserClient) OnFloat(id int, value float64) bool {
-	return false
+func (t *track) Entry() *TrackEntry {
+	return &t.entry
 }
 
-func (p *tracksParserClient) OnString(id int, value string) bool {
-	if id == IdCodecID {
-		p.codecID = value
-		return true
+func ParseTracksElement(buf []byte) ([]Track, error) {
+	element := tracksElement{}
+	if err := ebml.Unmarshal(buf, &element); err != nil {
+		return nil, err
 	}
-	return false
-}
-
-func ParseTracksElement(buf []byte) []Track {
-	typeInfo := map[int]int{
-		IdTrackEntry:  ebml.TypeList,
-		IdTrackNumber: ebml.TypeUint,
-		IdTrackType:   ebml.TypeUint,
-		IdCodecID:     ebml.TypeString}
-
-	client := &tracksParserClient{
-		tracks:      []Track{},
-		trackNumber: 0,
-		trackType:   0,
-		codecID:     ""}
-	parser := ebml.NewParser(ebml.GetListIDs(typeInfo), map[int][]int{},
-		ebml.NewElementParser(client, typeInfo))
-
-	if !parser.Append(buf) {
-		log.Printf("Failed to parse tracks.")
-		return nil
+
+	tracks := []Track{}
+	for i := range element.Entries {
+		tracks = append(tracks, &track{entry: element.Entries[i]})
+	}
+	return tracks, nil
+}
+
+// WriteTracksElement writes a Tracks element containing entries.
+func WriteTracksElement(w *ebml.Writer, entries []*TrackEntry) error {
+	bodies := make([][]byte, len(entries))
+	for i, entry := range entries {
+		body, err := ebml.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		bodies[i] = body
 	}
 
-	return client.Tracks()
+	if err := w.WriteListStart(IdTracks); err != nil {
+		return err
+	}
+	for _, body := range bodies {
+		if _, err := w.Write(IdTrackEntry, body); err != nil {
+			return err
+		}
+	}
+	return w.WriteListEnd(IdTracks)
 }
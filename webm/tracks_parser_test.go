@@ -0,0 +1,177 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webm
+
+import (
+	"github.com/acolwell/mse-tools/ebml"
+	"reflect"
+	"testing"
+)
+
+// testTracks returns the body of a Tracks element with a video track that
+// uses every nested list and an audio track that relies on the defaults.
+func testTracks(t *testing.T) []byte {
+	u := func(id int, value uint64) *ebml.Node { return ebml.NewNode(id, ebml.TypeUint, value) }
+	f := func(id int, value float64) *ebml.Node { return ebml.NewNode(id, ebml.TypeFloat, value) }
+
+	body, err := ebml.EncodeNodes([]*ebml.Node{
+		ebml.NewListNode(IdTrackEntry,
+			u(IdTrackNumber, 1),
+			u(IdTrackUID, 0x1234),
+			u(IdTrackType, uint64(VIDEO_TRACK)),
+			u(IdFlagDefault, 0),
+			u(IdFlagForced, 1),
+			u(IdDefaultDuration, 33366667),
+			ebml.NewNode(IdName, ebml.TypeUTF8, "Vidéo"),
+			ebml.NewNode(IdLanguage, ebml.TypeString, "fra"),
+			ebml.NewNode(IdCodecID, ebml.TypeString, "V_VP9"),
+			ebml.NewNode(IdCodecPrivate, ebml.TypeBinary, []byte{1, 2, 3}),
+			// Elements that TrackEntry doesn't model are dropped.
+			u(IdFlagHearingImpaired, 1),
+			ebml.NewListNode(IdVideo,
+				u(IdStereoMode, 1),
+				u(IdAlphaMode, 1),
+				u(IdPixelWidth, 1920),
+				u(IdPixelHeight, 1080),
+				u(IdDisplayWidth, 16),
+				u(IdDisplayHeight, 9),
+				u(IdDisplayUnit, 3),
+				ebml.NewListNode(IdColour,
+					u(IdMatrixCoefficients, 9),
+					u(IdBitsPerChannel, 10),
+					u(IdChromaSubsamplingHorz, 1),
+					u(IdRange, 1),
+					u(IdTransferCharacteristics, 16),
+					u(IdMaxCLL, 1000),
+					ebml.NewListNode(IdMasteringMetadata,
+						f(IdPrimaryRChromaticityX, 0.708),
+						f(IdWhitePointChromaticityY, 0.329),
+						f(IdLuminanceMax, 1000),
+						f(IdLuminanceMin, 0.005),
+					),
+				),
+				ebml.NewListNode(IdProjection,
+					u(IdProjectionType, 2),
+					ebml.NewNode(IdProjectionPrivate, ebml.TypeBinary, []byte{0, 0, 0, 0}),
+					f(IdProjectionPoseYaw, -90),
+				),
+			),
+			ebml.NewListNode(IdContentEncodings,
+				ebml.NewListNode(IdContentEncoding,
+					u(IdContentEncodingType, 1),
+					ebml.NewListNode(IdContentEncryption,
+						u(IdContentEncAlgo, 5),
+						ebml.NewNode(IdContentEncKeyID, ebml.TypeBinary, []byte{0xAA, 0xBB}),
+						ebml.NewListNode(IdContentEncAESSettings, u(IdAESSettingsCipherMode, 1)),
+					),
+				),
+				ebml.NewListNode(IdContentEncoding,
+					u(IdContentEncodingOrder, 1),
+					ebml.NewListNode(IdContentCompression,
+						u(IdContentCompAlgo, 3),
+						ebml.NewNode(IdContentCompSettings, ebml.TypeBinary, []byte{0x0F}),
+					),
+				),
+			),
+		),
+		ebml.NewListNode(IdTrackEntry,
+			u(IdTrackNumber, 2),
+			u(IdTrackType, uint64(AUDIO_TRACK)),
+			ebml.NewNode(IdCodecID, ebml.TypeString, "A_OPUS"),
+			u(IdCodecDelay, 6500000),
+			u(IdSeekPreRoll, 80000000),
+			ebml.NewListNode(IdAudio, u(IdBitDepth, 16)),
+		),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestTracksRoundTrip(t *testing.T) {
+	tracks, err := ParseTracksElement(testTracks(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 2 {
+		t.Fatalf("got %d tracks, want 2", len(tracks))
+	}
+
+	video := &TrackEntry{
+		TrackNumber: 1, TrackUID: 0x1234, TrackType: uint64(VIDEO_TRACK),
+		FlagEnabled: true, FlagForced: true, FlagLacing: true,
+		DefaultDuration: 33366667, Name: "Vidéo", Language: "fra",
+		CodecID: "V_VP9", CodecPrivate: []byte{1, 2, 3},
+		Video: &VideoSettings{
+			StereoMode: 1, AlphaMode: 1, PixelWidth: 1920, PixelHeight: 1080,
+			DisplayWidth: 16, DisplayHeight: 9, DisplayUnit: 3,
+			Colour: &Colour{
+				MatrixCoefficients: 9, BitsPerChannel: 10, ChromaSubsamplingHorz: 1, Range: 1,
+				TransferCharacteristics: 16, Primaries: 2, MaxCLL: 1000,
+				MasteringMetadata: &MasteringMetadata{
+					PrimaryRChromaticityX: 0.708, WhitePointChromaticityY: 0.329,
+					LuminanceMax: 1000, LuminanceMin: 0.005,
+				},
+			},
+			Projection: &Projection{ProjectionType: 2, ProjectionPrivate: []byte{0, 0, 0, 0}, ProjectionPoseYaw: -90},
+		},
+		ContentEncodings: &ContentEncodings{ContentEncoding: []ContentEncoding{
+			{ContentEncodingScope: 1, ContentEncodingType: 1, ContentEncryption: &ContentEncryption{
+				ContentEncAlgo: 5, ContentEncKeyID: []byte{0xAA, 0xBB},
+				ContentEncAESSettings: &ContentEncAESSettings{AESSettingsCipherMode: 1},
+			}},
+			{ContentEncodingOrder: 1, ContentEncodingScope: 1, ContentCompression: &ContentCompression{
+				ContentCompAlgo: 3, ContentCompSettings: []byte{0x0F},
+			}},
+		}},
+	}
+	audio := &TrackEntry{
+		TrackNumber: 2, TrackType: uint64(AUDIO_TRACK),
+		FlagEnabled: true, FlagDefault: true, FlagLacing: true, Language: "eng",
+		CodecID: "A_OPUS", CodecDelay: 6500000, SeekPreRoll: 80000000,
+		Audio: &AudioSettings{SamplingFrequency: 8000, Channels: 1, BitDepth: 16},
+	}
+	for i, want := range []*TrackEntry{video, audio} {
+		if !reflect.DeepEqual(tracks[i].Entry(), want) {
+			t.Errorf("track %d got %+v, want %+v", i, tracks[i].Entry(), want)
+		}
+	}
+	if tracks[0].ID() != 1 || tracks[0].Type() != VIDEO_TRACK || tracks[1].CodecID() != "A_OPUS" {
+		t.Errorf("got ID %d, type %d and CodecID %s", tracks[0].ID(), tracks[0].Type(), tracks[1].CodecID())
+	}
+
+	// Writing the entries and parsing them again doesn't change them.
+	entries := []*TrackEntry{tracks[0].Entry(), tracks[1].Entry()}
+	body := encodeBody(t, IdTracks, func(w *ebml.Writer) error { return WriteTracksElement(w, entries) })
+	reparsed, err := ParseTracksElement(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range entries {
+		if !reflect.DeepEqual(reparsed[i].Entry(), entries[i]) {
+			t.Errorf("track %d got %+v after a round trip, want %+v", i, reparsed[i].Entry(), entries[i])
+		}
+	}
+
+	// A Colour without any elements gets the unspecified defaults.
+	colour := &Colour{}
+	if err := ebml.Unmarshal([]byte{}, colour); err != nil {
+		t.Fatal(err)
+	}
+	if *colour != (Colour{MatrixCoefficients: 2, TransferCharacteristics: 2, Primaries: 2}) {
+		t.Errorf("got default Colour %+v", colour)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Chapter is the simple form of a chapter used by the JSON and WebVTT
+// files. Times are in seconds and a nil End means the chapter lasts until
+// the next one starts. UID is the ChapterUID, or 0 to have one assigned on
+// import.
+type Chapter struct {
+	UID      uint64   `json:"uid,omitempty"`
+	Start    float64  `json:"start"`
+	End      *float64 `json:"end,omitempty"`
+	Title    string   `json:"title"`
+	Language string   `json:"language,omitempty"`
+}
+
+type jsonChapters struct {
+	Chapters []*Chapter `json:"chapters"`
+}
+
+func parseJSON(data []byte) ([]*Chapter, error) {
+	c := jsonChapters{}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return c.Chapters, nil
+}
+
+func formatJSON(chapters []*Chapter) ([]byte, error) {
+	data, err := json.MarshalIndent(&jsonChapters{Chapters: chapters}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// parseVTTTimestamp parses a WebVTT timestamp of the form hh:mm:ss.ttt or
+// mm:ss.ttt and returns it in seconds.
+func parseVTTTimestamp(s string) (float64, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+
+	seconds := 0.0
+	for i, part := range parts {
+		var value float64
+		var err error
+		if i == len(parts)-1 {
+			value, err = strconv.ParseFloat(part, 64)
+		} else {
+			var n uint64
+			n, err = strconv.ParseUint(part, 10, 64)
+			value = float64(n)
+		}
+		if err != nil || value < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		seconds = seconds*60 + value
+	}
+	return seconds, nil
+}
+
+func formatVTTTimestamp(seconds float64) string {
+	ms := int64(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, (ms/60000)%60, (ms/1000)%60, ms%1000)
+}
+
+// parseVTT parses a WebVTT chapters file. Each cue is a chapter and the cue
+// text is its title.
+func parseVTT(data []byte, language string) ([]*Chapter, error) {
+	text := strings.Replace(string(data), "\r\n", "\n", -1)
+	text = strings.Replace(text, "\r", "\n", -1)
+	text = strings.TrimPrefix(text, "\ufeff")
+
+	blocks := strings.Split(text, "\n\n")
+	if !strings.HasPrefix(blocks[0], "WEBVTT") {
+		return nil, errors.New("missing WEBVTT signature")
+	}
+
+	chapters := []*Chapter{}
+	for _, block := range blocks[1:] {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+		if lines[0] == "" || strings.HasPrefix(lines[0], "NOTE") ||
+			lines[0] == "STYLE" || lines[0] == "REGION" {
+			continue
+		}
+
+		timing := 0
+		for timing < len(lines) && !strings.Contains(lines[timing], "-->") {
+			timing++
+		}
+		if timing > 1 || timing == len(lines) {
+			return nil, fmt.Errorf("missing cue timings in %q", block)
+		}
+
+		times := strings.SplitN(lines[timing], "-->", 2)
+		start, err := parseVTTTimestamp(strings.TrimSpace(times[0]))
+		if err != nil {
+			return nil, err
+		}
+		// Cue settings may follow the end time.
+		endFields := strings.Fields(times[1])
+		if len(endFields) == 0 {
+			return nil, fmt.Errorf("missing end time in %q", lines[timing])
+		}
+		end, err := parseVTTTimestamp(endFields[0])
+		if err != nil {
+			return nil, err
+		}
+
+		chapters = append(chapters, &Chapter{
+			Start:    start,
+			End:      &end,
+			Title:    strings.Join(lines[timing+1:], " "),
+			Language: language,
+		})
+	}
+	return chapters, nil
+}
+
+// formatVTT writes chapters as WebVTT cues. WebVTT cues need an end time so
+// chapters without one end where the next chapter starts and the last one
+// ends at duration.
+func formatVTT(chapters []*Chapter, duration time.Duration) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString("WEBVTT\n")
+	for i, c := range chapters {
+		var end float64
+		if c.End != nil {
+			end = *c.End
+		} else if i+1 < len(chapters) {
+			end = chapters[i+1].Start
+		} else {
+			end = duration.Seconds()
+		}
+		if end < c.Start {
+			end = c.Start
+		}
+
+		fmt.Fprintf(buf, "\n%d\n%s --> %s\n%s\n", i+1, formatVTTTimestamp(c.Start), formatVTTTimestamp(end), c.Title)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,339 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/acolwell/mse-tools/ebml"
+	"github.com/acolwell/mse-tools/webm"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+func isVTT(filename string, format string) bool {
+	if filename != "" {
+		return filepath.Ext(filename) == ".vtt"
+	}
+	return format == "vtt"
+}
+
+// fromChapters flattens the chapters of every edition. Nested chapters
+// follow their parent chapter.
+func fromChapters(chapters *webm.Chapters) []*Chapter {
+	result := []*Chapter{}
+	for _, edition := range chapters.EditionEntries {
+		result = appendAtoms(result, edition.ChapterAtoms)
+	}
+	return result
+}
+
+func appendAtoms(result []*Chapter, atoms []webm.ChapterAtom) []*Chapter {
+	for _, atom := range atoms {
+		c := &Chapter{
+			UID:   atom.ChapterUID,
+			Start: float64(atom.ChapterTimeStart) / 1e9,
+		}
+		if atom.ChapterTimeEnd != nil {
+			end := float64(*atom.ChapterTimeEnd) / 1e9
+			c.End = &end
+		}
+		if len(atom.ChapterDisplay) > 0 {
+			c.Title = atom.ChapterDisplay[0].ChapString
+			c.Language = atom.ChapterDisplay[0].ChapLanguage
+		}
+		result = append(result, c)
+		result = appendAtoms(result, atom.ChapterAtoms)
+	}
+	return result
+}
+
+// toChapters converts chapters to a single edition sorted by start time.
+// Chapters keep their UIDs and ones without a UID are given the lowest
+// unused values.
+func toChapters(chapters []*Chapter) (*webm.Chapters, error) {
+	sorted := append([]*Chapter{}, chapters...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	usedUIDs := map[uint64]bool{}
+	for _, c := range sorted {
+		if c.UID == 0 {
+			continue
+		}
+		if usedUIDs[c.UID] {
+			return nil, fmt.Errorf("duplicate uid %d for chapter %q", c.UID, c.Title)
+		}
+		usedUIDs[c.UID] = true
+	}
+
+	nextUID := uint64(1)
+	edition := webm.EditionEntry{ChapterAtoms: []webm.ChapterAtom{}}
+	for _, c := range sorted {
+		if c.Start < 0 || (c.End != nil && *c.End < c.Start) {
+			return nil, fmt.Errorf("invalid times for chapter %q", c.Title)
+		}
+		language := c.Language
+		if language == "" {
+			language = "eng"
+		}
+
+		uid := c.UID
+		if uid == 0 {
+			for usedUIDs[nextUID] {
+				nextUID++
+			}
+			uid = nextUID
+			usedUIDs[uid] = true
+		}
+
+		atom := webm.ChapterAtom{
+			ChapterUID:         uid,
+			ChapterTimeStart:   uint64(math.Round(c.Start * 1e9)),
+			ChapterFlagEnabled: true,
+			ChapterDisplay:     []webm.ChapterDisplay{{ChapString: c.Title, ChapLanguage: language}},
+		}
+		if c.End != nil {
+			end := uint64(math.Round(*c.End * 1e9))
+			atom.ChapterTimeEnd = &end
+		}
+		edition.ChapterAtoms = append(edition.ChapterAtoms, atom)
+	}
+	return &webm.Chapters{EditionEntries: []webm.EditionEntry{edition}}, nil
+}
+
+func exportChapters(infile string, outfile string, format string) error {
+	file, err := os.Open(infile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	f, err := webm.Open(file)
+	if err != nil {
+		return err
+	}
+	if f.Chapters == nil {
+		return errors.New("file doesn't contain any chapters")
+	}
+
+	chapters := fromChapters(f.Chapters)
+	var output []byte
+	if isVTT(outfile, format) {
+		duration := time.Duration(0)
+		if d := f.Info.Duration(); !math.IsInf(d, 0) {
+			duration = time.Duration(d * float64(f.Info.TimecodeScale()))
+		}
+		output = formatVTT(chapters, duration)
+	} else if output, err = formatJSON(chapters); err != nil {
+		return err
+	}
+
+	if outfile == "" {
+		_, err = os.Stdout.Write(output)
+		return err
+	}
+	return ioutil.WriteFile(outfile, output, 0644)
+}
+
+func encode(write func(w *ebml.Writer) error) ([]byte, error) {
+	bw := ebml.NewBufferWriter(1024)
+	if err := write(ebml.NewWriter(bw)); err != nil {
+		return nil, err
+	}
+	return bw.Bytes(), nil
+}
+
+type level1Element struct {
+	id         int
+	offset     int64
+	headerSize int
+	size       int64 // Includes the header.
+}
+
+// importChapters appends the chapters to the end of the Segment so the
+// position of every other element stays the same. Existing Chapters
+// elements are replaced by Void elements and the SeekHead is rewritten in
+// place, using any Void elements after it as extra space.
+func importChapters(chaptersFile string, language string, infile string, outfile string) error {
+	input, err := ioutil.ReadFile(chaptersFile)
+	if err != nil {
+		return err
+	}
+	var chapters []*Chapter
+	if isVTT(chaptersFile, "") {
+		chapters, err = parseVTT(input, language)
+	} else {
+		chapters, err = parseJSON(input)
+	}
+	if err != nil {
+		return fmt.Errorf("can't parse %s: %s", chaptersFile, err.Error())
+	}
+	if len(chapters) == 0 {
+		return fmt.Errorf("%s doesn't contain any chapters", chaptersFile)
+	}
+
+	webmChapters, err := toChapters(chapters)
+	if err != nil {
+		return err
+	}
+	chaptersElement, err := encode(func(w *ebml.Writer) error { return webm.WriteChapters(w, webmChapters) })
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(infile)
+	if err != nil {
+		return err
+	}
+	r := bytes.NewReader(data)
+
+	id, headerSize, size, err := ebml.ReadElementHeader(r, 0)
+	if err != nil {
+		return err
+	}
+	if id != ebml.IdHeader {
+		return fmt.Errorf("expected EBML header, found %s", webm.IdToName(id))
+	}
+
+	segmentOffset := int64(headerSize) + size
+	id, headerSize, size, err = ebml.ReadElementHeader(r, segmentOffset)
+	if err != nil {
+		return err
+	}
+	if id != webm.IdSegment {
+		return fmt.Errorf("expected Segment, found %s", webm.IdToName(id))
+	}
+	segmentHeaderSize := headerSize
+	segmentDataOffset := segmentOffset + int64(headerSize)
+	segmentEnd := int64(len(data))
+	if size != -1 {
+		segmentEnd = segmentDataOffset + size
+	}
+
+	elements := []level1Element{}
+	for offset := segmentDataOffset; offset < segmentEnd; {
+		id, headerSize, size, err := ebml.ReadElementHeader(r, offset)
+		if err != nil {
+			return err
+		}
+		if size == -1 {
+			return fmt.Errorf("unknown size %s elements aren't supported. Remux the file with mse_webm_remuxer first", webm.IdToName(id))
+		}
+		elements = append(elements, level1Element{id: id, offset: offset, headerSize: headerSize, size: int64(headerSize) + size})
+		offset += int64(headerSize) + size
+	}
+
+	output := append([]byte{}, data[:segmentEnd]...)
+	seekHeadIndex := -1
+	for i, e := range elements {
+		if e.id == webm.IdChapters {
+			void, err := encode(func(w *ebml.Writer) error {
+				_, err := w.WriteVoid(int(e.size))
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			copy(output[e.offset:], void)
+		} else if e.id == webm.IdSeekHead && seekHeadIndex == -1 {
+			seekHeadIndex = i
+		}
+	}
+
+	if seekHeadIndex == -1 {
+		return fmt.Errorf("%s doesn't have a SeekHead to find the Chapters with. Remux the file with mse_webm_remuxer first", infile)
+	}
+
+	e := elements[seekHeadIndex]
+	seekHead, err := webm.ParseSeekHead(data[e.offset+int64(e.headerSize) : e.offset+e.size])
+	if err != nil {
+		return err
+	}
+
+	seeks := seekHead.Seeks
+	seekHead.Seeks = []webm.Seek{}
+	for _, seek := range seeks {
+		if seek.ID() != webm.IdChapters {
+			seekHead.Seeks = append(seekHead.Seeks, seek)
+		}
+	}
+	seekHead.Add(webm.IdChapters, uint64(segmentEnd-segmentDataOffset))
+
+	reservedSize := e.size
+	for i := seekHeadIndex + 1; i < len(elements) && elements[i].id == ebml.IdVoid; i++ {
+		reservedSize += elements[i].size
+	}
+
+	seekHeadElement, err := encode(func(w *ebml.Writer) error { return seekHead.Write(w, int(reservedSize)) })
+	if err != nil {
+		return fmt.Errorf("%s. Remux the file with mse_webm_remuxer to make room for the Chapters", err.Error())
+	}
+	copy(output[e.offset:], seekHeadElement)
+
+	if size != -1 {
+		// The Segment ID is 4 bytes so the rest of the header is the size.
+		segmentHeader, err := encode(func(w *ebml.Writer) error {
+			_, err := w.WriteElementHeader(webm.IdSegment, size+int64(len(chaptersElement)), segmentHeaderSize-4)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		copy(output[segmentOffset:], segmentHeader)
+	}
+
+	output = append(output, chaptersElement...)
+	output = append(output, data[segmentEnd:]...)
+	return ioutil.WriteFile(outfile, output, 0644)
+}
+
+func checkError(str string, err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s - %s\n", str, err.Error())
+		os.Exit(-1)
+	}
+}
+
+func main() {
+	var importFile string
+	var format string
+	var language string
+	flag.StringVar(&importFile, "import", "", "JSON or WebVTT (.vtt) chapters file to add to the WebM file")
+	flag.StringVar(&format, "format", "json", "Format to export to stdout. json or vtt")
+	flag.StringVar(&language, "language", "eng", "Language of imported WebVTT chapters")
+	flag.Parse()
+
+	if importFile != "" {
+		if len(flag.Args()) < 2 {
+			fmt.Fprintf(os.Stderr, "Usage: %s [-language <lang>] -import <chapters file> <infile> <outfile>\n", os.Args[0])
+			os.Exit(-1)
+		}
+		checkError("Import failed", importChapters(importFile, language, flag.Arg(0), flag.Arg(1)))
+		return
+	}
+
+	if len(flag.Args()) < 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-format json|vtt] <infile> [<chapters file>]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s [-language <lang>] -import <chapters file> <infile> <outfile>\n", os.Args[0])
+		os.Exit(-1)
+	}
+	checkError("Export failed", exportChapters(flag.Arg(0), flag.Arg(1), format))
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/acolwell/mse-tools/webm"
+	"testing"
+)
+
+func TestFromChaptersNested(t *testing.T) {
+	end := uint64(0)
+	chapters := &webm.Chapters{EditionEntries: []webm.EditionEntry{{ChapterAtoms: []webm.ChapterAtom{
+		{ChapterUID: 1, ChapterTimeStart: 0, ChapterTimeEnd: &end, ChapterAtoms: []webm.ChapterAtom{
+			{ChapterUID: 2, ChapterTimeStart: 1e9},
+		}},
+		{ChapterUID: 3, ChapterTimeStart: 2e9},
+	}}}}
+
+	result := fromChapters(chapters)
+	if len(result) != 3 {
+		t.Fatalf("got %d chapters, want 3", len(result))
+	}
+	for i, c := range result {
+		if c.UID != uint64(i+1) || c.Start != float64(i) {
+			t.Errorf("chapter %d got uid %d start %v", i, c.UID, c.Start)
+		}
+	}
+	if result[0].End == nil || *result[0].End != 0 {
+		t.Errorf("an end time of 0 was lost")
+	}
+	if result[1].End != nil {
+		t.Errorf("got end time %v for a chapter without one", *result[1].End)
+	}
+}
+
+func TestToChaptersUIDs(t *testing.T) {
+	chapters := []*Chapter{{Start: 3, UID: 1}, {Start: 1}, {Start: 2, UID: 7}, {Start: 0}}
+	result, err := toChapters(chapters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chapters[0].Start != 3 {
+		t.Errorf("toChapters() reordered its input")
+	}
+
+	atoms := result.EditionEntries[0].ChapterAtoms
+	want := []uint64{2, 3, 7, 1}
+	for i, atom := range atoms {
+		if atom.ChapterTimeStart != uint64(i)*1e9 || atom.ChapterUID != want[i] {
+			t.Errorf("atom %d got start %d uid %d, want uid %d", i, atom.ChapterTimeStart, atom.ChapterUID, want[i])
+		}
+	}
+
+	if _, err := toChapters([]*Chapter{{UID: 5}, {Start: 1, UID: 5}}); err == nil {
+		t.Errorf("toChapters() accepted duplicate UIDs")
+	}
+}
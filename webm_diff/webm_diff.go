@@ -0,0 +1,219 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/acolwell/mse-tools/ebml"
+	"github.com/acolwell/mse-tools/webm"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+type differ struct {
+	ignoreIds      map[int]bool
+	headersOnly    bool
+	ignorePayloads bool
+	differences    int
+	out            io.Writer
+}
+
+func formatValue(n *ebml.Node, ignorePayloads bool) string {
+	switch value := n.Value.(type) {
+	case []byte:
+		if n.ID == webm.IdSimpleBlock || n.ID == webm.IdBlock {
+			if blockInfo := webm.ParseSimpleBlock(value); blockInfo != nil {
+				if ignorePayloads {
+					return fmt.Sprintf("track %d timecode %d flags %x", blockInfo.Id, blockInfo.Timecode, blockInfo.Flags)
+				}
+				return fmt.Sprintf("track %d timecode %d flags %x size %d", blockInfo.Id, blockInfo.Timecode, blockInfo.Flags, len(value))
+			}
+		}
+		if len(value) <= 16 {
+			return fmt.Sprintf("0x%X", value)
+		}
+		return fmt.Sprintf("%d bytes", len(value))
+	case float64:
+		return fmt.Sprintf("%f", value)
+	case string:
+		return fmt.Sprintf("%q", value)
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(n.Value)
+}
+
+func (d *differ) equalValues(a *ebml.Node, b *ebml.Node) bool {
+	aBytes, aIsBytes := a.Value.([]byte)
+	bBytes, bIsBytes := b.Value.([]byte)
+	if !aIsBytes || !bIsBytes {
+		return formatValue(a, false) == formatValue(b, false)
+	}
+
+	if d.ignorePayloads && (a.ID == webm.IdSimpleBlock || a.ID == webm.IdBlock) {
+		return formatValue(a, true) == formatValue(b, true)
+	}
+	return bytes.Equal(aBytes, bBytes)
+}
+
+func (d *differ) report(prefix string, path string, n *ebml.Node) {
+	d.differences++
+	if n.Type == ebml.TypeList {
+		fmt.Fprintf(d.out, "%s %s\n", prefix, path)
+		return
+	}
+	fmt.Fprintf(d.out, "%s %s = %s\n", prefix, path, formatValue(n, d.ignorePayloads))
+}
+
+// filter returns the nodes that should be compared.
+func (d *differ) filter(nodes []*ebml.Node) []*ebml.Node {
+	result := []*ebml.Node{}
+	for _, n := range nodes {
+		if d.ignoreIds[n.ID] || (d.headersOnly && n.ID == webm.IdCluster) {
+			continue
+		}
+		result = append(result, n)
+	}
+	return result
+}
+
+// compare pairs up the k-th occurrence of each ID in a with the k-th
+// occurrence of the same ID in b.
+func (d *differ) compare(path string, a []*ebml.Node, b []*ebml.Node) {
+	a = d.filter(a)
+	b = d.filter(b)
+
+	bByID := map[int][]*ebml.Node{}
+	for _, n := range b {
+		bByID[n.ID] = append(bByID[n.ID], n)
+	}
+
+	counts := map[int]int{}
+	for _, n := range a {
+		index := counts[n.ID]
+		counts[n.ID]++
+
+		childPath := elementPath(path, n.ID, index)
+		if index >= len(bByID[n.ID]) {
+			d.report("-", childPath, n)
+			continue
+		}
+
+		other := bByID[n.ID][index]
+		if n.Type == ebml.TypeList {
+			d.compare(childPath, n.Children, other.Children)
+		} else if !d.equalValues(n, other) {
+			d.differences++
+			fmt.Fprintf(d.out, "~ %s = %s -> %s\n", childPath, formatValue(n, d.ignorePayloads), formatValue(other, d.ignorePayloads))
+		}
+	}
+
+	bCounts := map[int]int{}
+	for _, n := range b {
+		index := bCounts[n.ID]
+		bCounts[n.ID]++
+		if index >= counts[n.ID] {
+			d.report("+", elementPath(path, n.ID, index), n)
+		}
+	}
+}
+
+func elementPath(parent string, id int, index int) string {
+	return fmt.Sprintf("%s/%s[%d]", parent, webm.IdToName(id), index+1)
+}
+
+// readNodes streams filename into a Node tree. Clusters are left out when
+// headersOnly is set and blocks only keep their headers when ignorePayloads
+// is set, so frame data is never held in memory for those options.
+func readNodes(filename string, headersOnly bool, ignorePayloads bool) ([]*ebml.Node, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	builder := ebml.NewNodeBuilder()
+	if headersOnly {
+		builder.Skip = func(e ebml.Element, depth int) bool {
+			return e.ID == webm.IdCluster
+		}
+	}
+
+	decoder := ebml.NewDecoder(file, webm.Schema())
+	for {
+		e, err := decoder.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if value, ok := e.Value.([]byte); ok && ignorePayloads && (e.ID == webm.IdSimpleBlock || e.ID == webm.IdBlock) {
+			if blockInfo := webm.ParseSimpleBlock(value); blockInfo != nil {
+				e.Value = append([]byte{}, value[:blockInfo.HeaderSize]...)
+			}
+		}
+		builder.Add(e)
+	}
+	return builder.Nodes(), nil
+}
+
+func checkError(str string, err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s - %s\n", str, err.Error())
+		os.Exit(2)
+	}
+}
+
+func main() {
+	d := &differ{ignoreIds: map[int]bool{}, out: os.Stdout}
+	var ignore string
+	flag.BoolVar(&d.headersOnly, "headers", false, "Only compare elements outside of Clusters")
+	flag.BoolVar(&d.ignorePayloads, "ignore-payloads", false, "Compare block headers but not the frame data in blocks")
+	flag.StringVar(&ignore, "ignore", "Void", "Comma separated names of elements to skip")
+	flag.Parse()
+
+	if len(flag.Args()) < 2 {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-headers] [-ignore-payloads] [-ignore <names>] <file1> <file2>\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	for _, name := range strings.Split(ignore, ",") {
+		if name == "" {
+			continue
+		}
+		id, ok := webm.Schema().ID(name)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown element %s\n", name)
+			os.Exit(2)
+		}
+		d.ignoreIds[id] = true
+	}
+
+	a, err := readNodes(flag.Arg(0), d.headersOnly, d.ignorePayloads)
+	checkError(fmt.Sprintf("can't parse %s", flag.Arg(0)), err)
+	b, err := readNodes(flag.Arg(1), d.headersOnly, d.ignorePayloads)
+	checkError(fmt.Sprintf("can't parse %s", flag.Arg(1)), err)
+
+	d.compare("", a, b)
+	if d.differences > 0 {
+		os.Exit(1)
+	}
+}
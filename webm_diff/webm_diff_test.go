@@ -0,0 +1,233 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"github.com/acolwell/mse-tools/ebml"
+	"github.com/acolwell/mse-tools/webm"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testOptions describes the parts of a test file that differ between
+// the inputs of a test.
+type testOptions struct {
+	timecodeScale uint64
+	title         string
+	void          bool
+	tags          bool
+	frame         []byte
+	clusters      int
+}
+
+var defaultOptions = testOptions{timecodeScale: 1000000, frame: []byte{1, 2}, clusters: 2}
+
+// writeTestFile writes a Segment with an Info, an optional Tags element and
+// Clusters that each hold a SimpleBlock with o.frame.
+func writeTestFile(t *testing.T, filename string, o testOptions) string {
+	info := ebml.NewListNode(webm.IdInfo, ebml.NewNode(webm.IdTimecodeScale, ebml.TypeUint, o.timecodeScale))
+	if o.title != "" {
+		info.AppendChild(ebml.NewNode(webm.IdTitle, ebml.TypeUTF8, o.title))
+	}
+	segment := ebml.NewListNode(webm.IdSegment, info)
+	if o.void {
+		segment.AppendChild(ebml.NewNode(ebml.IdVoid, ebml.TypeBinary, []byte{0, 0}))
+	}
+	if o.tags {
+		segment.AppendChild(ebml.NewListNode(webm.IdTags, ebml.NewListNode(webm.IdTag)))
+	}
+	for i := 0; i < o.clusters; i++ {
+		block, err := webm.EncodeBlock(1, 0, 0x80, [][]byte{o.frame})
+		if err != nil {
+			t.Fatal(err)
+		}
+		segment.AppendChild(ebml.NewListNode(webm.IdCluster,
+			ebml.NewNode(webm.IdTimecode, ebml.TypeUint, uint64(i*1000)),
+			ebml.NewNode(webm.IdSimpleBlock, ebml.TypeBinary, block)))
+	}
+
+	data, err := ebml.EncodeNodes([]*ebml.Node{segment})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), filename)
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDiff(t *testing.T) {
+	changed := func(change func(o *testOptions)) testOptions {
+		o := defaultOptions
+		change(&o)
+		return o
+	}
+
+	tests := []struct {
+		name           string
+		b              testOptions
+		headersOnly    bool
+		ignorePayloads bool
+		ignore         []int
+		want           []string
+	}{
+		{"identical", defaultOptions, false, false, nil, nil},
+		{
+			"changed value",
+			changed(func(o *testOptions) { o.timecodeScale = 500000 }),
+			false, false, nil,
+			[]string{"~ /Segment[1]/Info[1]/TimecodeScale[1] = 1000000 -> 500000"},
+		},
+		{
+			"added leaf",
+			changed(func(o *testOptions) { o.title = "new" }),
+			false, false, nil,
+			[]string{`+ /Segment[1]/Info[1]/Title[1] = "new"`},
+		},
+		{
+			"added list",
+			changed(func(o *testOptions) { o.tags = true }),
+			false, false, nil,
+			[]string{"+ /Segment[1]/Tags[1]"},
+		},
+		{
+			"removed Cluster",
+			changed(func(o *testOptions) { o.clusters = 1 }),
+			false, false, nil,
+			[]string{"- /Segment[1]/Cluster[2]"},
+		},
+		{
+			"changed payloads",
+			changed(func(o *testOptions) { o.frame = []byte{3, 4, 5} }),
+			false, false, nil,
+			[]string{
+				"~ /Segment[1]/Cluster[1]/SimpleBlock[1] = track 1 timecode 0 flags 80 size 6 -> track 1 timecode 0 flags 80 size 7",
+				"~ /Segment[1]/Cluster[2]/SimpleBlock[1] = track 1 timecode 0 flags 80 size 6 -> track 1 timecode 0 flags 80 size 7",
+			},
+		},
+		{
+			"ignored payloads",
+			changed(func(o *testOptions) { o.frame = []byte{3, 4, 5} }),
+			false, true, nil, nil,
+		},
+		{
+			"headers only",
+			changed(func(o *testOptions) { o.frame = []byte{3}; o.clusters = 3 }),
+			true, false, nil, nil,
+		},
+		{
+			"headers only with a changed header",
+			changed(func(o *testOptions) { o.title = "new"; o.clusters = 3 }),
+			true, false, nil,
+			[]string{`+ /Segment[1]/Info[1]/Title[1] = "new"`},
+		},
+		{
+			"ignored Void",
+			changed(func(o *testOptions) { o.void = true }),
+			false, false, []int{ebml.IdVoid}, nil,
+		},
+		{
+			"Void",
+			changed(func(o *testOptions) { o.void = true }),
+			false, false, nil,
+			[]string{"+ /Segment[1]/Void[1] = 0x0000"},
+		},
+	}
+
+	for _, test := range tests {
+		a := writeTestFile(t, "a.webm", defaultOptions)
+		b := writeTestFile(t, "b.webm", test.b)
+
+		out := &bytes.Buffer{}
+		d := &differ{ignoreIds: map[int]bool{}, headersOnly: test.headersOnly, ignorePayloads: test.ignorePayloads, out: out}
+		for _, id := range test.ignore {
+			d.ignoreIds[id] = true
+		}
+
+		aNodes, err := readNodes(a, d.headersOnly, d.ignorePayloads)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		bNodes, err := readNodes(b, d.headersOnly, d.ignorePayloads)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		d.compare("", aNodes, bNodes)
+
+		got := []string{}
+		if out.Len() > 0 {
+			got = strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		}
+		if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+		if d.differences != len(test.want) {
+			t.Errorf("%s: got %d differences, want %d", test.name, d.differences, len(test.want))
+		}
+	}
+}
+
+func TestReadHeaderNodes(t *testing.T) {
+	o := defaultOptions
+	o.tags = true
+	o.clusters = 3
+	path := writeTestFile(t, "a.webm", o)
+	nodes, err := readNodes(path, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || len(nodes[0].Children) != 2 {
+		t.Fatalf("got %d nodes", len(nodes))
+	}
+	if nodes[0].Lookup(webm.IdInfo, webm.IdTimecodeScale) == nil || nodes[0].Lookup(webm.IdCluster) != nil {
+		t.Errorf("got %+v, want the Info and Tags without Clusters", nodes[0].Children)
+	}
+
+	// The Segment size still includes the skipped Clusters.
+	all, err := readNodes(path, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodes[0].Size != all[0].Size {
+		t.Errorf("got Segment size %d, want %d", nodes[0].Size, all[0].Size)
+	}
+}
+
+func TestReadNodesIgnorePayloads(t *testing.T) {
+	o := defaultOptions
+	o.frame = make([]byte, 100)
+	path := writeTestFile(t, "a.webm", o)
+	nodes, err := readNodes(path, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blocks := 0
+	for _, cluster := range nodes[0].ChildrenWithID(webm.IdCluster) {
+		for _, block := range cluster.ChildrenWithID(webm.IdSimpleBlock) {
+			blocks++
+			if value := block.Value.([]byte); len(value) != 4 || block.Size != 104 {
+				t.Errorf("got a %d byte SimpleBlock with %d bytes kept, want 104 and the 4 byte header", block.Size, len(value))
+			}
+		}
+	}
+	if blocks != o.clusters {
+		t.Errorf("got %d SimpleBlocks, want %d", blocks, o.clusters)
+	}
+}
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"github.com/acolwell/mse-tools/ebml"
 	"github.com/acolwell/mse-tools/webm"
@@ -23,74 +24,24 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 )
 
-type TestClient struct {
-	depth           int
-	clusterTimecode uint64
+func indent(depth int) string {
+	return strings.Repeat("  ", depth)
 }
 
-func (c *TestClient) OnListStart(offset int64, id int) bool {
-	fmt.Printf("%s<%s type=\"list\" offset=\"%d\">\n", c.indent(), webm.IdToName(id), offset)
-	c.depth++
-	return true
-}
-
-func (c *TestClient) OnListEnd(offset int64, id int) bool {
-	c.depth--
-	fmt.Printf("%s</%s>\n", c.indent(), webm.IdToName(id))
-	return true
-}
-
-func (c *TestClient) OnBinary(id int, value []byte) bool {
-	if id != webm.IdSimpleBlock && id != webm.IdBlock {
-		fmt.Printf("%s<%s type=\"binary\" size=\"%d\"/>\n", c.indent(), webm.IdToName(id), len(value))
-	} else {
-		blockInfo := webm.ParseSimpleBlock(value)
-		presentationTimecode := int64(c.clusterTimecode) + int64(blockInfo.Timecode)
-		if blockInfo != nil {
-			fmt.Printf("%s<%s type=\"binary\" size=\"%d\" trackNum=\"%d\" timecode=\"%d\" presentationTimecode=\"%d\" flags=\"%x\"/>\n",
-				c.indent(), webm.IdToName(id), len(value), blockInfo.Id, blockInfo.Timecode, presentationTimecode, blockInfo.Flags)
-		} else {
-			fmt.Printf("%s<%s type=\"binary\" size=\"%d\" invalid=\"true\"/>\n", c.indent(), webm.IdToName(id), len(value))
-		}
+func printBlock(depth int, e ebml.Element, clusterTimecode uint64) {
+	value := e.Value.([]byte)
+	blockInfo := webm.ParseSimpleBlock(value)
+	if blockInfo == nil {
+		fmt.Printf("%s<%s type=\"binary\" size=\"%d\" invalid=\"true\"/>\n", indent(depth), webm.IdToName(e.ID), len(value))
+		return
 	}
-	return true
-}
 
-func (c *TestClient) OnInt(id int, value int64) bool {
-	fmt.Printf("%s<%s type=\"int\" value=\"%d\"/>\n", c.indent(), webm.IdToName(id), value)
-	return true
-}
-
-func (c *TestClient) OnUint(id int, value uint64) bool {
-	if id != webm.IdSeekID {
-		fmt.Printf("%s<%s type=\"uint\" value=\"%d\"/>\n", c.indent(), webm.IdToName(id), value)
-	} else {
-		fmt.Printf("%s<%s type=\"uint\" id_name=\"%s\" value=\"%d\"/>\n", c.indent(), webm.IdToName(id), webm.IdToName(int(value)), value)
-	}
-	if id == webm.IdTimecode {
-		c.clusterTimecode = value
-	}
-	return true
-}
-
-func (c *TestClient) OnFloat(id int, value float64) bool {
-	fmt.Printf("%s<%s type=\"float\" value=\"%f\"/>\n", c.indent(), webm.IdToName(id), value)
-	return true
-}
-
-func (c *TestClient) OnString(id int, value string) bool {
-	fmt.Printf("%s<%s type=\"string\" value=\"%s\"/>\n", c.indent(), webm.IdToName(id), value)
-	return true
-}
-
-func (c *TestClient) indent() string {
-	return strings.Repeat("  ", c.depth)
-}
-
-func NewTestClient() *TestClient {
-	return &TestClient{depth: 0}
+	presentationTimecode := int64(clusterTimecode) + int64(blockInfo.Timecode)
+	fmt.Printf("%s<%s type=\"binary\" size=\"%d\" trackNum=\"%d\" timecode=\"%d\" presentationTimecode=\"%d\" flags=\"%x\"/>\n",
+		indent(depth), webm.IdToName(e.ID), len(value), blockInfo.Id, blockInfo.Timecode, presentationTimecode, blockInfo.Flags)
 }
 
 func checkError(str string, err error) {
@@ -101,16 +52,28 @@ func checkError(str string, err error) {
 }
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <infile>\n", os.Args[0])
+	var verifyCRC32 bool
+	var resync bool
+	var maxBufferedBytes int64
+	var maxDepth int
+	flag.BoolVar(&verifyCRC32, "verify-crc", false, "Verify CRC-32 elements and fail on mismatches")
+	flag.BoolVar(&resync, "resync", false, "Skip over corrupt data instead of failing")
+	flag.Int64Var(&maxBufferedBytes, "max-buffer", 0, "Maximum size of a non-list element in bytes (0 disables the limit)")
+	flag.IntVar(&maxDepth, "max-depth", 0, "Maximum list nesting depth (0 disables the limit)")
+	flag.Parse()
+
+	if len(flag.Args()) < 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-verify-crc] [-resync] [-max-buffer <bytes>] [-max-depth <depth>] <infile>\n", os.Args[0])
 		return
 	}
 
+	inputArg := flag.Arg(0)
+
 	var in io.Reader = nil
-	if os.Args[1] == "-" {
+	if inputArg == "-" {
 		in = os.Stdin
-	} else if strings.HasPrefix(os.Args[1], "ws://") {
-		url, err := url.Parse(os.Args[1])
+	} else if strings.HasPrefix(inputArg, "ws://") {
+		url, err := url.Parse(inputArg)
 		checkError("Output url", err)
 
 		origin := "http://localhost/"
@@ -118,24 +81,67 @@ func main() {
 		checkError("WebSocket Dial", err)
 		in = io.Reader(ws)
 	} else {
-		file, err := os.Open(os.Args[1])
-		checkError(fmt.Sprintf("can't open file %s", os.Args[1]), err)
+		file, err := os.Open(inputArg)
+		checkError(fmt.Sprintf("can't open file %s", inputArg), err)
 		in = io.Reader(file)
 	}
 
-	buf := [1024]byte{}
-
-	c := NewTestClient()
-
-	parser := ebml.NewParser(ebml.GetListIDs(webm.IdTypes()), webm.UnknownSizeInfo(), ebml.NewElementParser(c, webm.IdTypes()))
+	decoder := ebml.NewDecoder(in, webm.Schema())
+	decoder.SetVerifyCRC32(verifyCRC32)
+	decoder.SetOptions(ebml.ParserOptions{MaxDepth: maxDepth, MaxBufferedBytes: maxBufferedBytes})
+	if resync {
+		decoder.SetResyncIDs(webm.ResyncIDs())
+	}
 
+	depth := 0
+	clusterTimecode := uint64(0)
 	for {
-		bytesRead, err := in.Read(buf[:])
-		if err != nil {
-			parser.EndOfData()
+		e, err := decoder.Next()
+		if err == io.EOF {
 			break
 		}
+		checkError("Parse failed", err)
+
+		switch e.Kind {
+		case ebml.ElementListStart:
+			fmt.Printf("%s<%s type=\"list\" offset=\"%d\">\n", indent(depth), webm.IdToName(e.ID), e.Offset)
+			depth++
+			continue
+		case ebml.ElementListEnd:
+			depth--
+			fmt.Printf("%s</%s>\n", indent(depth), webm.IdToName(e.ID))
+			continue
+		case ebml.ElementSkipped:
+			fmt.Printf("%s<!-- skipped %d bytes at offset %d -->\n", indent(depth), e.Size, e.Offset)
+			continue
+		}
 
-		parser.Append(buf[0:bytesRead])
+		switch value := e.Value.(type) {
+		case []byte:
+			if e.ID == webm.IdSimpleBlock || e.ID == webm.IdBlock {
+				printBlock(depth, e, clusterTimecode)
+			} else if e.ID == webm.IdSeekID && len(value) <= 4 {
+				id := 0
+				for _, b := range value {
+					id = (id << 8) | int(b)
+				}
+				fmt.Printf("%s<%s type=\"uint\" id_name=\"%s\" value=\"%d\"/>\n", indent(depth), webm.IdToName(e.ID), webm.IdToName(id), id)
+			} else {
+				fmt.Printf("%s<%s type=\"binary\" size=\"%d\"/>\n", indent(depth), webm.IdToName(e.ID), len(value))
+			}
+		case int64:
+			fmt.Printf("%s<%s type=\"int\" value=\"%d\"/>\n", indent(depth), webm.IdToName(e.ID), value)
+		case uint64:
+			fmt.Printf("%s<%s type=\"uint\" value=\"%d\"/>\n", indent(depth), webm.IdToName(e.ID), value)
+			if e.ID == webm.IdTimecode {
+				clusterTimecode = value
+			}
+		case float64:
+			fmt.Printf("%s<%s type=\"float\" value=\"%f\"/>\n", indent(depth), webm.IdToName(e.ID), value)
+		case string:
+			fmt.Printf("%s<%s type=\"string\" value=\"%s\"/>\n", indent(depth), webm.IdToName(e.ID), value)
+		case time.Time:
+			fmt.Printf("%s<%s type=\"date\" value=\"%s\"/>\n", indent(depth), webm.IdToName(e.ID), value.Format(time.RFC3339Nano))
+		}
 	}
 }
@@ -25,11 +25,11 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 )
 
 type TestClient struct {
 	clusterTimecode uint64
-	currentTrackId  uint64
 
 	videoTrackId uint64
 	codec4cc     uint32
@@ -65,28 +65,62 @@ func (c *TestClient) WriteHeader() {
 	c.out.Write(buf.Bytes())
 }
 
-func (c *TestClient) OnListStart(offset int64, id int) bool {
+func (c *TestClient) OnListStart(offset int64, id int) error {
 	if id == webm.IdSegment {
 		c.WriteHeader()
 	}
-	return true
+	return nil
 }
 
-func (c *TestClient) OnListEnd(offset int64, id int) bool {
-	if id == webm.IdTrackEntry && c.videoTrackId == 0 {
-		c.videoTrackId = c.currentTrackId
-	} else if id == webm.IdSegment {
+func (c *TestClient) OnListEnd(offset int64, id int) error {
+	if id == webm.IdSegment {
 		c.WriteHeader()
 	}
-	return true
+	return nil
 }
 
-func (c *TestClient) OnBinary(id int, value []byte) bool {
+func (c *TestClient) ParseTracks(buf []byte) error {
+	tracks, err := webm.ParseTracksElement(buf)
+	if err != nil {
+		return err
+	}
+
+	for _, track := range tracks {
+		if track.Type() != webm.VIDEO_TRACK {
+			continue
+		}
+
+		c.videoTrackId = track.ID()
+		if track.CodecID() == "V_VP9" {
+			c.codec4cc = 0x56503930
+		} else if track.CodecID() == "V_VP8" {
+			c.codec4cc = 0x56503830
+		}
+		if video := track.Entry().Video; video != nil {
+			c.width = uint16(video.PixelWidth)
+			c.height = uint16(video.PixelHeight)
+			c.frameRate = video.FrameRate
+		}
+		break
+	}
+	return nil
+}
+
+func (c *TestClient) OnBinary(id int, value []byte) error {
+	if id == webm.IdTracks {
+		return c.ParseTracks(value)
+	}
+
 	if id == webm.IdSimpleBlock {
-		blockInfo := webm.ParseSimpleBlock(value)
+		blockInfo, frames, err := webm.ParseBlockFrames(value)
+		if err != nil {
+			return fmt.Errorf("invalid simple block: %w", err)
+		}
+
+		// Laced frames don't have their own timestamps so they all get the
+		// block's timestamp.
 		presentationTimecode := int64(c.clusterTimecode) + int64(blockInfo.Timecode)
-		if blockInfo != nil {
-			frameData := value[blockInfo.HeaderSize:]
+		for _, frameData := range frames {
 			fmt.Printf("frame size %d timestamp %d\n", len(frameData), presentationTimecode)
 			buf := new(bytes.Buffer)
 			binary.Write(buf, binary.LittleEndian, uint32(len(frameData)))
@@ -94,56 +128,34 @@ func (c *TestClient) OnBinary(id int, value []byte) bool {
 			binary.Write(buf, binary.BigEndian, frameData)
 			c.out.Write(buf.Bytes())
 			c.frameCount += 1
-		} else {
-			fmt.Printf("Invalid simple block")
-			return false
 		}
 	}
-	return true
+	return nil
 }
 
-func (c *TestClient) OnInt(id int, value int64) bool {
-	return true
+func (c *TestClient) OnInt(id int, value int64) error {
+	return nil
 }
 
-func (c *TestClient) OnUint(id int, value uint64) bool {
+func (c *TestClient) OnUint(id int, value uint64) error {
 	if id == webm.IdTimecode {
 		c.clusterTimecode = value
+	} else if id == webm.IdTimecodeScale {
+		c.timeScale = uint32(value)
 	}
+	return nil
+}
 
-	if c.videoTrackId == 0 {
-		if id == webm.IdTrackNumber {
-			c.currentTrackId = value
-		} else if id == webm.IdPixelWidth {
-			c.width = uint16(value)
-		} else if id == webm.IdPixelHeight {
-			c.height = uint16(value)
-		} else if id == webm.IdTimecodeScale {
-			c.timeScale = uint32(value)
-		}
-	}
-
-	return true
+func (c *TestClient) OnFloat(id int, value float64) error {
+	return nil
 }
 
-func (c *TestClient) OnFloat(id int, value float64) bool {
-	if c.videoTrackId == 0 && id == webm.IdFrameRate {
-		c.frameRate = value
-	}
-	return true
+func (c *TestClient) OnString(id int, value string) error {
+	return nil
 }
 
-func (c *TestClient) OnString(id int, value string) bool {
-	if c.videoTrackId == 0 {
-		if id == webm.IdCodecID {
-			if value == "V_VP9" {
-				c.codec4cc = 0x56503930
-			} else if value == "V_VP8" {
-				c.codec4cc = 0x56503830
-			}
-		}
-	}
-	return true
+func (c *TestClient) OnDate(id int, value time.Time) error {
+	return nil
 }
 
 func NewTestClient(out io.WriteSeeker) *TestClient {
@@ -197,15 +209,28 @@ func main() {
 
 	c := NewTestClient(out)
 
-	parser := ebml.NewParser(ebml.GetListIDs(webm.IdTypes()), webm.UnknownSizeInfo(), ebml.NewElementParser(c, webm.IdTypes()))
+	// Tracks is parsed as a whole by ParseTracks().
+	typeInfo := map[int]int{
+		webm.IdSegment:       ebml.TypeList,
+		webm.IdInfo:          ebml.TypeList,
+		webm.IdTimecodeScale: ebml.TypeUint,
+		webm.IdTracks:        ebml.TypeBinary,
+		webm.IdCluster:       ebml.TypeList,
+		webm.IdTimecode:      ebml.TypeUint,
+		webm.IdSimpleBlock:   ebml.TypeBinary,
+	}
+
+	schema := webm.Schema().WithTypes(typeInfo)
+	parser := ebml.NewParser(schema, ebml.NewElementParser(c, schema))
 
 	for {
 		bytesRead, err := in.Read(buf[:])
-		if err != nil {
-			parser.EndOfData()
+		if err == io.EOF {
+			checkError("Parse failed", parser.EndOfData())
 			break
 		}
+		checkError("Read failed", err)
 
-		parser.Append(buf[0:bytesRead])
+		checkError("Parse failed", parser.Append(buf[0:bytesRead]))
 	}
 }